@@ -165,6 +165,9 @@ module "observability" {
   loki_storage_size       = "200Gi"
   storage_class           = "gp3"
 
+  # Backups a cada 6h: alerta após um schedule perdido
+  backup_max_age_hours = 7
+
   depends_on = [module.eks_cluster]
 }
 
@@ -224,4 +224,9 @@ variable "domain_name" {
 variable "cert_manager_email" {
   description = "Email address for Let's Encrypt certificate notifications"
   type        = string
+
+  validation {
+    condition     = can(regex("^[^@\\s]+@[^@\\s]+\\.[a-zA-Z]{2,}$", var.cert_manager_email)) && !can(regex("@(.+\\.)?example\\.(com|net|org)$", lower(var.cert_manager_email)))
+    error_message = "cert_manager_email must be a valid address outside the example.com/.net/.org placeholder domains"
+  }
 }
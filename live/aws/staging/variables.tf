@@ -140,9 +140,9 @@ variable "node_groups" {
   default = {
     system = {
       instance_types = ["t3.medium"]
-      min_size       = 2
-      max_size       = 3
-      desired_size   = 2
+      min_size       = 3
+      max_size       = 4
+      desired_size   = 3
       disk_size      = 50
       labels = {
         role = "system"
@@ -74,7 +74,7 @@ resource "aws_s3_bucket_lifecycle_configuration" "audit_logs" {
   }
 }
 
-# Bucket policy para prevenir deleção e permitir CloudTrail
+# Bucket policy para prevenir deleção, exigir TLS e permitir CloudTrail
 resource "aws_s3_bucket_policy" "audit_logs" {
   bucket = aws_s3_bucket.audit_logs.id
 
@@ -136,6 +136,21 @@ resource "aws_s3_bucket_policy" "audit_logs" {
           }
         }
       },
+      {
+        Sid       = "DenyInsecureTransport"
+        Effect    = "Deny"
+        Principal = "*"
+        Action    = "s3:*"
+        Resource = [
+          aws_s3_bucket.audit_logs.arn,
+          "${aws_s3_bucket.audit_logs.arn}/*"
+        ]
+        Condition = {
+          Bool = {
+            "aws:SecureTransport" = "false"
+          }
+        }
+      },
       {
         Sid    = "DenyDeleteBucket"
         Effect = "Deny"
@@ -152,6 +152,11 @@ resource "helm_release" "external_secrets" {
         nodeSelector = var.node_selector
         tolerations  = var.tolerations
       }
+
+      # Métricas de sincronização para os alertas do módulo observability
+      serviceMonitor = {
+        enabled = true
+      }
     })
   ]
 
@@ -25,6 +25,11 @@ resource "helm_release" "cert_manager" {
         tolerations  = var.tolerations
       }
 
+      startupapicheck = {
+        nodeSelector = var.node_selector
+        tolerations  = var.tolerations
+      }
+
       prometheus = {
         enabled = true
         servicemonitor = {
@@ -57,11 +62,22 @@ resource "kubernetes_manifest" "letsencrypt_issuer" {
         privateKeySecretRef = {
           name = "letsencrypt-${var.letsencrypt_environment}"
         }
+        # O Ingress do desafio HTTP-01 usa a mesma IngressClass das aplicações; no
+        # ALB ele precisa ser internet-facing para o Let's Encrypt alcançá-lo
         solvers = [{
           http01 = {
-            ingress = {
-              class = var.ingress_type == "alb" ? "alb" : "nginx"
-            }
+            ingress = merge(
+              { ingressClassName = local.ingress_class },
+              var.ingress_type == "alb" ? {
+                ingressTemplate = {
+                  metadata = {
+                    annotations = {
+                      "alb.ingress.kubernetes.io/scheme" = "internet-facing"
+                    }
+                  }
+                }
+              } : {}
+            )
           }
         }]
       }
@@ -5,18 +5,20 @@ data "aws_iam_policy_document" "external_dns" {
   statement {
     effect = "Allow"
     actions = [
-      "route53:ChangeResourceRecordSets"
+      "route53:ChangeResourceRecordSets",
+      "route53:ListResourceRecordSets"
     ]
     resources = [
       "arn:aws:route53:::hostedzone/${var.route53_zone_id}"
     ]
   }
 
+  # ListHostedZones não aceita restrição por recurso; o escopo das alterações
+  # vem do statement acima e do --zone-id-filter
   statement {
     effect = "Allow"
     actions = [
-      "route53:ListHostedZones",
-      "route53:ListResourceRecordSets"
+      "route53:ListHostedZones"
     ]
     resources = ["*"]
   }
@@ -86,10 +88,18 @@ resource "helm_release" "external_dns" {
     yamlencode({
       provider = "aws"
 
-      aws = {
-        region = var.aws_region
-        zoneType = "public"
-      }
+      # O chart kubernetes-sigs não possui a chave aws; região e tipo de zona
+      # são passados por variável de ambiente e argumento do container
+      env = [{
+        name  = "AWS_DEFAULT_REGION"
+        value = var.aws_region
+      }]
+
+      # O chart não possui a chave zoneIdFilters; o filtro é passado como argumento
+      extraArgs = [
+        "--aws-zone-type=public",
+        "--zone-id-filter=${var.route53_zone_id}"
+      ]
 
       domainFilters = [var.domain_name]
 
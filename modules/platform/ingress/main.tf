@@ -21,6 +21,11 @@ terraform {
   }
 }
 
+locals {
+  # IngressClass criada pelo controller (padrão dos charts aws-load-balancer-controller e ingress-nginx)
+  ingress_class = var.ingress_type == "alb" ? "alb" : "nginx"
+}
+
 # Namespace para ingress controller
 resource "kubernetes_namespace" "ingress" {
   metadata {
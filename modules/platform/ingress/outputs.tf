@@ -20,7 +20,7 @@ output "ingress_type" {
 
 output "ingress_class" {
   description = "IngressClass a usar em recursos Ingress"
-  value       = var.ingress_type == "alb" ? "alb" : "nginx"
+  value       = local.ingress_class
 }
 
 output "cluster_issuer_name" {
@@ -0,0 +1,293 @@
+# Catálogo de alertas dos componentes da plataforma
+#
+# As PrometheusRules usam o label release do kube-prometheus-stack para serem
+# carregadas pelo Prometheus. O label app.kubernetes.io/part-of indica o chart
+# que exporta as métricas (test/testdata/charts/<chart>/<versão>/metrics.yaml).
+
+# Velero: backups com falha ou atrasados
+resource "kubernetes_manifest" "alerts_velero" {
+  count = var.enable_alert_rules ? 1 : 0
+
+  manifest = {
+    apiVersion = "monitoring.coreos.com/v1"
+    kind       = "PrometheusRule"
+    metadata = {
+      name      = "platform-velero"
+      namespace = kubernetes_namespace.observability.metadata[0].name
+      labels = {
+        release                     = "kube-prometheus-stack"
+        "app.kubernetes.io/part-of" = "velero"
+      }
+    }
+    spec = {
+      groups = [{
+        name = "velero"
+        rules = [
+          {
+            alert = "VeleroBackupFailed"
+            expr  = "increase(velero_backup_failure_total{schedule!=\"\"}[1h]) > 0"
+            labels = {
+              severity = "critical"
+            }
+            annotations = {
+              summary     = "Backup do Velero falhou"
+              description = "O schedule {{ $labels.schedule }} teve backup com falha na última hora."
+            }
+          },
+          {
+            alert = "VeleroBackupPartiallyFailed"
+            expr  = "increase(velero_backup_partial_failure_total{schedule!=\"\"}[1h]) > 0"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Backup do Velero parcialmente concluído"
+              description = "O schedule {{ $labels.schedule }} teve backup com falha parcial na última hora."
+            }
+          },
+          {
+            alert = "VeleroBackupTooOld"
+            expr  = "time() - velero_backup_last_successful_timestamp{schedule!=\"\"} > ${var.backup_max_age_hours * 3600}"
+            for   = "15m"
+            labels = {
+              severity = "critical"
+            }
+            annotations = {
+              summary     = "Sem backup recente do Velero"
+              description = "O schedule {{ $labels.schedule }} está há mais de ${var.backup_max_age_hours}h sem backup bem-sucedido."
+            }
+          },
+        ]
+      }]
+    }
+  }
+
+  depends_on = [helm_release.kube_prometheus_stack]
+}
+
+# cert-manager: certificados expirando ou não prontos
+resource "kubernetes_manifest" "alerts_cert_manager" {
+  count = var.enable_alert_rules ? 1 : 0
+
+  manifest = {
+    apiVersion = "monitoring.coreos.com/v1"
+    kind       = "PrometheusRule"
+    metadata = {
+      name      = "platform-cert-manager"
+      namespace = kubernetes_namespace.observability.metadata[0].name
+      labels = {
+        release                     = "kube-prometheus-stack"
+        "app.kubernetes.io/part-of" = "cert-manager"
+      }
+    }
+    spec = {
+      groups = [{
+        name = "cert-manager"
+        rules = [
+          {
+            alert = "CertManagerCertificateExpiringSoon"
+            expr  = "certmanager_certificate_expiration_timestamp_seconds - time() < ${var.certificate_expiry_warning_days * 86400}"
+            for   = "1h"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Certificado expirando"
+              description = "O certificado {{ $labels.namespace }}/{{ $labels.name }} expira em menos de ${var.certificate_expiry_warning_days} dias."
+            }
+          },
+          {
+            alert = "CertManagerCertificateNotReady"
+            expr  = "certmanager_certificate_ready_status{condition=~\"False|Unknown\"} == 1"
+            for   = "15m"
+            labels = {
+              severity = "critical"
+            }
+            annotations = {
+              summary     = "Certificado não está pronto"
+              description = "O certificado {{ $labels.namespace }}/{{ $labels.name }} não está Ready há 15 minutos."
+            }
+          },
+        ]
+      }]
+    }
+  }
+
+  depends_on = [helm_release.kube_prometheus_stack]
+}
+
+# External Secrets: erros de sincronização
+resource "kubernetes_manifest" "alerts_external_secrets" {
+  count = var.enable_alert_rules ? 1 : 0
+
+  manifest = {
+    apiVersion = "monitoring.coreos.com/v1"
+    kind       = "PrometheusRule"
+    metadata = {
+      name      = "platform-external-secrets"
+      namespace = kubernetes_namespace.observability.metadata[0].name
+      labels = {
+        release                     = "kube-prometheus-stack"
+        "app.kubernetes.io/part-of" = "external-secrets"
+      }
+    }
+    spec = {
+      groups = [{
+        name = "external-secrets"
+        rules = [
+          {
+            alert = "ExternalSecretSyncError"
+            expr  = "increase(externalsecret_sync_calls_error[15m]) > 0"
+            for   = "15m"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Erro de sincronização do External Secrets"
+              description = "O ExternalSecret {{ $labels.namespace }}/{{ $labels.name }} falha ao sincronizar há 15 minutos."
+            }
+          },
+          {
+            alert = "ExternalSecretNotReady"
+            expr  = "externalsecret_status_condition{condition=\"Ready\",status=\"False\"} == 1"
+            for   = "15m"
+            labels = {
+              severity = "critical"
+            }
+            annotations = {
+              summary     = "ExternalSecret não está pronto"
+              description = "O ExternalSecret {{ $labels.namespace }}/{{ $labels.name }} não está Ready há 15 minutos."
+            }
+          },
+        ]
+      }]
+    }
+  }
+
+  depends_on = [helm_release.kube_prometheus_stack]
+}
+
+# Policy engine: falhas nos webhooks de admissão do Kyverno ou do Gatekeeper,
+# medidas pelo API server (métricas coletadas pelo kube-prometheus-stack)
+resource "kubernetes_manifest" "alerts_policy_engine" {
+  count = var.enable_alert_rules ? 1 : 0
+
+  manifest = {
+    apiVersion = "monitoring.coreos.com/v1"
+    kind       = "PrometheusRule"
+    metadata = {
+      name      = "platform-policy-engine"
+      namespace = kubernetes_namespace.observability.metadata[0].name
+      labels = {
+        release                     = "kube-prometheus-stack"
+        "app.kubernetes.io/part-of" = "kube-prometheus-stack"
+      }
+    }
+    spec = {
+      groups = [{
+        name = "policy-engine"
+        rules = [
+          {
+            alert = "PolicyWebhookFailing"
+            expr  = "sum by (name) (rate(apiserver_admission_webhook_rejection_count{name=~\".*kyverno.*|.*gatekeeper.*\",error_type=~\"calling_webhook_error|apiserver_internal_error\"}[5m])) > 0"
+            for   = "10m"
+            labels = {
+              severity = "critical"
+            }
+            annotations = {
+              summary     = "Webhook de políticas falhando"
+              description = "O API server não consegue chamar o webhook {{ $labels.name }} há 10 minutos."
+            }
+          },
+          {
+            alert = "PolicyWebhookFailOpen"
+            expr  = "sum by (name) (increase(apiserver_admission_webhook_fail_open_count{name=~\".*kyverno.*|.*gatekeeper.*\"}[10m])) > 0"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Webhook de políticas ignorado"
+              description = "Requisições passaram sem avaliação pelo webhook {{ $labels.name }} (failurePolicy Ignore)."
+            }
+          },
+          {
+            alert = "PolicyWebhookSlow"
+            expr  = "histogram_quantile(0.99, sum by (name, le) (rate(apiserver_admission_webhook_admission_duration_seconds_bucket{name=~\".*kyverno.*|.*gatekeeper.*\"}[5m]))) > 2"
+            for   = "15m"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Webhook de políticas lento"
+              description = "O p99 do webhook {{ $labels.name }} está acima de 2s há 15 minutos."
+            }
+          },
+        ]
+      }]
+    }
+  }
+
+  depends_on = [helm_release.kube_prometheus_stack]
+}
+
+# ArgoCD: aplicações fora de sincronia, degradadas ou com sync falhando
+resource "kubernetes_manifest" "alerts_argocd" {
+  count = var.enable_alert_rules ? 1 : 0
+
+  manifest = {
+    apiVersion = "monitoring.coreos.com/v1"
+    kind       = "PrometheusRule"
+    metadata = {
+      name      = "platform-argocd"
+      namespace = kubernetes_namespace.observability.metadata[0].name
+      labels = {
+        release                     = "kube-prometheus-stack"
+        "app.kubernetes.io/part-of" = "argo-cd"
+      }
+    }
+    spec = {
+      groups = [{
+        name = "argocd"
+        rules = [
+          {
+            alert = "ArgoCDAppOutOfSync"
+            expr  = "argocd_app_info{sync_status=\"OutOfSync\"} == 1"
+            for   = "30m"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Aplicação do ArgoCD fora de sincronia"
+              description = "A aplicação {{ $labels.name }} está OutOfSync há 30 minutos."
+            }
+          },
+          {
+            alert = "ArgoCDAppUnhealthy"
+            expr  = "argocd_app_info{health_status=~\"Degraded|Missing\"} == 1"
+            for   = "15m"
+            labels = {
+              severity = "critical"
+            }
+            annotations = {
+              summary     = "Aplicação do ArgoCD degradada"
+              description = "A aplicação {{ $labels.name }} está {{ $labels.health_status }} há 15 minutos."
+            }
+          },
+          {
+            alert = "ArgoCDAppSyncFailed"
+            expr  = "increase(argocd_app_sync_total{phase=~\"Error|Failed\"}[10m]) > 0"
+            labels = {
+              severity = "warning"
+            }
+            annotations = {
+              summary     = "Sync do ArgoCD falhou"
+              description = "O sync da aplicação {{ $labels.name }} terminou em {{ $labels.phase }}."
+            }
+          },
+        ]
+      }]
+    }
+  }
+
+  depends_on = [helm_release.kube_prometheus_stack]
+}
@@ -64,9 +64,7 @@ resource "helm_release" "kube_prometheus_stack" {
       # Grafana configuration
       grafana = {
         enabled = true
-        
-        adminPassword = var.grafana_admin_password
-        
+
         persistence = {
           enabled          = true
           storageClassName = var.storage_class
@@ -148,9 +146,21 @@ resource "helm_release" "kube_prometheus_stack" {
       kubeStateMetrics = {
         enabled = true
       }
+
+      # Subchart kube-state-metrics (o bloco acima apenas habilita)
+      kube-state-metrics = {
+        nodeSelector = var.node_selector
+        tolerations  = var.tolerations
+      }
     })
   ]
 
+  # Senha fora dos values para não ficar em texto plano no estado e no plano
+  set_sensitive {
+    name  = "grafana.adminPassword"
+    value = var.grafana_admin_password
+  }
+
   timeout = 600
 }
 
@@ -191,9 +201,8 @@ resource "helm_release" "loki" {
         }
       }
 
-      # Modo single binary para simplicidade
-      deploymentMode = "SingleBinary"
-
+      # Modo single binary para simplicidade (o chart 5.x seleciona o modo
+      # pelas réplicas: singleBinary > 0 e read/write/backend = 0)
       singleBinary = {
         replicas = 1
         
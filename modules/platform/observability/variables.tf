@@ -82,3 +82,31 @@ variable "tolerations" {
     effect   = "NoSchedule"
   }]
 }
+
+variable "enable_alert_rules" {
+  description = "Criar as PrometheusRules do catálogo de alertas dos componentes da plataforma"
+  type        = bool
+  default     = true
+}
+
+variable "certificate_expiry_warning_days" {
+  description = "Dias antes da expiração de um certificado do cert-manager para alertar"
+  type        = number
+  default     = 21
+
+  validation {
+    condition     = var.certificate_expiry_warning_days > 0
+    error_message = "certificate_expiry_warning_days deve ser positivo"
+  }
+}
+
+variable "backup_max_age_hours" {
+  description = "Horas sem backup bem-sucedido de um schedule do Velero para alertar"
+  type        = number
+  default     = 25
+
+  validation {
+    condition     = var.backup_max_age_hours > 0
+    error_message = "backup_max_age_hours deve ser positivo"
+  }
+}
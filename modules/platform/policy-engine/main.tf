@@ -76,10 +76,11 @@ resource "helm_release" "gatekeeper" {
   values = [
     yamlencode({
       replicas = 3
-      nodeSelector = var.node_selector
-      tolerations  = var.tolerations
+      controllerManager = {
+        nodeSelector = var.node_selector
+        tolerations  = var.tolerations
+      }
       audit = {
-        replicas = 1
         nodeSelector = var.node_selector
         tolerations  = var.tolerations
       }
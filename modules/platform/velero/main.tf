@@ -93,13 +93,28 @@ resource "aws_s3_bucket_lifecycle_configuration" "velero_backups" {
   }
 }
 
-# Bucket policy para prevenir deleção acidental
+# Bucket policy para prevenir deleção acidental e exigir TLS
 resource "aws_s3_bucket_policy" "velero_backups" {
   bucket = aws_s3_bucket.velero_backups.id
 
   policy = jsonencode({
     Version = "2012-10-17"
     Statement = [
+      {
+        Sid       = "DenyInsecureTransport"
+        Effect    = "Deny"
+        Principal = "*"
+        Action    = "s3:*"
+        Resource = [
+          aws_s3_bucket.velero_backups.arn,
+          "${aws_s3_bucket.velero_backups.arn}/*"
+        ]
+        Condition = {
+          Bool = {
+            "aws:SecureTransport" = "false"
+          }
+        }
+      },
       {
         Sid    = "DenyDeleteBucket"
         Effect = "Deny"
@@ -233,23 +248,28 @@ resource "helm_release" "velero" {
         }]
       }]
 
+      # A partir do chart 5.x as localizações são listas com provider por item
       configuration = {
-        provider = "aws"
-
-        backupStorageLocation = {
-          bucket = aws_s3_bucket.velero_backups.id
+        backupStorageLocation = [{
+          name     = "default"
+          provider = "aws"
+          bucket   = aws_s3_bucket.velero_backups.id
           config = {
             region = var.aws_region
           }
-        }
+        }]
 
-        volumeSnapshotLocation = var.enable_volume_snapshots ? {
+        volumeSnapshotLocation = [{
+          name     = "default"
+          provider = "aws"
           config = {
             region = var.aws_region
           }
-        } : null
+        }]
       }
 
+      snapshotsEnabled = var.enable_volume_snapshots
+
       serviceAccount = {
         server = {
           create = true
@@ -0,0 +1,376 @@
+// Comando templatecheck executa as verificações do template fora do go test.
+//
+// Uso:
+//
+//	templatecheck [flags] lint
+//	templatecheck [flags] env <ambiente>        (ex: staging ou gcp/staging)
+//	templatecheck [flags] policy [ambiente...]
+//	templatecheck [flags] plan <plano.json>
+//	templatecheck [flags] cost [ambiente...]
+//	templatecheck [flags] cost diff --base <revisão> [--head <revisão>] <ambiente>
+//	templatecheck [flags] cost diff <ambiente-base> <ambiente-head>
+//	templatecheck [flags] capacity [ambiente...]
+//	templatecheck [flags] observability [ambiente...]
+//	templatecheck [flags] dns [ambiente...]
+//	templatecheck checks
+//
+// O plano deve ser gerado com "terraform show -json tfplan > plano.json".
+//
+// Flags:
+//
+//	--root      raiz do repositório (padrão: $TEMPLATE_ROOT ou o primeiro diretório acima
+//	            do atual com .template-layout.yaml, ou com modules/ e live/)
+//	--format    text, json, sarif ou junit (padrão: text)
+//	--output    arquivo de saída (padrão: stdout)
+//	--fail-on   menor severidade que reprova a execução: error, warning ou note (padrão: error)
+//	--baseline  arquivo de achados aceitos (padrão: baseline do .template-layout.yaml)
+//	--base      revisão do git comparada por "cost diff"
+//	--head      revisão do git do outro lado de "cost diff" (padrão: árvore atual)
+//
+// "cost diff" imprime em Markdown (ou JSON, com --format json) a diferença de
+// custo mensal e sua origem por variável, no formato do comentário do plano no
+// pull request. A comparação é informativa e não reprova a execução.
+//
+// Achados podem ser aceitos com o comentário "# templatecheck:ignore <verificação>
+// <motivo>" na linha do achado ou na anterior, ou no baseline, com data de
+// expiração. Supressões expiradas ou que não suprimem nenhum achado reprovam a
+// execução.
+//
+// Códigos de saída: 0 sem achados no limite, 1 com achados, 2 em erro de uso ou execução.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+)
+
+const toolName = "templatecheck"
+
+// errUsage indica argumentos inválidos; a mensagem de uso já foi impressa
+var errUsage = errors.New("uso inválido")
+
+// options são as flags comuns a todos os subcomandos
+type options struct {
+	root     string
+	format   string
+	output   string
+	failOn   string
+	baseline string
+	base     string
+	head     string
+}
+
+func main() {
+	os.Exit(execute(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// execute executa o subcomando e retorna o código de saída
+func execute(args []string, stdout, stderr io.Writer) int {
+	failed, err := run(args, stdout, stderr)
+	switch {
+	case err != nil:
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "%s: %v\n", toolName, err)
+		}
+		return 2
+	case failed:
+		return 1
+	}
+	return 0
+}
+
+// run executa o subcomando e indica se algum achado atingiu o limite de severidade
+func run(args []string, stdout, stderr io.Writer) (bool, error) {
+	opts := &options{}
+	fs := newFlagSet(opts, stderr)
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return false, errUsage
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return false, errUsage
+	}
+
+	threshold, err := helpers.ParseSeverity(opts.failOn)
+	if err != nil {
+		return false, err
+	}
+	if opts.format != "text" && opts.format != "json" && opts.format != "sarif" && opts.format != "junit" {
+		return false, fmt.Errorf("formato desconhecido %q (use text, json, sarif ou junit)", opts.format)
+	}
+
+	root, err := resolveRoot(opts.root)
+	if err != nil {
+		return false, err
+	}
+	helpers.SetProjectRoot(root)
+	if _, err := helpers.LoadLayout(root); err != nil {
+		return false, err
+	}
+	report := helpers.NewReport(root)
+
+	command, params := positional[0], positional[1:]
+	switch command {
+	case "lint":
+		err = requireArgs(fs, params, 0)
+		if err == nil {
+			err = helpers.RunLintChecks(report)
+		}
+	case "env":
+		err = requireArgs(fs, params, 1)
+		if err == nil {
+			err = helpers.RunEnvironmentChecks(report, params[0])
+		}
+	case "policy":
+		envs := params
+		if len(envs) == 0 {
+			envs, err = helpers.ListAllEnvironments()
+		}
+		if err == nil {
+			err = helpers.RunPolicyChecks(report, envs...)
+		}
+	case "plan":
+		err = requireArgs(fs, params, 1)
+		if err == nil {
+			err = helpers.RunPlanChecks(report, params[0])
+		}
+	case "cost":
+		if len(params) > 0 && params[0] == "diff" {
+			return false, runCostDiff(fs, opts, params[1:], stdout)
+		}
+		envs := params
+		if len(envs) == 0 {
+			envs, err = helpers.ListAllEnvironments()
+		}
+		if err == nil {
+			var estimates []*helpers.CostEstimate
+			estimates, err = helpers.RunCostChecks(report, helpers.GetCostConfigPath(), envs...)
+			// O detalhamento em Markdown acompanha apenas a saída em texto
+			if err == nil && opts.format == "text" && opts.output == "" {
+				for _, estimate := range estimates {
+					fmt.Fprintln(stdout, estimate.Markdown())
+				}
+			}
+		}
+	case "capacity":
+		envs := params
+		if len(envs) == 0 {
+			envs, err = helpers.ListAllEnvironments()
+		}
+		if err == nil {
+			var capacities []*helpers.EnvironmentCapacity
+			capacities, err = helpers.RunCapacityChecks(report, helpers.GetInstanceTablePath(), envs...)
+			if err == nil && opts.format == "text" && opts.output == "" {
+				for _, capacity := range capacities {
+					fmt.Fprintln(stdout, capacity.Markdown())
+				}
+			}
+		}
+	case "observability":
+		envs := params
+		if len(envs) == 0 {
+			envs, err = helpers.ListAllEnvironments()
+		}
+		if err == nil {
+			var results []*helpers.EnvironmentObservability
+			results, err = helpers.RunObservabilityChecks(report, helpers.GetObservabilityConfigPath(), envs...)
+			if err == nil && opts.format == "text" && opts.output == "" {
+				for _, result := range results {
+					fmt.Fprintln(stdout, result.Markdown())
+				}
+			}
+		}
+	case "dns":
+		envs := params
+		if len(envs) == 0 {
+			envs, err = helpers.ListAllEnvironments()
+		}
+		if err == nil {
+			_, err = helpers.RunExternalDNSChecks(report, envs...)
+		}
+	case "checks":
+		for _, check := range helpers.CheckCatalog() {
+			fmt.Fprintf(stdout, "%-26s %-6s %s\n", check.ID, check.Requirement, check.Description)
+		}
+		return false, nil
+	default:
+		fmt.Fprintf(stderr, "subcomando desconhecido %q\n", command)
+		fs.Usage()
+		return false, errUsage
+	}
+	if err != nil {
+		return false, err
+	}
+
+	baseline := opts.baseline
+	if baseline == "" {
+		baseline = helpers.GetBaselinePath()
+	}
+	if err := report.Suppress(baseline, time.Now()); err != nil {
+		return false, err
+	}
+
+	if err := writeReport(report, opts, stdout); err != nil {
+		return false, err
+	}
+	return report.Failed(threshold), nil
+}
+
+// newFlagSet declara as flags comuns em opts
+func newFlagSet(opts *options, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(toolName, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.root, "root", "", "raiz do repositório")
+	fs.StringVar(&opts.format, "format", "text", "formato da saída: text, json, sarif ou junit")
+	fs.StringVar(&opts.output, "output", "", "arquivo de saída (padrão: stdout)")
+	fs.StringVar(&opts.failOn, "fail-on", "error", "menor severidade que reprova a execução: error, warning ou note")
+	fs.StringVar(&opts.baseline, "baseline", "", "arquivo de achados aceitos (padrão: baseline do .template-layout.yaml)")
+	fs.StringVar(&opts.base, "base", "", "revisão do git comparada por cost diff")
+	fs.StringVar(&opts.head, "head", "", "revisão do git do outro lado de cost diff (padrão: árvore atual)")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "uso: %s [flags] lint | env <ambiente> | policy [ambiente...] | plan <plano.json> | cost [ambiente...] | cost diff ... | capacity [ambiente...] | observability [ambiente...] | dns [ambiente...] | checks\n\n", toolName)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runCostDiff compara o custo de duas revisões do mesmo ambiente (--base e
+// --head) ou de dois ambientes da árvore atual
+func runCostDiff(fs *flag.FlagSet, opts *options, params []string, stdout io.Writer) error {
+	if opts.format != "text" && opts.format != "json" {
+		return fmt.Errorf("cost diff aceita apenas os formatos text e json")
+	}
+	config, err := helpers.LoadCostConfig(helpers.GetCostConfigPath())
+	if err != nil {
+		return err
+	}
+
+	var base, head helpers.CostSource
+	switch {
+	case opts.base != "" && len(params) == 1:
+		var cleanup func()
+		base, cleanup, err = helpers.RevisionCostSource(opts.base, params[0])
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		head = helpers.EnvironmentCostSource(params[0])
+		if opts.head != "" {
+			head, cleanup, err = helpers.RevisionCostSource(opts.head, params[0])
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+		}
+	case opts.base == "" && opts.head == "" && len(params) == 2:
+		base, head = helpers.EnvironmentCostSource(params[0]), helpers.EnvironmentCostSource(params[1])
+	default:
+		fs.Usage()
+		return errUsage
+	}
+
+	diff, err := helpers.DiffCost(base, head, config)
+	if err != nil {
+		return err
+	}
+
+	out := stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	if opts.format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+	_, err = fmt.Fprint(out, diff.Markdown())
+	return err
+}
+
+// parseInterspersed aceita flags antes e depois dos argumentos posicionais
+// (ex: "templatecheck env staging --format sarif")
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// requireArgs valida a quantidade de argumentos do subcomando
+func requireArgs(fs *flag.FlagSet, params []string, n int) error {
+	if len(params) != n {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// resolveRoot usa a flag --root ou, sem ela, a raiz detectada pelos helpers
+// (TEMPLATE_ROOT ou o marcador .template-layout.yaml acima do diretório atual)
+func resolveRoot(flagRoot string) (string, error) {
+	if flagRoot == "" {
+		if root := os.Getenv(helpers.ProjectRootEnv); root != "" {
+			flagRoot = root
+		} else {
+			dir, err := os.Getwd()
+			if err != nil {
+				return "", err
+			}
+			return helpers.FindProjectRoot(dir)
+		}
+	}
+
+	root, err := filepath.Abs(flagRoot)
+	if err != nil {
+		return "", err
+	}
+	if !helpers.DirectoryExists(root) {
+		return "", fmt.Errorf("raiz %s não existe", flagRoot)
+	}
+	return root, nil
+}
+
+// writeReport grava o relatório no formato e destino escolhidos
+func writeReport(report *helpers.Report, opts *options, stdout io.Writer) error {
+	out := stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch opts.format {
+	case "json":
+		return report.WriteJSON(out)
+	case "sarif":
+		return report.WriteSARIF(out, toolName)
+	case "junit":
+		return report.WriteJUnit(out)
+	}
+	return report.WriteText(out)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sarifLog é o subconjunto do SARIF lido pelos testes
+type sarifLog struct {
+	Runs []struct {
+		Results []struct {
+			RuleID    string            `json:"ruleId"`
+			Locations []json.RawMessage `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+// TestRun valida os códigos de saída, as flags antes e depois dos argumentos,
+// o limite de severidade, os formatos e a gravação em arquivo do templatecheck
+// Valida: Requisitos 16.4
+func TestRun(t *testing.T) {
+	root := helpers.GetProjectRoot()
+	plan := helpers.GetTestPath("testdata", "plans", "staging-replace-cluster.json")
+	output := filepath.Join(t.TempDir(), "report.sarif")
+
+	cases := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "sem subcomando", args: nil, code: 2, stderr: "uso: templatecheck"},
+		{name: "subcomando desconhecido", args: []string{"deploy"}, code: 2, stderr: `subcomando desconhecido "deploy"`},
+		{name: "flag desconhecida", args: []string{"--strict", "lint"}, code: 2, stderr: "flag provided but not defined: -strict"},
+		{name: "argumentos faltando", args: []string{"env"}, code: 2, stderr: "uso: templatecheck"},
+		{name: "argumentos sobrando", args: []string{"plan", plan, plan}, code: 2, stderr: "uso: templatecheck"},
+		{name: "formato desconhecido", args: []string{"--format", "xml", "lint"}, code: 2, stderr: `formato desconhecido "xml"`},
+		{name: "severidade desconhecida", args: []string{"--fail-on", "critical", "lint"}, code: 2, stderr: "critical"},
+		{name: "formato do cost diff", args: []string{"cost", "diff", "staging", "prod", "--format", "sarif"}, code: 2, stderr: "cost diff aceita apenas os formatos text e json"},
+		{name: "raiz inexistente", args: []string{"--root", filepath.Join(root, "ausente"), "lint"}, code: 2, stderr: "não existe"},
+		{name: "catálogo", args: []string{"checks"}, code: 0, stdout: helpers.CheckPlanDestructiveChange},
+		{name: "plano destrutivo", args: []string{"plan", plan}, code: 1, stdout: "plano substitui module.eks_cluster.aws_eks_cluster.main"},
+		{name: "flags depois dos argumentos", args: []string{"plan", plan, "--format", "json"}, code: 1, stdout: `"check_id": "plan-destructive-change"`},
+		{name: "notas não reprovam por padrão", args: []string{"--root", root, "env", "staging"}, code: 0, stdout: "[note] upgrade-plan"},
+		{name: "fail-on note", args: []string{"env", "staging", "--fail-on", "note"}, code: 1, stdout: "[note] upgrade-plan"},
+		{name: "saída em arquivo", args: []string{"--format", "sarif", "--output", output, "plan", plan}, code: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, c.code, execute(c.args, &stdout, &stderr), "stdout:\n%s\nstderr:\n%s", &stdout, &stderr)
+			assert.Contains(t, stdout.String(), c.stdout)
+			assert.Contains(t, stderr.String(), c.stderr)
+			if c.stdout == "" {
+				assert.Empty(t, stdout.String(), "Saída deve ir apenas para --output")
+			}
+		})
+	}
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(content, &log))
+	require.Len(t, log.Runs, 1)
+	assert.NotEmpty(t, log.Runs[0].Results)
+}
+
+// TestParseInterspersed valida a separação entre flags e argumentos posicionais
+// em qualquer ordem
+// Valida: Requisitos 16.4
+func TestParseInterspersed(t *testing.T) {
+	opts := &options{}
+	fs := newFlagSet(opts, io.Discard)
+	positional, err := parseInterspersed(fs, []string{"cost", "--format", "json", "diff", "--base=main", "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cost", "diff", "staging"}, positional)
+	assert.Equal(t, "json", opts.format)
+	assert.Equal(t, "main", opts.base)
+
+	positional, err = parseInterspersed(newFlagSet(&options{}, io.Discard), []string{"env", "--", "--format"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env", "--format"}, positional, "Argumentos depois de -- não são flags")
+}
+
+// TestSARIFLocations valida que os achados do plano de upgrade apontam o arquivo
+// e a linha no SARIF, o que permite anotações e supressões inline
+// Valida: Requisitos 5.5, 16.4
+func TestSARIFLocations(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, execute([]string{"env", "staging", "--format", "sarif"}, &stdout, &stderr), stderr.String())
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	upgrades := 0
+	for _, result := range log.Runs[0].Results {
+		if result.RuleID != helpers.CheckUpgradePlan {
+			continue
+		}
+		upgrades++
+		assert.Len(t, result.Locations, 1, "Resultado de %s sem localização", result.RuleID)
+	}
+	assert.Positive(t, upgrades, "Staging deve ter itens no plano de upgrade")
+}
@@ -0,0 +1,80 @@
+// Comando traceability gera a matriz de rastreabilidade entre os critérios de
+// aceitação do spec e os testes que os citam em "Valida: Requisitos".
+//
+// Uso:
+//
+//	go test -json ./... | go run ./cmd/traceability -results -
+//	go run ./cmd/traceability -config testdata/traceability.yaml
+//
+// Termina com código 1 quando um teste cita critério inexistente ou quando um
+// critério obrigatório da configuração fica sem cobertura.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+)
+
+func main() {
+	requirementsPath := flag.String("requirements", helpers.GetRequirementsPath(), "documento de requisitos do spec")
+	configPath := flag.String("config", helpers.GetTraceabilityConfigPath(), "configuração dos critérios obrigatórios")
+	resultsPath := flag.String("results", "", "saída de go test -json (\"-\" para stdin); sem resultados, basta o teste existir")
+	flag.Parse()
+
+	if err := run(*requirementsPath, *configPath, *resultsPath, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(requirementsPath, configPath, resultsPath string, stdin io.Reader, stdout io.Writer) error {
+	criteria, err := helpers.ParseRequirements(requirementsPath)
+	if err != nil {
+		return err
+	}
+	refs, err := helpers.TestReferences(helpers.GetTestDirs()...)
+	if err != nil {
+		return err
+	}
+	config, err := helpers.LoadTraceabilityConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var results map[string]helpers.TestStatus
+	if resultsPath != "" {
+		input := stdin
+		if resultsPath != "-" {
+			f, err := os.Open(resultsPath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			input = f
+		}
+		if results, err = helpers.ParseGoTestJSON(input); err != nil {
+			return err
+		}
+	}
+
+	matrix := helpers.BuildTraceabilityMatrix(criteria, refs, results)
+	fmt.Fprint(stdout, matrix.Markdown())
+
+	missing := matrix.MissingRequired(config.Required)
+	if len(missing) > 0 {
+		fmt.Fprintln(stdout, "\nCritérios obrigatórios sem cobertura:")
+		fmt.Fprintln(stdout)
+		for _, criterion := range missing {
+			fmt.Fprintf(stdout, "- %s (%s): %s\n", criterion.ID, criterion.Title, criterion.Text)
+		}
+	}
+	if len(missing) > 0 || len(matrix.Unknown) > 0 {
+		return fmt.Errorf("rastreabilidade: %d critérios obrigatórios sem cobertura, %d citações inválidas",
+			len(missing), len(matrix.Unknown))
+	}
+	return nil
+}
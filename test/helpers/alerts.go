@@ -0,0 +1,272 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// AlertRule é uma regra de alerta de um PrometheusRule declarado em kubernetes_manifest
+type AlertRule struct {
+	// Source é o endereço do recurso kubernetes_manifest
+	Source string
+	// Chart é o chart que exporta as métricas (label app.kubernetes.io/part-of)
+	Chart       string
+	Group       string
+	Alert       string
+	Expr        string
+	For         string
+	Labels      map[string]string
+	Annotations map[string]string
+	File        string
+	Line        int
+}
+
+// alertSeverities são os valores aceitos no label severity das regras
+var alertSeverities = map[string]bool{"critical": true, "warning": true, "info": true}
+
+// requiredAlerts são os alertas exigidos para cada chart instalado
+var requiredAlerts = map[string][]string{
+	"velero":           {"VeleroBackupFailed", "VeleroBackupTooOld"},
+	"cert-manager":     {"CertManagerCertificateExpiringSoon", "CertManagerCertificateNotReady"},
+	"external-secrets": {"ExternalSecretSyncError"},
+	"kyverno":          {"PolicyWebhookFailing"},
+	"gatekeeper":       {"PolicyWebhookFailing"},
+	"argo-cd":          {"ArgoCDAppOutOfSync"},
+}
+
+// AlertRules extrai as regras de alerta dos recursos kubernetes_manifest de kind PrometheusRule
+func AlertRules(instances []*ResourceInstance) ([]*AlertRule, error) {
+	var rules []*AlertRule
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "kubernetes_manifest" {
+			continue
+		}
+		if inst.Diagnostics.HasErrors() {
+			return nil, fmt.Errorf("%s: erro ao avaliar recurso: %s", inst.Address(), inst.Diagnostics.Error())
+		}
+		manifest, _ := inst.Values()["manifest"].(map[string]interface{})
+		if manifest["kind"] != "PrometheusRule" {
+			continue
+		}
+		chart := stringMap(lookupHelmValue(manifest, "metadata.labels"))["app.kubernetes.io/part-of"]
+		groups, _ := lookupHelmValue(manifest, "spec.groups").([]interface{})
+		for _, g := range groups {
+			group, _ := g.(map[string]interface{})
+			groupName, _ := group["name"].(string)
+			entries, _ := group["rules"].([]interface{})
+			for _, r := range entries {
+				entry, _ := r.(map[string]interface{})
+				rule := &AlertRule{
+					Source: inst.Address(), Chart: chart, Group: groupName,
+					Labels: stringMap(entry["labels"]), Annotations: stringMap(entry["annotations"]),
+					File: inst.Resource.Range.Filename, Line: inst.Resource.Range.Start.Line,
+				}
+				rule.Alert, _ = entry["alert"].(string)
+				rule.Expr, _ = entry["expr"].(string)
+				rule.For, _ = entry["for"].(string)
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// stringMap converte um objeto avaliado em mapa de strings
+func stringMap(value interface{}) map[string]string {
+	result := make(map[string]string)
+	obj, _ := value.(map[string]interface{})
+	for key, v := range obj {
+		result[key] = fmt.Sprint(v)
+	}
+	return result
+}
+
+// ExprMetrics interpreta a expressão com o parser do Prometheus e retorna os
+// nomes das métricas selecionadas, ordenados
+func ExprMetrics(expr string) ([]string, error) {
+	parsed, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	parser.Inspect(parsed, func(node parser.Node, _ []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		name := selector.Name
+		for _, matcher := range selector.LabelMatchers {
+			if matcher.Name == labels.MetricName && matcher.Type == labels.MatchEqual {
+				name = matcher.Value
+			}
+		}
+		if name != "" {
+			seen[name] = true
+		}
+		return nil
+	})
+	metrics := make([]string, 0, len(seen))
+	for name := range seen {
+		metrics = append(metrics, name)
+	}
+	sort.Strings(metrics)
+	return metrics, nil
+}
+
+// GetChartMetricsPath retorna o caminho da lista de métricas de um chart/versão
+func GetChartMetricsPath(chart, version string) string {
+	return GetTestPath("testdata", "charts", chart, version, "metrics.yaml")
+}
+
+// LoadChartMetrics lê as métricas exportadas por um chart/versão. Histogramas e
+// summaries incluem as séries _bucket (só histogramas), _sum e _count
+func LoadChartMetrics(chart, version string) (map[string]bool, error) {
+	path := GetChartMetricsPath(chart, version)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Metrics map[string]string `yaml:"metrics"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	metrics := make(map[string]bool)
+	for name, metricType := range doc.Metrics {
+		metrics[name] = true
+		switch metricType {
+		case "histogram":
+			metrics[name+"_bucket"] = true
+			fallthrough
+		case "summary":
+			metrics[name+"_sum"] = true
+			metrics[name+"_count"] = true
+		case "counter", "gauge":
+		default:
+			return nil, fmt.Errorf("%s: %s: tipo desconhecido %q", path, name, metricType)
+		}
+	}
+	return metrics, nil
+}
+
+// AlertRuleError é uma falha de uma regra de alerta ou de um chart instalado,
+// localizada no recurso da regra ou no helm_release do chart
+type AlertRuleError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *AlertRuleError) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s:%d %s", e.File, e.Line, e.Message)
+}
+
+// ValidateAlertRules valida as regras contra os charts instalados: a expressão deve
+// ser PromQL válido e usar apenas métricas do chart da regra (app.kubernetes.io/part-of)
+// ou do kube-prometheus-stack, na versão instalada; cada chart instalado deve ter
+// os alertas exigidos
+func ValidateAlertRules(rules []*AlertRule, releases []*HelmRelease) []*AlertRuleError {
+	installed := make(map[string]*HelmRelease)
+	for _, release := range releases {
+		installed[release.Chart] = release
+	}
+	catalogs := make(map[string]map[string]bool)
+	var errs []*AlertRuleError
+	chartError := func(chart string, format string, args ...interface{}) {
+		err := &AlertRuleError{Message: fmt.Sprintf(format, args...)}
+		if release, ok := installed[chart]; ok {
+			err.File, err.Line = release.File, release.Line
+		}
+		errs = append(errs, err)
+	}
+	chartMetrics := func(chart string) map[string]bool {
+		if metrics, ok := catalogs[chart]; ok {
+			return metrics
+		}
+		version := ""
+		if release, ok := installed[chart]; ok {
+			version = release.Version
+		}
+		metrics, err := LoadChartMetrics(chart, version)
+		if err != nil {
+			chartError(chart, "métricas de %s %s não vendorizadas em test/testdata/charts: %v", chart, version, err)
+		}
+		catalogs[chart] = metrics
+		return metrics
+	}
+
+	defined := make(map[string]bool)
+	for _, rule := range rules {
+		defined[rule.Alert] = true
+		ruleError := func(format string, args ...interface{}) {
+			errs = append(errs, &AlertRuleError{
+				File: rule.File, Line: rule.Line,
+				Message: fmt.Sprintf("%s %s: ", rule.Source, rule.Alert) + fmt.Sprintf(format, args...),
+			})
+		}
+		if rule.Alert == "" {
+			ruleError("regra sem alert (recording rules não são aceitas no catálogo)")
+			continue
+		}
+		if !alertSeverities[rule.Labels["severity"]] {
+			ruleError("label severity %q deve ser critical, warning ou info", rule.Labels["severity"])
+		}
+		if rule.Annotations["summary"] == "" || rule.Annotations["description"] == "" {
+			ruleError("annotations summary e description são obrigatórias")
+		}
+		if rule.For != "" {
+			if _, err := parsePrometheusDuration(rule.For); err != nil {
+				ruleError("for: %v", err)
+			}
+		}
+
+		metrics, err := ExprMetrics(rule.Expr)
+		if err != nil {
+			ruleError("expr inválida: %v", err)
+			continue
+		}
+		release, ok := installed[rule.Chart]
+		if !ok {
+			ruleError("chart %q (app.kubernetes.io/part-of) não instalado", rule.Chart)
+			continue
+		}
+		available := chartMetrics(rule.Chart)
+		scraper := chartMetrics("kube-prometheus-stack")
+		if available == nil || scraper == nil {
+			continue
+		}
+		for _, metric := range metrics {
+			if !available[metric] && !scraper[metric] {
+				ruleError("métrica %s não exportada por %s %s", metric, rule.Chart, release.Version)
+			}
+		}
+	}
+
+	charts := make([]string, 0, len(installed))
+	for chart := range installed {
+		charts = append(charts, chart)
+	}
+	sort.Strings(charts)
+	for _, chart := range charts {
+		var missing []string
+		for _, alert := range requiredAlerts[chart] {
+			if !defined[alert] {
+				missing = append(missing, alert)
+			}
+		}
+		if len(missing) > 0 {
+			chartError(chart, "%s instalado sem os alertas %s", chart, strings.Join(missing, ", "))
+		}
+	}
+	return errs
+}
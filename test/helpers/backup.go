@@ -0,0 +1,243 @@
+package helpers
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// expectedBackupRPO é o maior RPO aceito em cada ambiente (12.3), indexado pelo
+// nome do ambiente sem a nuvem
+var expectedBackupRPO = map[string]time.Duration{
+	"staging": 24 * time.Hour,
+	"prod":    6 * time.Hour,
+}
+
+// BackupSchedule é um schedule do chart do Velero renderizado em um ambiente, com
+// o lifecycle do bucket de backups, os namespaces criados pelos módulos e o limite
+// do alerta de backup atrasado
+type BackupSchedule struct {
+	Env      string
+	Release  string
+	Name     string
+	Schedule string
+	// Cron é nil quando a expressão é inválida; CronError descreve o erro
+	Cron      *CronSchedule
+	CronError error
+	TTL       string
+	// ExpirationDays é a menor expiração habilitada no lifecycle do bucket (0 se ausente)
+	ExpirationDays     int
+	Bucket             string
+	IncludedNamespaces []string
+	ExcludedNamespaces []string
+	// PlatformNamespaces são os kubernetes_namespace criados pelos módulos do ambiente
+	PlatformNamespaces []string
+	// AlertHours é backup_max_age_hours do módulo observability (0 se ausente)
+	AlertHours int
+	File       string
+	Line       int
+}
+
+// BackupSchedules retorna os schedules dos helm_release do Velero declarados pelos
+// módulos filhos do ambiente
+func BackupSchedules(env string, ev *Evaluator) ([]*BackupSchedule, error) {
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "kubernetes_namespace" {
+			continue
+		}
+		metadata, _ := inst.Values()["metadata"].([]interface{})
+		for _, m := range metadata {
+			entry, _ := m.(map[string]interface{})
+			if name, ok := entry["name"].(string); ok && !IsComputedPlaceholder(name) {
+				namespaces = append(namespaces, name)
+			}
+		}
+	}
+	sort.Strings(namespaces)
+
+	names := make([]string, 0, len(ev.Module.ModuleCalls))
+	for name := range ev.Module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	alertHours := 0
+	var schedules []*BackupSchedule
+	for _, name := range names {
+		child, err := ev.Child(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := child.Module.Variables["backup_max_age_hours"]; ok {
+			if v := child.Var("backup_max_age_hours"); v != cty.NilVal && v.IsKnown() && !v.IsNull() && v.Type() == cty.Number {
+				hours, _ := v.AsBigFloat().Int64()
+				alertHours = int(hours)
+			}
+		}
+
+		expirations, err := bucketExpirations(child)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range child.Module.SortedResources() {
+			if r.Mode != "managed" || r.Type != "helm_release" {
+				continue
+			}
+			instances, err := child.Instances(r.Address())
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range instances {
+				release, err := RenderHelmRelease(inst)
+				if err != nil {
+					return nil, err
+				}
+				if release.Chart != "velero" {
+					continue
+				}
+				var bucket string
+				locations, _ := lookupHelmValue(release.Values, "configuration.backupStorageLocation").([]interface{})
+				for _, l := range locations {
+					location, _ := l.(map[string]interface{})
+					if b, ok := location["bucket"].(string); ok {
+						bucket = b
+						break
+					}
+				}
+
+				configured, _ := release.Values["schedules"].(map[string]interface{})
+				scheduleNames := make([]string, 0, len(configured))
+				for scheduleName := range configured {
+					scheduleNames = append(scheduleNames, scheduleName)
+				}
+				sort.Strings(scheduleNames)
+				for _, scheduleName := range scheduleNames {
+					values, _ := configured[scheduleName].(map[string]interface{})
+					if disabled, _ := values["disabled"].(bool); disabled {
+						continue
+					}
+					schedule := &BackupSchedule{
+						Env: env, Release: release.Address, Name: scheduleName, Bucket: bucket,
+						ExpirationDays: expirations[bucket], PlatformNamespaces: namespaces,
+						File: release.File, Line: release.Line,
+					}
+					schedule.Schedule, _ = values["schedule"].(string)
+					schedule.Cron, schedule.CronError = ParseCron(schedule.Schedule)
+					schedule.TTL, _ = lookupHelmValue(values, "template.ttl").(string)
+					schedule.IncludedNamespaces = stringList(lookupHelmValue(values, "template.includedNamespaces"))
+					schedule.ExcludedNamespaces = stringList(lookupHelmValue(values, "template.excludedNamespaces"))
+					schedules = append(schedules, schedule)
+				}
+			}
+		}
+	}
+	for _, schedule := range schedules {
+		schedule.AlertHours = alertHours
+	}
+	return schedules, nil
+}
+
+// bucketExpirations retorna, por bucket, a menor expiração habilitada nos
+// aws_s3_bucket_lifecycle_configuration do módulo
+func bucketExpirations(module *Evaluator) (map[string]int, error) {
+	expirations := make(map[string]int)
+	for _, r := range module.Module.SortedResources() {
+		if r.Mode != "managed" || r.Type != "aws_s3_bucket_lifecycle_configuration" {
+			continue
+		}
+		instances, err := module.Instances(r.Address())
+		if err != nil {
+			return nil, err
+		}
+		for _, inst := range instances {
+			values := inst.Values()
+			bucket, _ := values["bucket"].(string)
+			rules, _ := values["rule"].([]interface{})
+			for _, r := range rules {
+				rule, _ := r.(map[string]interface{})
+				if rule["status"] != "Enabled" {
+					continue
+				}
+				expiration, _ := rule["expiration"].([]interface{})
+				for _, e := range expiration {
+					entry, _ := e.(map[string]interface{})
+					days, _ := entry["days"].(int)
+					if days > 0 && (expirations[bucket] == 0 || days < expirations[bucket]) {
+						expirations[bucket] = days
+					}
+				}
+			}
+		}
+	}
+	return expirations, nil
+}
+
+// RPOProblems compara o pior RPO do schedule com o esperado no ambiente e com o
+// limite do alerta de backup atrasado, que dispararia entre execuções normais
+func (s *BackupSchedule) RPOProblems() []string {
+	if s.CronError != nil {
+		return []string{s.CronError.Error()}
+	}
+	var problems []string
+	if expected, ok := expectedBackupRPO[path.Base(s.Env)]; ok && s.Cron.RPO > expected {
+		problems = append(problems, fmt.Sprintf("schedule %q tem RPO de %s; esperado no máximo %s", s.Schedule, formatHours(s.Cron.RPO), formatHours(expected)))
+	}
+	if s.AlertHours > 0 && s.Cron.RPO >= time.Duration(s.AlertHours)*time.Hour {
+		problems = append(problems, fmt.Sprintf("schedule %q tem RPO de %s; backup_max_age_hours = %d dispara entre execuções normais", s.Schedule, formatHours(s.Cron.RPO), s.AlertHours))
+	}
+	return problems
+}
+
+// RetentionProblems verifica se o TTL dos backups não passa da expiração do
+// lifecycle do bucket, que apagaria os objetos antes do Velero
+func (s *BackupSchedule) RetentionProblems() []string {
+	ttl, err := time.ParseDuration(s.TTL)
+	if err != nil || ttl <= 0 {
+		return []string{fmt.Sprintf("ttl %q inválido", s.TTL)}
+	}
+	if s.ExpirationDays == 0 {
+		return []string{fmt.Sprintf("bucket %q sem regra de expiração no lifecycle", s.Bucket)}
+	}
+	expiration := time.Duration(s.ExpirationDays) * 24 * time.Hour
+	if ttl > expiration {
+		return []string{fmt.Sprintf("ttl %s maior que a expiração de %d dias do bucket %q; o S3 apaga backups que o Velero ainda lista", formatHours(ttl), s.ExpirationDays, s.Bucket)}
+	}
+	return nil
+}
+
+// NamespaceProblems lista os namespaces criados pelos módulos que o schedule não
+// inclui e que não estão em excludedNamespaces
+func (s *BackupSchedule) NamespaceProblems() []string {
+	included := make(map[string]bool)
+	for _, namespace := range s.IncludedNamespaces {
+		included[namespace] = true
+	}
+	excluded := make(map[string]bool)
+	for _, namespace := range s.ExcludedNamespaces {
+		excluded[namespace] = true
+	}
+	var problems []string
+	for _, namespace := range s.PlatformNamespaces {
+		if excluded[namespace] || included["*"] || included[namespace] {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("namespace %s não está em includedNamespaces nem em excludedNamespaces", namespace))
+	}
+	return problems
+}
+
+// formatHours formata uma duração em horas quando ela é um número inteiro de horas
+func formatHours(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return d.String()
+}
@@ -0,0 +1,318 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// publicAccessBlockFlags são os atributos de aws_s3_bucket_public_access_block
+// que devem ser true
+var publicAccessBlockFlags = []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"}
+
+// BucketRule é a postura exigida de um bucket
+type BucketRule struct {
+	Encryption     string `yaml:"encryption"`
+	ProtectObjects bool   `yaml:"protect_objects"`
+}
+
+// BucketPostureConfig são as regras de postura dos buckets S3 por tag Purpose
+// (testdata/s3_buckets.yaml)
+type BucketPostureConfig struct {
+	Default BucketRule             `yaml:"default"`
+	Buckets map[string]*BucketRule `yaml:"buckets"`
+}
+
+// GetBucketPostureConfigPath retorna o caminho das regras de postura dos buckets
+func GetBucketPostureConfigPath() string {
+	return GetTestPath("testdata", "s3_buckets.yaml")
+}
+
+// LoadBucketPostureConfig lê as regras de postura dos buckets
+func LoadBucketPostureConfig(path string) (*BucketPostureConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &BucketPostureConfig{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	rules := map[string]*BucketRule{"default": &config.Default}
+	for purpose, rule := range config.Buckets {
+		rules["buckets."+purpose] = rule
+	}
+	for name, rule := range rules {
+		if rule.Encryption != "AES256" && rule.Encryption != "aws:kms" {
+			return nil, fmt.Errorf("%s: %s.encryption deve ser AES256 ou aws:kms", path, name)
+		}
+	}
+	return config, nil
+}
+
+// Rule retorna a regra do Purpose do bucket ou a regra padrão
+func (c *BucketPostureConfig) Rule(purpose string) *BucketRule {
+	if rule, ok := c.Buckets[purpose]; ok {
+		return rule
+	}
+	return &c.Default
+}
+
+// BucketPosture é a configuração de segurança de um aws_s3_bucket, reunida dos
+// recursos aws_s3_bucket_* que referenciam o mesmo bucket
+type BucketPosture struct {
+	Env     string
+	Address string
+	Name    string
+	Purpose string
+	Rule    *BucketRule
+	// PublicAccessBlock tem os flags de aws_s3_bucket_public_access_block (vazio se ausente)
+	PublicAccessBlock map[string]bool
+	SSEAlgorithm      string
+	KMSKeyID          string
+	Versioning        string
+	// NoncurrentDays é a menor expiração de versões não correntes no lifecycle (0 se ausente)
+	NoncurrentDays int
+	// DenyInsecureTransport indica um Deny de s3:* com aws:SecureTransport = false
+	// sobre o ARN do bucket e <arn>/*
+	DenyInsecureTransport bool
+	// DeniedDeletes são as ações de deleção negadas a todos os principals no ARN
+	// do bucket (s3:DeleteBucket) ou em <arn>/* (deleção de objetos)
+	DeniedDeletes []string
+	// PolicyError descreve a falha ao interpretar a bucket policy
+	PolicyError error
+	File        string
+	Line        int
+}
+
+// bucketStatementJSON é um statement de bucket policy com o Principal
+type bucketStatementJSON struct {
+	iamStatementJSON
+	Principal json.RawMessage `json:"Principal"`
+}
+
+// allPrincipals indica se o Principal é "*" ou {"AWS": "*"}
+func (s *bucketStatementJSON) allPrincipals() bool {
+	var single string
+	if json.Unmarshal(s.Principal, &single) == nil {
+		return single == "*"
+	}
+	var principals map[string]iamStrings
+	if json.Unmarshal(s.Principal, &principals) != nil {
+		return false
+	}
+	for _, principal := range principals["AWS"] {
+		if principal == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// BucketPostures lê os aws_s3_bucket das instâncias expandidas e as configurações
+// de versionamento, criptografia, bloqueio de acesso público, lifecycle e policy
+// associadas a cada um pelo nome do bucket
+func BucketPostures(env string, instances []*ResourceInstance, config *BucketPostureConfig) ([]*BucketPosture, error) {
+	postures := make(map[string]*BucketPosture)
+	var names []string
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "aws_s3_bucket" {
+			continue
+		}
+		values := inst.Values()
+		posture := &BucketPosture{
+			Env: env, Address: inst.Address(), PublicAccessBlock: make(map[string]bool),
+			File: inst.Resource.Range.Filename, Line: inst.Resource.Range.Start.Line,
+		}
+		posture.Name, _ = values["bucket"].(string)
+		posture.Purpose = stringMap(values["tags"])["Purpose"]
+		posture.Rule = config.Rule(posture.Purpose)
+		postures[posture.Name] = posture
+		names = append(names, posture.Name)
+	}
+	sort.Strings(names)
+
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" {
+			continue
+		}
+		values := inst.Values()
+		bucket, _ := values["bucket"].(string)
+		posture, ok := postures[bucket]
+		if !ok {
+			continue
+		}
+		switch inst.Resource.Type {
+		case "aws_s3_bucket_public_access_block":
+			for _, flag := range publicAccessBlockFlags {
+				posture.PublicAccessBlock[flag], _ = values[flag].(bool)
+			}
+		case "aws_s3_bucket_server_side_encryption_configuration":
+			rules, _ := values["rule"].([]interface{})
+			for _, r := range rules {
+				rule, _ := r.(map[string]interface{})
+				defaults, _ := rule["apply_server_side_encryption_by_default"].([]interface{})
+				for _, d := range defaults {
+					entry, _ := d.(map[string]interface{})
+					posture.SSEAlgorithm, _ = entry["sse_algorithm"].(string)
+					posture.KMSKeyID, _ = entry["kms_master_key_id"].(string)
+				}
+			}
+		case "aws_s3_bucket_versioning":
+			configurations, _ := values["versioning_configuration"].([]interface{})
+			for _, c := range configurations {
+				entry, _ := c.(map[string]interface{})
+				posture.Versioning, _ = entry["status"].(string)
+			}
+		case "aws_s3_bucket_lifecycle_configuration":
+			rules, _ := values["rule"].([]interface{})
+			for _, r := range rules {
+				rule, _ := r.(map[string]interface{})
+				if rule["status"] != "Enabled" {
+					continue
+				}
+				expirations, _ := rule["noncurrent_version_expiration"].([]interface{})
+				for _, e := range expirations {
+					entry, _ := e.(map[string]interface{})
+					days, _ := entry["noncurrent_days"].(int)
+					if days > 0 && (posture.NoncurrentDays == 0 || days < posture.NoncurrentDays) {
+						posture.NoncurrentDays = days
+					}
+				}
+			}
+		case "aws_s3_bucket_policy":
+			policy, _ := values["policy"].(string)
+			posture.PolicyError = posture.readPolicy(policy)
+		}
+	}
+
+	result := make([]*BucketPosture, 0, len(names))
+	for _, name := range names {
+		result = append(result, postures[name])
+	}
+	return result, nil
+}
+
+// readPolicy lê da bucket policy o Deny sem TLS e as deleções negadas a todos
+func (b *BucketPosture) readPolicy(policy string) error {
+	var doc struct {
+		Statement []bucketStatementJSON `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return fmt.Errorf("bucket policy inválida: %w", err)
+	}
+	bucketARN := "arn:aws:s3:::" + b.Name
+	objectsARN := bucketARN + "/*"
+	for _, statement := range doc.Statement {
+		if statement.Effect != "Deny" || !statement.allPrincipals() {
+			continue
+		}
+		deniesAll := false
+		for _, action := range statement.Action {
+			if iamWildcard(action).MatchString("s3:GetObject") && iamWildcard(action).MatchString("s3:PutObject") {
+				deniesAll = true
+			}
+		}
+		if len(statement.Condition) == 0 {
+			for action, resource := range map[string]string{
+				"s3:DeleteBucket": bucketARN, "s3:DeleteObject": objectsARN, "s3:DeleteObjectVersion": objectsARN,
+			} {
+				if statement.covers(action, resource) {
+					b.DeniedDeletes = append(b.DeniedDeletes, action)
+				}
+			}
+			continue
+		}
+		secure := statement.Condition["Bool"]["aws:SecureTransport"]
+		if deniesAll && len(statement.Condition) == 1 && len(secure) == 1 && strings.EqualFold(secure[0], "false") &&
+			statement.coversResource(bucketARN) && statement.coversResource(objectsARN) {
+			b.DenyInsecureTransport = true
+		}
+	}
+	sort.Strings(b.DeniedDeletes)
+	return nil
+}
+
+// covers indica se o statement se aplica à ação no recurso
+func (s *bucketStatementJSON) covers(action, resource string) bool {
+	for _, pattern := range s.Action {
+		if iamWildcard(pattern).MatchString(action) {
+			return s.coversResource(resource)
+		}
+	}
+	return false
+}
+
+// coversResource indica se algum Resource do statement casa com o ARN
+func (s *bucketStatementJSON) coversResource(arn string) bool {
+	for _, pattern := range s.Resource {
+		if iamWildcard(pattern).MatchString(arn) {
+			return true
+		}
+	}
+	return false
+}
+
+// Problems lista as divergências do bucket em relação à regra do seu Purpose
+func (b *BucketPosture) Problems() []string {
+	var problems []string
+	for _, flag := range publicAccessBlockFlags {
+		if !b.PublicAccessBlock[flag] {
+			problems = append(problems, fmt.Sprintf("aws_s3_bucket_public_access_block.%s deve ser true", flag))
+		}
+	}
+
+	switch {
+	case b.SSEAlgorithm == "":
+		problems = append(problems, "sem aws_s3_bucket_server_side_encryption_configuration")
+	case b.Rule.Encryption == "aws:kms" && (!strings.HasPrefix(b.SSEAlgorithm, "aws:kms") || b.KMSKeyID == ""):
+		problems = append(problems, fmt.Sprintf("criptografia %s; a regra de %s exige aws:kms com kms_master_key_id", b.SSEAlgorithm, b.purposeName()))
+	case b.SSEAlgorithm != "AES256" && !strings.HasPrefix(b.SSEAlgorithm, "aws:kms"):
+		problems = append(problems, fmt.Sprintf("criptografia %q desconhecida", b.SSEAlgorithm))
+	}
+
+	if b.Versioning != "Enabled" {
+		problems = append(problems, fmt.Sprintf("versionamento %q; esperado Enabled", b.Versioning))
+	}
+	if b.NoncurrentDays == 0 {
+		problems = append(problems, "lifecycle sem noncurrent_version_expiration; versões antigas ficam para sempre")
+	}
+
+	if b.PolicyError != nil {
+		return append(problems, b.PolicyError.Error())
+	}
+	if !b.DenyInsecureTransport {
+		problems = append(problems, "bucket policy sem Deny de s3:* com aws:SecureTransport = false no bucket e em <arn>/*")
+	}
+	required := []string{"s3:DeleteBucket"}
+	if b.Rule.ProtectObjects {
+		required = append(required, "s3:DeleteObject", "s3:DeleteObjectVersion")
+	}
+	for _, action := range required {
+		if !containsString(b.DeniedDeletes, action) {
+			problems = append(problems, fmt.Sprintf("bucket policy não nega %s a todos os principals", action))
+		}
+	}
+	return problems
+}
+
+// purposeName descreve o Purpose do bucket nas mensagens
+func (b *BucketPosture) purposeName() string {
+	if b.Purpose == "" {
+		return "buckets sem Purpose"
+	}
+	return "Purpose=" + b.Purpose
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
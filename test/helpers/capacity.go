@@ -0,0 +1,480 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// evictionHardMemoryMiB é o limite de despejo (memory.available) do kubelet nas AMIs do EKS
+	evictionHardMemoryMiB = 100
+	// maxPodsSmallInstance é o teto de pods por node recomendado pelo EKS para
+	// instâncias com menos de 30 vCPUs; acima, o teto é maxPodsLargeInstance
+	maxPodsSmallInstance = 110
+	maxPodsLargeInstance = 250
+)
+
+// Resources é uma quantidade de CPU, memória e pods
+type Resources struct {
+	// CPU em milicores
+	CPU int64 `json:"cpu_millicores"`
+	// Memory em MiB
+	Memory int64 `json:"memory_mib"`
+	Pods   int   `json:"pods"`
+}
+
+// Add soma duas quantidades
+func (r Resources) Add(other Resources) Resources {
+	return Resources{CPU: r.CPU + other.CPU, Memory: r.Memory + other.Memory, Pods: r.Pods + other.Pods}
+}
+
+// Scale multiplica a quantidade por n (ex: nodes ou réplicas)
+func (r Resources) Scale(n int) Resources {
+	return Resources{CPU: r.CPU * int64(n), Memory: r.Memory * int64(n), Pods: r.Pods * n}
+}
+
+// String formata a quantidade (ex: "1930m CPU, 3554Mi, 17 pods")
+func (r Resources) String() string {
+	return fmt.Sprintf("%dm CPU, %dMi, %d pods", r.CPU, r.Memory, r.Pods)
+}
+
+// InstanceSpec são os limites de um tipo de instância
+type InstanceSpec struct {
+	VCPU      int   `yaml:"vcpu"`
+	MemoryMiB int64 `yaml:"memory_mib"`
+	// ENIs e IPv4PerENI definem o máximo de pods com o VPC CNI
+	ENIs       int `yaml:"enis"`
+	IPv4PerENI int `yaml:"ipv4_per_eni"`
+}
+
+// SystemPod é um pod instalado pelo EKS (ex: coredns, aws-node)
+type SystemPod struct {
+	Name      string `yaml:"name"`
+	DaemonSet bool   `yaml:"daemonset"`
+	Replicas  int    `yaml:"replicas"`
+	CPU       string `yaml:"cpu"`
+	Memory    string `yaml:"memory"`
+}
+
+// InstanceTable é a tabela versionada de tipos de instância (testdata/instances.yaml)
+type InstanceTable struct {
+	Version    string                   `yaml:"version"`
+	Instances  map[string]*InstanceSpec `yaml:"instances"`
+	SystemPods []*SystemPod             `yaml:"system_pods"`
+	path       string
+}
+
+// GetInstanceTablePath retorna a tabela de tipos de instância
+func GetInstanceTablePath() string {
+	return GetTestPath("testdata", "instances.yaml")
+}
+
+// LoadInstanceTable lê a tabela de tipos de instância
+func LoadInstanceTable(path string) (*InstanceTable, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	table := &InstanceTable{path: path}
+	if err := yaml.Unmarshal(content, table); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if table.Version == "" {
+		return nil, fmt.Errorf("%s: version obrigatório", path)
+	}
+	return table, nil
+}
+
+// NodeCapacity é a capacidade de um node: total, reservada pelo kubelet e
+// alocável para pods
+type NodeCapacity struct {
+	InstanceType string    `json:"instance_type"`
+	Capacity     Resources `json:"capacity"`
+	Reserved     Resources `json:"reserved"`
+	Allocatable  Resources `json:"allocatable"`
+}
+
+// Node calcula a capacidade de um node do tipo informado com as fórmulas das
+// AMIs do EKS: máximo de pods do VPC CNI (ENIs × (IPv4 por ENI - 1) + 2), CPU
+// reservada por faixa de cores e memória reservada de 11Mi por pod + 255Mi,
+// mais o limite de despejo
+func (t *InstanceTable) Node(instanceType string) (*NodeCapacity, error) {
+	spec, ok := t.Instances[instanceType]
+	if !ok {
+		return nil, fmt.Errorf("%s: instances.%s ausente", t.path, instanceType)
+	}
+	pods := MaxPods(spec)
+	node := &NodeCapacity{
+		InstanceType: instanceType,
+		Capacity:     Resources{CPU: int64(spec.VCPU) * 1000, Memory: spec.MemoryMiB, Pods: pods},
+		Reserved:     Resources{CPU: reservedCPU(spec.VCPU), Memory: int64(11*pods+255) + evictionHardMemoryMiB},
+	}
+	node.Allocatable = Resources{
+		CPU:    node.Capacity.CPU - node.Reserved.CPU,
+		Memory: node.Capacity.Memory - node.Reserved.Memory,
+		Pods:   pods,
+	}
+	return node, nil
+}
+
+// MaxPods retorna o máximo de pods por node com o VPC CNI sem prefix delegation
+func MaxPods(spec *InstanceSpec) int {
+	pods := spec.ENIs*(spec.IPv4PerENI-1) + 2
+	limit := maxPodsSmallInstance
+	if spec.VCPU >= 30 {
+		limit = maxPodsLargeInstance
+	}
+	if pods > limit {
+		return limit
+	}
+	return pods
+}
+
+// reservedCPU retorna os milicores reservados pelo kubelet: 6% do primeiro
+// core, 1% do segundo, 0,5% do terceiro e quarto e 0,25% dos demais
+func reservedCPU(vcpu int) int64 {
+	reserved := 0.0
+	for core := 1; core <= vcpu; core++ {
+		switch {
+		case core == 1:
+			reserved += 60
+		case core == 2:
+			reserved += 10
+		case core <= 4:
+			reserved += 5
+		default:
+			reserved += 2.5
+		}
+	}
+	return int64(math.Round(reserved))
+}
+
+// NodeGroupCapacity é a capacidade de um node group. O tipo considerado é o de
+// menor capacidade entre os instance_types (pior caso).
+type NodeGroupCapacity struct {
+	*NodeGroup
+	Address     string        `json:"address"`
+	Node        *NodeCapacity `json:"node"`
+	MinSize     int           `json:"min_size"`
+	DesiredSize int           `json:"desired_size"`
+	MaxSize     int           `json:"max_size"`
+}
+
+// At retorna a capacidade alocável do node group com n nodes
+func (g *NodeGroupCapacity) At(nodes int) Resources {
+	return g.Node.Allocatable.Scale(nodes)
+}
+
+// ClusterCapacity é a capacidade alocável do cluster
+type ClusterCapacity struct {
+	Groups []*NodeGroupCapacity `json:"groups"`
+}
+
+// Min retorna a capacidade com todos os node groups no min_size
+func (c *ClusterCapacity) Min() Resources {
+	total := Resources{}
+	for _, g := range c.Groups {
+		total = total.Add(g.At(g.MinSize))
+	}
+	return total
+}
+
+// Max retorna a capacidade com todos os node groups no max_size
+func (c *ClusterCapacity) Max() Resources {
+	total := Resources{}
+	for _, g := range c.Groups {
+		total = total.Add(g.At(g.MaxSize))
+	}
+	return total
+}
+
+// Group retorna o node group com o label role informado
+func (c *ClusterCapacity) Group(role string) *NodeGroupCapacity {
+	for _, g := range c.Groups {
+		if g.Labels["role"] == role {
+			return g
+		}
+	}
+	return nil
+}
+
+// ClusterCapacityFromInstances calcula a capacidade dos node groups
+// (aws_eks_node_group) de instâncias avaliadas
+func ClusterCapacityFromInstances(instances []*ResourceInstance, table *InstanceTable) (*ClusterCapacity, error) {
+	capacity := &ClusterCapacity{}
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "aws_eks_node_group" {
+			continue
+		}
+		attrs := inst.Values()
+		group := &NodeGroupCapacity{NodeGroup: nodeGroupFromInstance(inst), Address: inst.Address()}
+		for _, config := range listValue(attrs["scaling_config"]) {
+			config, _ := config.(map[string]interface{})
+			for key, target := range map[string]*int{"min_size": &group.MinSize, "desired_size": &group.DesiredSize, "max_size": &group.MaxSize} {
+				value, _ := toFloat(config[key])
+				*target = int(value)
+			}
+		}
+
+		types := listValue(attrs["instance_types"])
+		if len(types) == 0 {
+			types = []interface{}{defaultNodeInstanceType}
+		}
+		for _, t := range types {
+			node, err := table.Node(fmt.Sprint(t))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", inst.Address(), err)
+			}
+			if group.Node == nil || smallerNode(node, group.Node) {
+				group.Node = node
+			}
+		}
+		capacity.Groups = append(capacity.Groups, group)
+	}
+	sort.Slice(capacity.Groups, func(i, j int) bool { return capacity.Groups[i].Name < capacity.Groups[j].Name })
+	return capacity, nil
+}
+
+// smallerNode compara nodes por pods, memória e CPU alocáveis, nessa ordem
+func smallerNode(a, b *NodeCapacity) bool {
+	if a.Allocatable.Pods != b.Allocatable.Pods {
+		return a.Allocatable.Pods < b.Allocatable.Pods
+	}
+	if a.Allocatable.Memory != b.Allocatable.Memory {
+		return a.Allocatable.Memory < b.Allocatable.Memory
+	}
+	return a.Allocatable.CPU < b.Allocatable.CPU
+}
+
+// CapacityFit compara a demanda dos workloads com a capacidade de um node group
+type CapacityFit struct {
+	Group     string    `json:"group"`
+	Nodes     int       `json:"nodes"`
+	Available Resources `json:"available"`
+	Demand    Resources `json:"demand"`
+	// Undeclared são os workloads sem resources.requests, contados apenas em pods
+	Undeclared []string `json:"undeclared,omitempty"`
+	Problems   []string `json:"problems,omitempty"`
+}
+
+// Fits indica se a demanda cabe no node group
+func (f *CapacityFit) Fits() bool {
+	return len(f.Problems) == 0
+}
+
+// Fit soma os requests dos workloads que podem ser agendados no node group e
+// dos pods do EKS e compara com a capacidade alocável com n nodes. DaemonSets
+// ocupam um pod por node; hooks (Job) não ocupam capacidade. A comparação é
+// pelo total, sem simular o empacotamento, e cada pod deve caber em um node.
+func (g *NodeGroupCapacity) Fit(placements []*PodPlacement, systemPods []*SystemPod, nodes int) (*CapacityFit, error) {
+	fit := &CapacityFit{Group: g.Name, Nodes: nodes, Available: g.At(nodes)}
+
+	add := func(name string, requests *Resources, replicas int, daemonSet bool) {
+		pod := Resources{Pods: 1}
+		if requests != nil {
+			pod = Resources{CPU: requests.CPU, Memory: requests.Memory, Pods: 1}
+		}
+		if daemonSet {
+			replicas = nodes
+		}
+		fit.Demand = fit.Demand.Add(pod.Scale(replicas))
+		if pod.CPU > g.Node.Allocatable.CPU || pod.Memory > g.Node.Allocatable.Memory {
+			fit.Problems = append(fit.Problems, fmt.Sprintf("%s pede %s, acima do alocável de um node %s (%s)",
+				name, pod, g.Node.InstanceType, g.Node.Allocatable))
+		}
+	}
+
+	for _, p := range placements {
+		if p.Job || !g.Accepts(p) {
+			continue
+		}
+		if p.Requests == nil {
+			fit.Undeclared = append(fit.Undeclared, p.Name())
+		}
+		add(p.Name(), p.Requests, p.Replicas, p.DaemonSet)
+	}
+	for _, pod := range systemPods {
+		requests, err := parseRequests(map[string]interface{}{"cpu": pod.CPU, "memory": pod.Memory})
+		if err != nil {
+			return nil, fmt.Errorf("system_pods %s: %w", pod.Name, err)
+		}
+		replicas := pod.Replicas
+		if replicas == 0 {
+			replicas = 1
+		}
+		add(pod.Name, requests, replicas, pod.DaemonSet)
+	}
+
+	for _, dimension := range []struct {
+		name              string
+		demand, available int64
+	}{
+		{"pods", int64(fit.Demand.Pods), int64(fit.Available.Pods)},
+		{"CPU (milicores)", fit.Demand.CPU, fit.Available.CPU},
+		{"memória (Mi)", fit.Demand.Memory, fit.Available.Memory},
+	} {
+		if dimension.demand > dimension.available {
+			fit.Problems = append(fit.Problems, fmt.Sprintf("%s: demanda de %d %s acima do alocável de %d com %d× %s",
+				g.Name, dimension.demand, dimension.name, dimension.available, nodes, g.Node.InstanceType))
+		}
+	}
+	return fit, nil
+}
+
+// parseRequests lê cpu e memory de um bloco resources.requests; retorna nil
+// quando o bloco não existe ou não declara nenhum dos dois
+func parseRequests(value interface{}) (*Resources, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	requests := &Resources{Pods: 1}
+	declared := false
+	if cpu, ok := m["cpu"]; ok && cpu != nil && fmt.Sprint(cpu) != "" {
+		millicores, err := parseCPUQuantity(fmt.Sprint(cpu))
+		if err != nil {
+			return nil, err
+		}
+		requests.CPU, declared = millicores, true
+	}
+	if memory, ok := m["memory"]; ok && memory != nil && fmt.Sprint(memory) != "" {
+		mib, err := parseMemoryQuantity(fmt.Sprint(memory))
+		if err != nil {
+			return nil, err
+		}
+		requests.Memory, declared = mib, true
+	}
+	if !declared {
+		return nil, nil
+	}
+	return requests, nil
+}
+
+// parseCPUQuantity converte uma quantidade de CPU do Kubernetes ("250m", "0.5", "2") em milicores
+func parseCPUQuantity(s string) (int64, error) {
+	if strings.HasSuffix(s, "m") {
+		value, err := strconv.ParseFloat(strings.TrimSuffix(s, "m"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("quantidade de CPU inválida %q", s)
+		}
+		return int64(math.Ceil(value)), nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("quantidade de CPU inválida %q", s)
+	}
+	return int64(math.Ceil(value * 1000)), nil
+}
+
+// memoryUnits são os sufixos de quantidade de memória do Kubernetes, em bytes
+var memoryUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// parseMemoryQuantity converte uma quantidade de memória do Kubernetes ("512Mi", "1Gi", "128M") em MiB
+func parseMemoryQuantity(s string) (int64, error) {
+	bytes, err := parseQuantityBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("quantidade de memória inválida %q", s)
+	}
+	return int64(math.Ceil(bytes / (1 << 20))), nil
+}
+
+// parseQuantityBytes converte uma quantidade de memória ou armazenamento do Kubernetes ("50Gi", "128M") em bytes
+func parseQuantityBytes(s string) (float64, error) {
+	number, factor := s, 1.0
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			number, factor = strings.TrimSuffix(s, unit.suffix), unit.bytes
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("quantidade inválida %q", s)
+	}
+	return value * factor, nil
+}
+
+// EnvironmentCapacity é a capacidade de um ambiente e o encaixe dos add-ons no
+// node group system com min_size nodes
+type EnvironmentCapacity struct {
+	Environment  string           `json:"environment"`
+	TableVersion string           `json:"table_version"`
+	Cluster      *ClusterCapacity `json:"cluster"`
+	System       *CapacityFit     `json:"system"`
+}
+
+// EstimateEnvironmentCapacity avalia o ambiente e calcula a capacidade dos node
+// groups e a demanda dos add-ons no node group system
+func EstimateEnvironmentCapacity(env string, table *InstanceTable) (*EnvironmentCapacity, error) {
+	ev, err := NewEnvironmentEvaluator(env)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := ClusterCapacityFromInstances(instances, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", env, err)
+	}
+	system := cluster.Group("system")
+	if system == nil {
+		return nil, fmt.Errorf("%s: node group com role=system não encontrado", env)
+	}
+
+	releases, err := HelmReleases(instances)
+	if err != nil {
+		return nil, err
+	}
+	var placements []*PodPlacement
+	for _, release := range releases {
+		found, err := HelmPlacements(release)
+		if err != nil {
+			return nil, err
+		}
+		placements = append(placements, found...)
+	}
+	fit, err := system.Fit(placements, table.SystemPods, system.MinSize)
+	if err != nil {
+		return nil, err
+	}
+	return &EnvironmentCapacity{Environment: env, TableVersion: table.Version, Cluster: cluster, System: fit}, nil
+}
+
+// Markdown formata a capacidade por node group e a demanda no node group system
+func (c *EnvironmentCapacity) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Capacidade: %s\n\n", c.Environment)
+	fmt.Fprintf(&b, "Tabela de instâncias %s; o tipo considerado é o de menor capacidade do node group.\n\n", c.TableVersion)
+	b.WriteString("| Node group | Tipo | Alocável por node | Nodes (min-máx) | No mínimo | No máximo |\n")
+	b.WriteString("|------------|------|-------------------|-----------------|-----------|-----------|\n")
+	for _, g := range c.Cluster.Groups {
+		fmt.Fprintf(&b, "| %s | %s | %s | %d-%d | %s | %s |\n", g.Name, g.Node.InstanceType, g.Node.Allocatable,
+			g.MinSize, g.MaxSize, g.At(g.MinSize), g.At(g.MaxSize))
+	}
+	fmt.Fprintf(&b, "| **Cluster** | | | | **%s** | **%s** |\n", c.Cluster.Min(), c.Cluster.Max())
+
+	fit := c.System
+	fmt.Fprintf(&b, "\nAdd-ons e pods do EKS no node group %s com %d nodes: demanda de %s para %s alocáveis.\n",
+		fit.Group, fit.Nodes, fit.Demand, fit.Available)
+	for _, problem := range fit.Problems {
+		fmt.Fprintf(&b, "- ❌ %s\n", problem)
+	}
+	if len(fit.Undeclared) > 0 {
+		fmt.Fprintf(&b, "\n%d workloads sem resources.requests entram apenas na contagem de pods.\n", len(fit.Undeclared))
+	}
+	return b.String()
+}
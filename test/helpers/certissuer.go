@@ -0,0 +1,166 @@
+package helpers
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+)
+
+// URLs ACME do Let's Encrypt
+const (
+	LetsEncryptStagingURL    = "https://acme-staging-v02.api.letsencrypt.org/directory"
+	LetsEncryptProductionURL = "https://acme-v02.api.letsencrypt.org/directory"
+)
+
+// expectedACMEServer é o servidor ACME esperado em cada ambiente, indexado pelo
+// nome do ambiente sem a nuvem
+var expectedACMEServer = map[string]string{
+	"staging": LetsEncryptStagingURL,
+	"prod":    LetsEncryptProductionURL,
+}
+
+// placeholderEmail casa com endereços nos domínios reservados para exemplos (RFC 2606)
+var placeholderEmail = regexp.MustCompile(`(?i)@(.+\.)?example\.(com|net|org)$`)
+
+// ClusterIssuer é um ClusterIssuer ACME declarado em kubernetes_manifest, com o
+// contexto do módulo que o declara (tipo de ingress e zona Route53)
+type ClusterIssuer struct {
+	Env          string
+	Address      string
+	Name         string
+	Server       string
+	Email        string
+	Solvers      []map[string]interface{}
+	IngressType  string
+	IngressClass string
+	ZoneID       string
+	File         string
+	Line         int
+}
+
+// ClusterIssuers retorna os ClusterIssuers declarados pelos módulos filhos do ambiente
+func ClusterIssuers(env string, ev *Evaluator) ([]*ClusterIssuer, error) {
+	names := make([]string, 0, len(ev.Module.ModuleCalls))
+	for name := range ev.Module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issuers []*ClusterIssuer
+	for _, name := range names {
+		child, err := ev.Child(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range child.Module.SortedResources() {
+			if r.Mode != "managed" || r.Type != "kubernetes_manifest" {
+				continue
+			}
+			instances, err := child.Instances(r.Address())
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range instances {
+				if inst.Diagnostics.HasErrors() {
+					return nil, fmt.Errorf("%s: erro ao avaliar recurso: %s", inst.Address(), inst.Diagnostics.Error())
+				}
+				manifest, _ := inst.Values()["manifest"].(map[string]interface{})
+				if manifest["kind"] != "ClusterIssuer" {
+					continue
+				}
+				issuer := &ClusterIssuer{
+					Env: env, Address: inst.Address(),
+					File: inst.Resource.Range.Filename, Line: inst.Resource.Range.Start.Line,
+				}
+				issuer.Name, _ = lookupHelmValue(manifest, "metadata.name").(string)
+				issuer.Server, _ = lookupHelmValue(manifest, "spec.acme.server").(string)
+				issuer.Email, _ = lookupHelmValue(manifest, "spec.acme.email").(string)
+				solvers, _ := lookupHelmValue(manifest, "spec.acme.solvers").([]interface{})
+				for _, s := range solvers {
+					solver, _ := s.(map[string]interface{})
+					issuer.Solvers = append(issuer.Solvers, solver)
+				}
+				if v := child.Var("ingress_type"); isLiteralString(v) {
+					issuer.IngressType = v.AsString()
+				}
+				if v := child.Var("route53_zone_id"); isLiteralString(v) {
+					issuer.ZoneID = v.AsString()
+				}
+				if _, ok := child.Module.Outputs["ingress_class"]; ok {
+					class, err := child.Output("ingress_class")
+					if err != nil {
+						return nil, err
+					}
+					if isLiteralString(class) {
+						issuer.IngressClass = class.AsString()
+					}
+				}
+				issuers = append(issuers, issuer)
+			}
+		}
+	}
+	return issuers, nil
+}
+
+// Problems lista as divergências do ClusterIssuer: servidor ACME diferente do
+// esperado no ambiente, email de exemplo com o servidor de produção e solvers que
+// não correspondem ao ingress controller (HTTP-01) ou à zona Route53 (DNS-01)
+func (i *ClusterIssuer) Problems() []string {
+	var problems []string
+	if expected, ok := expectedACMEServer[path.Base(i.Env)]; ok && i.Server != expected {
+		problems = append(problems, fmt.Sprintf("servidor ACME %q; esperado %q", i.Server, expected))
+	}
+	if i.Email == "" {
+		problems = append(problems, "email ACME não definido")
+	} else if i.Server == LetsEncryptProductionURL && placeholderEmail.MatchString(i.Email) {
+		problems = append(problems, fmt.Sprintf("email %q é de exemplo; o Let's Encrypt de produção envia avisos de expiração para ele", i.Email))
+	}
+	if len(i.Solvers) == 0 {
+		problems = append(problems, "nenhum solver configurado")
+	}
+	for n, solver := range i.Solvers {
+		prefix := fmt.Sprintf("solvers[%d]", n)
+		switch {
+		case solver["http01"] != nil:
+			problems = append(problems, i.http01Problems(prefix, solver)...)
+		case solver["dns01"] != nil:
+			problems = append(problems, i.dns01Problems(prefix, solver)...)
+		default:
+			problems = append(problems, fmt.Sprintf("%s: apenas http01 e dns01 são suportados", prefix))
+		}
+	}
+	return problems
+}
+
+// http01Problems verifica se o Ingress do desafio usa a IngressClass do controller
+// instalado e, no ALB, se o load balancer é internet-facing
+func (i *ClusterIssuer) http01Problems(prefix string, solver map[string]interface{}) []string {
+	var problems []string
+	class, _ := lookupHelmValue(solver, "http01.ingress.ingressClassName").(string)
+	if class == "" {
+		class, _ = lookupHelmValue(solver, "http01.ingress.class").(string)
+	}
+	if class != i.IngressClass {
+		problems = append(problems, fmt.Sprintf("%s: http01 usa a IngressClass %q; o controller %s atende %q", prefix, class, i.IngressType, i.IngressClass))
+	}
+	if i.IngressType == "alb" {
+		annotations := stringMap(lookupHelmValue(solver, "http01.ingress.ingressTemplate.metadata.annotations"))
+		if annotations["alb.ingress.kubernetes.io/scheme"] != "internet-facing" {
+			problems = append(problems, fmt.Sprintf("%s: http01 no ALB precisa da anotação alb.ingress.kubernetes.io/scheme=internet-facing", prefix))
+		}
+	}
+	return problems
+}
+
+// dns01Problems verifica se o desafio DNS-01 usa o Route53 na zona do external-dns
+func (i *ClusterIssuer) dns01Problems(prefix string, solver map[string]interface{}) []string {
+	route53, ok := lookupHelmValue(solver, "dns01.route53").(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%s: dns01 deve usar o Route53", prefix)}
+	}
+	if zone, _ := route53["hostedZoneID"].(string); zone != i.ZoneID {
+		return []string{fmt.Sprintf("%s: dns01 usa a zona %q; a zona do external-dns é %q", prefix, zone, i.ZoneID)}
+	}
+	return nil
+}
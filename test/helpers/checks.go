@@ -0,0 +1,936 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// MaxSupportedKubernetesVersion é a maior versão aceita pela validação de cluster_version
+const MaxSupportedKubernetesVersion = "1.30"
+
+// IDs das verificações executadas fora do go test (cmd/templatecheck)
+const (
+	CheckModuleParse            = "module-parse"
+	CheckVariableDescription    = "variable-description"
+	CheckOutputDescription      = "output-description"
+	CheckRequirementTrace       = "requirement-traceability"
+	CheckPropertyLinkage        = "property-linkage"
+	CheckVariableValidation     = "variable-validation"
+	CheckHelmValuesSchema       = "helm-values-schema"
+	CheckAddonScheduling        = "addon-scheduling"
+	CheckKubernetesAPIRemovals  = "k8s-api-removals"
+	CheckUpgradePlan            = "upgrade-plan"
+	CheckPolicyRequired         = "policy-required"
+	CheckPolicyEnforcementMode  = "policy-enforcement-mode"
+	CheckPlanDestructiveChange  = "plan-destructive-change"
+	CheckPlanRequiredTags       = "plan-required-tags"
+	CheckPlanOpenIngress        = "plan-open-ingress"
+	CheckEKSPublicEndpoint      = "eks-public-endpoint"
+	CheckSuppression            = "suppression"
+	CheckCostBudget             = "cost-budget"
+	CheckNodeCapacity           = "node-capacity"
+	CheckObservabilityRetention = "observability-retention"
+	CheckObservabilityStorage   = "observability-storage"
+	CheckAlertRules             = "alert-rules"
+	CheckSensitiveLeak          = "sensitive-leak"
+	CheckIAMPolicyDrift         = "iam-policy-drift"
+	CheckExternalDNSScope       = "external-dns-scope"
+	CheckExternalDNSOwner       = "external-dns-owner"
+	CheckClusterIssuer          = "cluster-issuer"
+	CheckBackupSchedule         = "backup-schedule"
+	CheckBackupRetention        = "backup-retention"
+	CheckBackupNamespaces       = "backup-namespaces"
+	CheckS3BucketPosture        = "s3-bucket-posture"
+)
+
+// checkCatalog descreve cada verificação e o critério de aceitação que ela cobre
+var checkCatalog = map[string]*Check{
+	CheckModuleParse:            {ID: CheckModuleParse, Requirement: "3.1", Description: "Módulos e ambientes devem ser HCL válido"},
+	CheckVariableDescription:    {ID: CheckVariableDescription, Requirement: "15.3", Description: "Variáveis devem ter description"},
+	CheckOutputDescription:      {ID: CheckOutputDescription, Requirement: "15.4", Description: "Outputs devem ter description"},
+	CheckRequirementTrace:       {ID: CheckRequirementTrace, Requirement: "16.5", Description: "Testes devem citar critérios existentes e cobrir os obrigatórios"},
+	CheckPropertyLinkage:        {ID: CheckPropertyLinkage, Requirement: "16.5", Description: "Cada propriedade do design deve ter um teste que use os geradores"},
+	CheckVariableValidation:     {ID: CheckVariableValidation, Requirement: "13.7", Description: "Entradas do ambiente devem passar nos blocos validation"},
+	CheckHelmValuesSchema:       {ID: CheckHelmValuesSchema, Requirement: "7.5", Description: "Values dos helm_release devem seguir o schema do chart"},
+	CheckAddonScheduling:        {ID: CheckAddonScheduling, Requirement: "6.1", Description: "Add-ons devem ser agendados apenas no node group system"},
+	CheckKubernetesAPIRemovals:  {ID: CheckKubernetesAPIRemovals, Requirement: "5.5", Description: "Manifestos não devem usar APIs removidas até a maior versão suportada"},
+	CheckUpgradePlan:            {ID: CheckUpgradePlan, Requirement: "5.5", Description: "Upgrade até a maior versão suportada deve ter ação conhecida"},
+	CheckPolicyRequired:         {ID: CheckPolicyRequired, Requirement: "8.2", Description: "Políticas de segurança obrigatórias devem estar habilitadas"},
+	CheckPolicyEnforcementMode:  {ID: CheckPolicyEnforcementMode, Requirement: "8.6", Description: "Políticas em audit no staging e enforce em prod"},
+	CheckPlanDestructiveChange:  {ID: CheckPlanDestructiveChange, Requirement: "14.3", Description: "Plano não deve destruir recursos críticos"},
+	CheckPlanRequiredTags:       {ID: CheckPlanRequiredTags, Requirement: "17.1", Description: "Recursos devem ter as tags Environment, ManagedBy e Project"},
+	CheckPlanOpenIngress:        {ID: CheckPlanOpenIngress, Requirement: "5.6", Description: "Ingress e endpoint público não devem aceitar 0.0.0.0/0"},
+	CheckEKSPublicEndpoint:      {ID: CheckEKSPublicEndpoint, Requirement: "5.6", Description: "Endpoint público do cluster deve ser restrito por CIDR"},
+	CheckCostBudget:             {ID: CheckCostBudget, Requirement: "17.2", Description: "Custo mensal estimado deve caber no orçamento do ambiente"},
+	CheckNodeCapacity:           {ID: CheckNodeCapacity, Requirement: "6.7", Description: "Add-ons devem caber no node group system com min_size nodes"},
+	CheckObservabilityRetention: {ID: CheckObservabilityRetention, Requirement: "10.4", Description: "Retenção nos values do Prometheus e do Loki deve ser a das variáveis"},
+	CheckObservabilityStorage:   {ID: CheckObservabilityStorage, Requirement: "10.6", Description: "Volumes do Prometheus e do Loki devem comportar a janela de retenção"},
+	CheckAlertRules:             {ID: CheckAlertRules, Requirement: "10.1", Description: "Alertas dos componentes devem usar PromQL válido e métricas dos charts instalados"},
+	CheckIAMPolicyDrift:         {ID: CheckIAMPolicyDrift, Requirement: "11.2", Description: "Policies IAM dos controllers devem seguir a policy de referência da versão fixada do chart"},
+	CheckExternalDNSScope:       {ID: CheckExternalDNSScope, Requirement: "11.4", Description: "external-dns deve ter permissões e filtros restritos à zona Route53 do ambiente"},
+	CheckExternalDNSOwner:       {ID: CheckExternalDNSOwner, Requirement: "11.5", Description: "txtOwnerId do external-dns deve ser único por ambiente"},
+	CheckClusterIssuer:          {ID: CheckClusterIssuer, Requirement: "11.3", Description: "ClusterIssuer deve usar o servidor ACME do ambiente e solver compatível com o ingress e a zona Route53"},
+	CheckBackupSchedule:         {ID: CheckBackupSchedule, Requirement: "12.3", Description: "Schedule do Velero deve ter RPO dentro do esperado no ambiente e abaixo do alerta de backup atrasado"},
+	CheckBackupRetention:        {ID: CheckBackupRetention, Requirement: "12.4", Description: "TTL dos backups do Velero não deve passar da expiração do lifecycle do bucket"},
+	CheckBackupNamespaces:       {ID: CheckBackupNamespaces, Requirement: "12.3", Description: "Backups do Velero devem incluir os namespaces criados pelos módulos ou excluí-los explicitamente"},
+	CheckS3BucketPosture:        {ID: CheckS3BucketPosture, Requirement: "18.5", Description: "Buckets S3 devem bloquear acesso público, exigir TLS, ter criptografia, versionamento e proteção contra deleção"},
+	CheckSensitiveLeak:          {ID: CheckSensitiveLeak, Requirement: "16.3", Description: "Valores sensitive não devem chegar a outputs, tags, values ou exemplos de tfvars em texto plano"},
+	CheckSuppression:            {ID: CheckSuppression, Requirement: "16.4", Description: "Supressões devem ter motivo, estar no prazo e suprimir algum achado"},
+}
+
+// CheckCatalog retorna as verificações conhecidas, ordenadas por ID
+func CheckCatalog() []*Check {
+	checks := make([]*Check, 0, len(checkCatalog))
+	for _, check := range checkCatalog {
+		copied := *check
+		checks = append(checks, &copied)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].ID < checks[j].ID })
+	return checks
+}
+
+// addChecks registra no relatório as verificações do catálogo
+func (r *Report) addChecks(ids ...string) {
+	for _, id := range ids {
+		copied := *checkCatalog[id]
+		r.AddCheck(&copied)
+	}
+}
+
+// RunLintChecks executa as verificações estáticas dos módulos, dos ambientes e
+// da ligação entre spec e testes
+func RunLintChecks(report *Report) error {
+	report.addChecks(CheckModuleParse, CheckVariableDescription, CheckOutputDescription,
+		CheckRequirementTrace, CheckPropertyLinkage, CheckSensitiveLeak)
+	report.AddInputs(GetModulesPath(), filepath.Join(GetProjectRoot(), GetLayout().Environments))
+
+	dirs, err := ModuleDirs()
+	if err != nil {
+		return err
+	}
+	envs, err := ListAllEnvironments()
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		dirs = append(dirs, GetEnvironmentPath(env))
+	}
+
+	for _, dir := range dirs {
+		mod, err := LoadModule(dir)
+		if err != nil {
+			report.Add(&Finding{CheckID: CheckModuleParse, Severity: SeverityError, File: dir, Message: err.Error()})
+			continue
+		}
+		for _, v := range mod.Variables {
+			if strings.TrimSpace(v.Description) == "" {
+				report.Add(&Finding{
+					CheckID: CheckVariableDescription, Severity: SeverityError,
+					File: v.Range.Filename, Line: v.Range.Start.Line,
+					Message: fmt.Sprintf("variável %s sem description", v.Name),
+				})
+			}
+		}
+		for _, o := range mod.Outputs {
+			if strings.TrimSpace(o.Description) == "" {
+				report.Add(&Finding{
+					CheckID: CheckOutputDescription, Severity: SeverityError,
+					File: o.Range.Filename, Line: o.Range.Start.Line,
+					Message: fmt.Sprintf("output %s sem description", o.Name),
+				})
+			}
+		}
+	}
+
+	if err := addSensitiveFindings(report, dirs); err != nil {
+		return err
+	}
+
+	if err := addTraceabilityFindings(report); err != nil {
+		return err
+	}
+
+	properties, err := ParseDesignProperties(GetDesignPath())
+	if err != nil {
+		return err
+	}
+	tests, err := PropertyTests(GetTestPath("property"))
+	if err != nil {
+		return err
+	}
+	report.AddPropertyLinkage(CheckPropertyLinkage, LinkProperties(properties, tests))
+	return nil
+}
+
+// addTraceabilityFindings reporta citações a critérios inexistentes e critérios
+// obrigatórios sem testes
+func addTraceabilityFindings(report *Report) error {
+	criteria, err := ParseRequirements(GetRequirementsPath())
+	if err != nil {
+		return err
+	}
+	refs, err := TestReferences(GetTestDirs()...)
+	if err != nil {
+		return err
+	}
+	config, err := LoadTraceabilityConfig(GetTraceabilityConfigPath())
+	if err != nil {
+		return err
+	}
+
+	matrix := BuildTraceabilityMatrix(criteria, refs, nil)
+	for _, unknown := range matrix.Unknown {
+		report.Add(&Finding{
+			CheckID: CheckRequirementTrace, Severity: SeverityError,
+			File: unknown.Test.File, Line: unknown.Test.Line,
+			Message: fmt.Sprintf("%s cita o critério %s, inexistente em requirements.md", unknown.Test.Name, unknown.Criterion),
+		})
+	}
+	for _, criterion := range matrix.MissingRequired(config.Required) {
+		report.Add(&Finding{
+			CheckID: CheckRequirementTrace, Severity: SeverityError,
+			File: GetRequirementsPath(), Line: criterion.Line,
+			Message: fmt.Sprintf("critério obrigatório %s (%s) sem testes", criterion.ID, criterion.Title),
+		})
+	}
+	return nil
+}
+
+// RunEnvironmentChecks avalia um ambiente e verifica variáveis, endpoint do
+// cluster, values dos charts, policies IAM dos controllers, escopo do
+// external-dns, ClusterIssuers, schedules do Velero, postura dos buckets S3,
+// agendamento dos add-ons e o caminho de upgrade do Kubernetes
+func RunEnvironmentChecks(report *Report, env string) error {
+	report.addChecks(CheckVariableValidation, CheckEKSPublicEndpoint, CheckHelmValuesSchema,
+		CheckAddonScheduling, CheckKubernetesAPIRemovals, CheckUpgradePlan, CheckAlertRules,
+		CheckIAMPolicyDrift, CheckExternalDNSScope, CheckClusterIssuer,
+		CheckBackupSchedule, CheckBackupRetention, CheckBackupNamespaces, CheckS3BucketPosture)
+	report.AddInputs(GetEnvironmentPath(env), GetModulesPath())
+
+	ev, err := NewEnvironmentEvaluator(env)
+	if err != nil {
+		return err
+	}
+	return checkEnvironment(report, env, ev)
+}
+
+// RunEnvironmentConfigChecks executa as verificações de ambiente e de políticas
+// sobre o ambiente env com uma configuração gerada (ver GenEnvironmentConfig) e
+// retorna as instâncias expandidas
+func RunEnvironmentConfigChecks(report *Report, env string, config *EnvironmentConfig) ([]*ResourceInstance, error) {
+	report.addChecks(CheckVariableValidation, CheckEKSPublicEndpoint, CheckHelmValuesSchema,
+		CheckAddonScheduling, CheckKubernetesAPIRemovals, CheckUpgradePlan, CheckAlertRules,
+		CheckIAMPolicyDrift, CheckExternalDNSScope, CheckClusterIssuer,
+		CheckBackupSchedule, CheckBackupRetention, CheckBackupNamespaces, CheckS3BucketPosture,
+		CheckPolicyRequired, CheckPolicyEnforcementMode)
+	report.AddInputs(GetEnvironmentPath(env), GetModulesPath())
+
+	ev, err := config.Evaluator(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEnvironment(report, env, ev); err != nil {
+		return nil, err
+	}
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, err
+	}
+	checkPolicies(report, env, instances)
+	return instances, nil
+}
+
+// checkEnvironment executa as verificações de RunEnvironmentChecks sobre o avaliador do ambiente
+func checkEnvironment(report *Report, env string, ev *Evaluator) error {
+	if err := addValidationFindings(report, ev); err != nil {
+		return err
+	}
+
+	instances, err := ev.Expand()
+	if err != nil {
+		return err
+	}
+	for _, inst := range instances {
+		if inst.Resource.Type != "aws_eks_cluster" {
+			continue
+		}
+		for _, cidr := range openIngressCIDRs(inst.Resource.Type, inst.Values()) {
+			file, line := environmentInputLocation(env, "cluster_endpoint_public_access_cidrs", inst)
+			report.Add(&Finding{
+				CheckID: CheckEKSPublicEndpoint, Severity: SeverityError, File: file, Line: line,
+				Message: fmt.Sprintf("%s: %s tem endpoint público aberto para %s", env, inst.Address(), cidr),
+			})
+		}
+	}
+
+	releases, err := HelmReleases(instances)
+	if err != nil {
+		return err
+	}
+
+	for _, release := range releases {
+		schema, err := LoadChartSchema(release.Chart, release.Version)
+		if err != nil {
+			report.Add(&Finding{
+				CheckID: CheckHelmValuesSchema, Severity: SeverityWarning, File: release.File, Line: release.Line,
+				Message: fmt.Sprintf("%s: schema de %s %s não vendorizado em test/testdata/charts", release.Address, release.Chart, release.Version),
+			})
+			continue
+		}
+		for _, violation := range schema.Validate(release.Values) {
+			report.Add(&Finding{
+				CheckID: CheckHelmValuesSchema, Severity: SeverityError, File: release.File, Line: release.Line,
+				Message: fmt.Sprintf("%s (%s %s): %v", release.Address, release.Chart, release.Version, violation),
+			})
+		}
+	}
+
+	rules, err := AlertRules(instances)
+	if err != nil {
+		return err
+	}
+	for _, err := range ValidateAlertRules(rules, releases) {
+		report.Add(&Finding{
+			CheckID: CheckAlertRules, Severity: SeverityError, File: err.File, Line: err.Line,
+			Message: fmt.Sprintf("%s: %s", env, err.Message),
+		})
+	}
+
+	comparisons, err := CompareChartIAMPolicies(instances)
+	if err != nil {
+		return err
+	}
+	for _, comparison := range comparisons {
+		if comparison.ReferenceError != nil {
+			report.Add(&Finding{
+				CheckID: CheckIAMPolicyDrift, Severity: SeverityError, File: comparison.File, Line: comparison.Line,
+				Message: fmt.Sprintf("%s: %v", comparison.Release, comparison.ReferenceError),
+			})
+			continue
+		}
+		drift := comparison.Drift
+		for _, kind := range []struct {
+			label  string
+			grants []*IAMGrant
+		}{{"ausente", drift.Missing}, {"adicionada", drift.Added}, {"afrouxada", drift.Loosened}} {
+			for _, grant := range kind.grants {
+				report.Add(&Finding{
+					CheckID: CheckIAMPolicyDrift, Severity: SeverityError, File: comparison.File, Line: comparison.Line,
+					Message: fmt.Sprintf("%s: permissão %s em relação a %s %s: %s", comparison.Document, kind.label, comparison.Chart, comparison.Version, grant),
+				})
+			}
+		}
+	}
+
+	scopes, err := ExternalDNSScopes(env, ev)
+	if err != nil {
+		return err
+	}
+	addExternalDNSScopeFindings(report, scopes)
+
+	issuers, err := ClusterIssuers(env, ev)
+	if err != nil {
+		return err
+	}
+	for _, issuer := range issuers {
+		for _, problem := range issuer.Problems() {
+			report.Add(&Finding{
+				CheckID: CheckClusterIssuer, Severity: SeverityError, File: issuer.File, Line: issuer.Line,
+				Message: fmt.Sprintf("%s: %s: %s", env, issuer.Address, problem),
+			})
+		}
+	}
+
+	schedules, err := BackupSchedules(env, ev)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		for _, kind := range []struct {
+			check    string
+			problems []string
+		}{
+			{CheckBackupSchedule, schedule.RPOProblems()},
+			{CheckBackupRetention, schedule.RetentionProblems()},
+			{CheckBackupNamespaces, schedule.NamespaceProblems()},
+		} {
+			for _, problem := range kind.problems {
+				report.Add(&Finding{
+					CheckID: kind.check, Severity: SeverityError, File: schedule.File, Line: schedule.Line,
+					Message: fmt.Sprintf("%s: %s (schedule %s): %s", env, schedule.Release, schedule.Name, problem),
+				})
+			}
+		}
+	}
+
+	bucketConfig, err := LoadBucketPostureConfig(GetBucketPostureConfigPath())
+	if err != nil {
+		return err
+	}
+	buckets, err := BucketPostures(env, instances, bucketConfig)
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		for _, problem := range bucket.Problems() {
+			report.Add(&Finding{
+				CheckID: CheckS3BucketPosture, Severity: SeverityError, File: bucket.File, Line: bucket.Line,
+				Message: fmt.Sprintf("%s: %s: %s", env, bucket.Address, problem),
+			})
+		}
+	}
+
+	groups := NodeGroupsFromInstances(instances)
+	for _, release := range releases {
+		placements, err := HelmPlacements(release)
+		if err != nil {
+			return err
+		}
+		for _, placement := range placements {
+			eligible := EligibleNodeGroups(placement, groups)
+			if len(eligible) == 0 {
+				report.Add(&Finding{
+					CheckID: CheckAddonScheduling, Severity: SeverityError, File: release.File, Line: release.Line,
+					Message: fmt.Sprintf("%s não pode ser agendado em nenhum node group", placement.Name()),
+				})
+				continue
+			}
+			if placement.DaemonSet {
+				continue
+			}
+			for _, group := range eligible {
+				if group.Labels["role"] != "system" {
+					report.Add(&Finding{
+						CheckID: CheckAddonScheduling, Severity: SeverityError, File: release.File, Line: release.Line,
+						Message: fmt.Sprintf("%s pode ser agendado no node group %s", placement.Name(), group.Name),
+					})
+				}
+			}
+		}
+	}
+
+	manifests, err := RepositoryManifests()
+	if err != nil {
+		return err
+	}
+	current := ev.Var("cluster_version")
+	if !isLiteralString(current) {
+		return fmt.Errorf("%s: cluster_version não definido", env)
+	}
+	removals, err := FindAPIRemovals(manifests, current.AsString(), MaxSupportedKubernetesVersion)
+	if err != nil {
+		return err
+	}
+	report.AddAPIFindings(CheckKubernetesAPIRemovals, removals)
+	for _, release := range releases {
+		report.AddAPIFindings(CheckKubernetesAPIRemovals, FindCRDRemovals(manifests, release.Chart, release.Version, release.Version))
+	}
+
+	plan, err := PlanUpgrade(ev, MaxSupportedKubernetesVersion, manifests)
+	if err != nil {
+		return err
+	}
+	for _, item := range plan.Blocking() {
+		severity := SeverityNote
+		if !item.Resolvable {
+			severity = SeverityError
+		}
+		report.Add(&Finding{
+			CheckID: CheckUpgradePlan, Severity: severity, File: item.File, Line: item.Line,
+			Message: fmt.Sprintf("%s: upgrade para %s: %s: %s", env, item.Version, item.Target, item.Action),
+		})
+	}
+	return nil
+}
+
+// addSensitiveFindings segue os valores sensíveis a partir de cada módulo e
+// ambiente; um vazamento alcançado por várias raízes é reportado uma vez
+func addSensitiveFindings(report *Report, dirs []string) error {
+	config, err := LoadSensitiveConfig(GetSensitiveConfigPath())
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		leaks, err := FindSensitiveLeaks(dir, config)
+		if err != nil {
+			// Erros de parse já são reportados por module-parse
+			continue
+		}
+		for _, leak := range leaks {
+			if seen[leak.String()] {
+				continue
+			}
+			seen[leak.String()] = true
+			report.Add(&Finding{
+				CheckID: CheckSensitiveLeak, Severity: SeverityError,
+				File: leak.File, Line: leak.Line, Message: leak.Message,
+			})
+		}
+	}
+	return nil
+}
+
+// environmentInputLocation localiza a atribuição de uma variável no
+// terraform.tfvars.example do ambiente, onde o valor é escolhido e onde cabe a
+// supressão inline; sem ela, retorna a posição do recurso, se informado
+func environmentInputLocation(env, variable string, inst *ResourceInstance) (string, int) {
+	path := filepath.Join(GetEnvironmentPath(env), "terraform.tfvars.example")
+	if content, err := os.ReadFile(path); err == nil {
+		file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+		if !diags.HasErrors() {
+			if attr, ok := file.Body.(*hclsyntax.Body).Attributes[variable]; ok {
+				return path, attr.SrcRange.Start.Line
+			}
+		}
+	}
+	if inst == nil {
+		return path, 0
+	}
+	return inst.Resource.Range.Filename, inst.Resource.Range.Start.Line
+}
+
+// addValidationFindings avalia os blocos validation do ambiente e dos módulos chamados
+func addValidationFindings(report *Report, ev *Evaluator) error {
+	for _, err := range ev.ValidateVariables() {
+		report.Add(&Finding{
+			CheckID: CheckVariableValidation, Severity: SeverityError,
+			Message: fmt.Sprintf("%s: %v", ev.describe(), err),
+		})
+	}
+
+	names := make([]string, 0, len(ev.Module.ModuleCalls))
+	for name := range ev.Module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child, err := ev.Child(name)
+		if err != nil {
+			return err
+		}
+		if err := addValidationFindings(report, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requiredPolicy associa um critério de segurança às políticas que o implementam
+type requiredPolicy struct {
+	Requirement string
+	Description string
+	// Kyverno é o nome da ClusterPolicy e Gatekeeper o kind da Constraint
+	Kyverno    string
+	Gatekeeper string
+}
+
+var requiredPolicies = []requiredPolicy{
+	{"8.2", "bloquear containers privilegiados", "disallow-privileged-containers", "K8sPSPPrivilegedContainer"},
+	{"8.3", "exigir runAsNonRoot", "require-run-as-non-root", "K8sPSPAllowedUsers"},
+	{"8.4", "exigir resource requests e limits", "require-resources", "K8sRequiredResources"},
+	{"8.5", "bloquear tag latest", "disallow-latest-tag", "K8sDisallowLatestTag"},
+}
+
+// expectedEnforcement é o modo esperado das políticas em cada ambiente (8.6, 8.7),
+// indexado pelo nome do ambiente sem a nuvem
+var expectedEnforcement = map[string]string{
+	"staging": "audit",
+	"prod":    "enforce",
+}
+
+// policyEnforcement traduz as ações de Kyverno e Gatekeeper para audit ou enforce
+var policyEnforcement = map[string]string{
+	"Audit":   "audit",
+	"Enforce": "enforce",
+	"dryrun":  "audit",
+	"warn":    "audit",
+	"deny":    "enforce",
+}
+
+// RunPolicyChecks verifica, em cada ambiente, as políticas de segurança
+// obrigatórias e o modo de enforcement
+func RunPolicyChecks(report *Report, envs ...string) error {
+	report.addChecks(CheckPolicyRequired, CheckPolicyEnforcementMode)
+	report.AddInputs(GetModulesPath())
+
+	for _, env := range envs {
+		report.AddInputs(GetEnvironmentPath(env))
+		ev, err := NewEnvironmentEvaluator(env)
+		if err != nil {
+			return err
+		}
+		instances, err := ev.Expand()
+		if err != nil {
+			return err
+		}
+		checkPolicies(report, env, instances)
+	}
+	return nil
+}
+
+// RunExternalDNSChecks verifica, em cada ambiente, se o external-dns atua apenas
+// na zona Route53 do ambiente e se o txtOwnerId não se repete entre ambientes
+func RunExternalDNSChecks(report *Report, envs ...string) ([]*ExternalDNSScope, error) {
+	report.addChecks(CheckExternalDNSScope, CheckExternalDNSOwner)
+	report.AddInputs(GetModulesPath())
+
+	var all []*ExternalDNSScope
+	for _, env := range envs {
+		report.AddInputs(GetEnvironmentPath(env))
+		ev, err := NewEnvironmentEvaluator(env)
+		if err != nil {
+			return nil, err
+		}
+		scopes, err := ExternalDNSScopes(env, ev)
+		if err != nil {
+			return nil, err
+		}
+		addExternalDNSScopeFindings(report, scopes)
+		all = append(all, scopes...)
+	}
+
+	conflicts := ExternalDNSOwnerConflicts(all)
+	owners := make([]string, 0, len(conflicts))
+	for owner := range conflicts {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	for _, owner := range owners {
+		envs := make([]string, len(conflicts[owner]))
+		for i, scope := range conflicts[owner] {
+			envs[i] = scope.Env
+		}
+		for _, scope := range conflicts[owner] {
+			report.Add(&Finding{
+				CheckID: CheckExternalDNSOwner, Severity: SeverityError, File: scope.File, Line: scope.Line,
+				Message: fmt.Sprintf("%s: %s usa txtOwnerId %q, o mesmo de %s", scope.Env, scope.Release, owner, strings.Join(envs, ", ")),
+			})
+		}
+	}
+	return all, nil
+}
+
+// addExternalDNSScopeFindings registra os problemas de escopo de cada external-dns
+func addExternalDNSScopeFindings(report *Report, scopes []*ExternalDNSScope) {
+	for _, scope := range scopes {
+		for _, problem := range scope.Problems() {
+			report.Add(&Finding{
+				CheckID: CheckExternalDNSScope, Severity: SeverityError, File: scope.File, Line: scope.Line,
+				Message: fmt.Sprintf("%s: %s: %s", scope.Env, scope.Release, problem),
+			})
+		}
+	}
+}
+
+// checkPolicies verifica as políticas obrigatórias e o modo de enforcement nas
+// instâncias expandidas do ambiente
+func checkPolicies(report *Report, env string, instances []*ResourceInstance) {
+	for _, policy := range requiredPolicies {
+		var found *ResourceInstance
+		action := ""
+		for _, inst := range instances {
+			if inst.Resource.Type != "kubernetes_manifest" {
+				continue
+			}
+			manifest, _ := inst.Values()["manifest"].(map[string]interface{})
+			kind, _ := manifest["kind"].(string)
+			metadata, _ := manifest["metadata"].(map[string]interface{})
+			spec, _ := manifest["spec"].(map[string]interface{})
+			name, _ := metadata["name"].(string)
+
+			switch {
+			case kind == "ClusterPolicy" && name == policy.Kyverno:
+				action, _ = spec["validationFailureAction"].(string)
+			case kind == policy.Gatekeeper:
+				action, _ = spec["enforcementAction"].(string)
+			default:
+				continue
+			}
+			found = inst
+			break
+		}
+
+		if found == nil {
+			report.Add(&Finding{
+				CheckID: CheckPolicyRequired, Severity: SeverityError, Requirement: policy.Requirement,
+				File:    filepath.Join(GetEnvironmentPath(env), "main.tf"),
+				Message: fmt.Sprintf("%s: nenhuma política para %s", env, policy.Description),
+			})
+			continue
+		}
+
+		expected, ok := expectedEnforcement[path.Base(env)]
+		if !ok {
+			continue
+		}
+		if mode := policyEnforcement[action]; mode != expected {
+			report.Add(&Finding{
+				CheckID: CheckPolicyEnforcementMode, Severity: SeverityError,
+				File: found.Resource.Range.Filename, Line: found.Resource.Range.Start.Line,
+				Message: fmt.Sprintf("%s: %s usa %q; esperado modo %s", env, found.Address(), action, expected),
+			})
+		}
+	}
+}
+
+// protectedResourceTypes são recursos cuja destruição causa perda de dados ou do cluster
+var protectedResourceTypes = map[string]bool{
+	"aws_eks_cluster":        true,
+	"aws_kms_key":            true,
+	"aws_s3_bucket":          true,
+	"aws_vpc":                true,
+	"aws_cloudtrail":         true,
+	"aws_guardduty_detector": true,
+}
+
+// requiredTags são as tags de custo obrigatórias em todos os recursos AWS (17.1)
+var requiredTags = []string{"Environment", "ManagedBy", "Project"}
+
+// RunPlanChecks verifica um plano gerado por "terraform show -json": destruição de
+// recursos críticos, tags obrigatórias e acesso aberto a 0.0.0.0/0
+func RunPlanChecks(report *Report, path string) error {
+	report.addChecks(CheckPlanDestructiveChange, CheckPlanRequiredTags, CheckPlanOpenIngress)
+	report.AddInputs(path)
+
+	plan, err := LoadTerraformPlan(path)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range plan.ResourceChanges {
+		if change.Mode != "managed" {
+			continue
+		}
+
+		if change.Deletes() && protectedResourceTypes[change.Type] {
+			action := "destrói"
+			if change.Replaces() {
+				action = "substitui"
+			}
+			report.Add(&Finding{
+				CheckID: CheckPlanDestructiveChange, Severity: SeverityError, File: path,
+				Message: fmt.Sprintf("plano %s %s", action, change.Address),
+			})
+		}
+
+		if !change.Writes() || change.Change.After == nil {
+			continue
+		}
+		after := change.Change.After
+
+		if _, taggable := after["tags_all"]; taggable && change.Change.AfterUnknown["tags_all"] == nil {
+			tags, _ := after["tags_all"].(map[string]interface{})
+			var missing []string
+			for _, key := range requiredTags {
+				if _, ok := tags[key]; !ok {
+					missing = append(missing, key)
+				}
+			}
+			if len(missing) > 0 {
+				report.Add(&Finding{
+					CheckID: CheckPlanRequiredTags, Severity: SeverityError, File: path,
+					Message: fmt.Sprintf("%s sem as tags %s", change.Address, strings.Join(missing, ", ")),
+				})
+			}
+		}
+
+		for _, cidr := range openIngressCIDRs(change.Type, after) {
+			report.Add(&Finding{
+				CheckID: CheckPlanOpenIngress, Severity: SeverityWarning, File: path,
+				Message: fmt.Sprintf("%s permite acesso de %s", change.Address, cidr),
+			})
+		}
+	}
+	return nil
+}
+
+// openIngressCIDRs retorna os CIDRs abertos para a internet em regras de ingress
+// e no endpoint público do EKS, a partir dos atributos do recurso
+func openIngressCIDRs(resourceType string, after map[string]interface{}) []string {
+	var cidrs []interface{}
+	switch resourceType {
+	case "aws_security_group_rule":
+		if after["type"] != "ingress" {
+			return nil
+		}
+		cidrs = append(cidrs, listValue(after["cidr_blocks"])...)
+		cidrs = append(cidrs, listValue(after["ipv6_cidr_blocks"])...)
+	case "aws_vpc_security_group_ingress_rule":
+		cidrs = append(cidrs, after["cidr_ipv4"], after["cidr_ipv6"])
+	case "aws_security_group":
+		for _, rule := range listValue(after["ingress"]) {
+			rule, _ := rule.(map[string]interface{})
+			cidrs = append(cidrs, listValue(rule["cidr_blocks"])...)
+			cidrs = append(cidrs, listValue(rule["ipv6_cidr_blocks"])...)
+		}
+	case "aws_eks_cluster":
+		for _, config := range listValue(after["vpc_config"]) {
+			config, _ := config.(map[string]interface{})
+			if public, _ := config["endpoint_public_access"].(bool); public {
+				cidrs = append(cidrs, listValue(config["public_access_cidrs"])...)
+			}
+		}
+	}
+
+	var open []string
+	for _, cidr := range cidrs {
+		if cidr == "0.0.0.0/0" || cidr == "::/0" {
+			open = append(open, cidr.(string))
+		}
+	}
+	return open
+}
+
+// listValue converte um valor JSON em lista, tratando ausência como lista vazia
+func listValue(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
+
+// RunCostChecks estima o custo mensal dos ambientes, reporta os totais como notas
+// e como erro os ambientes acima do orçamento da configuração (ver GetCostConfigPath)
+func RunCostChecks(report *Report, path string, envs ...string) ([]*CostEstimate, error) {
+	report.addChecks(CheckCostBudget)
+	report.AddInputs(path)
+
+	config, err := LoadCostConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var estimates []*CostEstimate
+	for _, env := range envs {
+		report.AddInputs(GetEnvironmentPath(env))
+		estimate, err := EstimateEnvironmentCost(env, config)
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, estimate)
+
+		total := estimate.Total()
+		budget := config.Environment(env)
+		report.Add(&Finding{
+			CheckID: CheckCostBudget, Severity: SeverityNote, File: path,
+			Message: fmt.Sprintf("%s: custo mensal estimado %s (mínimo %s, máximo %s), orçamento %s",
+				env, formatMoney(total.Expected), formatMoney(total.Min), formatMoney(total.Max), formatMoney(budget.Budget)),
+		})
+		if total.Expected > budget.Budget {
+			report.Add(&Finding{
+				CheckID: CheckCostBudget, Severity: SeverityError, File: path,
+				Message: fmt.Sprintf("%s: custo esperado %s excede o orçamento de %s", env, formatMoney(total.Expected), formatMoney(budget.Budget)),
+			})
+		}
+		if budget.MaxBudget > 0 && total.Max > budget.MaxBudget {
+			report.Add(&Finding{
+				CheckID: CheckCostBudget, Severity: SeverityError, File: path,
+				Message: fmt.Sprintf("%s: custo máximo %s excede o max_budget de %s", env, formatMoney(total.Max), formatMoney(budget.MaxBudget)),
+			})
+		}
+	}
+	return estimates, nil
+}
+
+// RunCapacityChecks calcula a capacidade dos node groups de cada ambiente e
+// verifica se os requests dos add-ons e dos pods do EKS cabem no node group
+// system com min_size nodes
+func RunCapacityChecks(report *Report, path string, envs ...string) ([]*EnvironmentCapacity, error) {
+	report.addChecks(CheckNodeCapacity)
+	report.AddInputs(path)
+
+	table, err := LoadInstanceTable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var capacities []*EnvironmentCapacity
+	for _, env := range envs {
+		report.AddInputs(GetEnvironmentPath(env), GetModulesPath())
+		capacity, err := EstimateEnvironmentCapacity(env, table)
+		if err != nil {
+			return nil, err
+		}
+		capacities = append(capacities, capacity)
+
+		fit := capacity.System
+		file, line := environmentInputLocation(env, "node_groups", nil)
+		report.Add(&Finding{
+			CheckID: CheckNodeCapacity, Severity: SeverityNote, File: file, Line: line,
+			Message: fmt.Sprintf("%s: capacidade no mínimo %s, no máximo %s; node group %s com %d nodes: demanda %s de %s (%d workloads sem requests)",
+				env, capacity.Cluster.Min(), capacity.Cluster.Max(), fit.Group, fit.Nodes, fit.Demand, fit.Available, len(fit.Undeclared)),
+		})
+		for _, problem := range fit.Problems {
+			report.Add(&Finding{
+				CheckID: CheckNodeCapacity, Severity: SeverityError, File: file, Line: line,
+				Message: fmt.Sprintf("%s: %s", env, problem),
+			})
+		}
+	}
+	return capacities, nil
+}
+
+// storageVariables são os argumentos do módulo observability com o tamanho do volume de cada componente
+var storageVariables = map[string]string{
+	"Prometheus": "prometheus_storage_size",
+	"Loki":       "loki_storage_size",
+}
+
+// RunObservabilityChecks compara, em cada ambiente, a retenção renderizada nos
+// values do kube-prometheus-stack e do Loki com as variáveis do módulo e verifica
+// se os volumes comportam a janela de retenção com as premissas de ingestão
+func RunObservabilityChecks(report *Report, path string, envs ...string) ([]*EnvironmentObservability, error) {
+	report.addChecks(CheckObservabilityRetention, CheckObservabilityStorage)
+	report.AddInputs(path, GetModulesPath())
+
+	config, err := LoadObservabilityConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*EnvironmentObservability
+	for _, env := range envs {
+		report.AddInputs(GetEnvironmentPath(env))
+		ev, err := NewEnvironmentEvaluator(env)
+		if err != nil {
+			return nil, err
+		}
+		observability, err := EstimateObservability(ev, env, config)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, observability)
+
+		call := ev.Module.ModuleCalls["observability"]
+		for _, sizing := range []*StorageSizing{observability.Prometheus, observability.Loki} {
+			for _, problem := range sizing.RetentionProblems {
+				report.Add(&Finding{
+					CheckID: CheckObservabilityRetention, Severity: SeverityError, File: sizing.file, Line: sizing.line,
+					Message: fmt.Sprintf("%s: %s: %s", env, sizing.Release, problem),
+				})
+			}
+
+			file, line := call.Range.Filename, call.Range.Start.Line
+			if attr, ok := call.Body.Attributes[storageVariables[sizing.Component]]; ok {
+				line = attr.SrcRange.Start.Line
+			}
+			severity, verdict := SeverityNote, "comporta"
+			if !sizing.Fits() {
+				severity, verdict = SeverityError, "não comporta"
+			}
+			report.Add(&Finding{
+				CheckID: CheckObservabilityStorage, Severity: severity, File: file, Line: line,
+				Message: fmt.Sprintf("%s: %s com %s %s %d dias de retenção: %s retidos, %s necessários com %s",
+					env, sizing.Component, sizing.Volume, verdict, sizing.RetentionDays,
+					formatGiB(sizing.DataBytes), formatGiB(sizing.RequiredBytes), sizing.Rate),
+			})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,445 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultNodeInstanceType e defaultNodeDiskGB são os valores do EKS quando o
+// node group não define instance_types, launch template ou disk_size
+const (
+	defaultNodeInstanceType = "t3.medium"
+	defaultNodeDiskGB       = 20
+)
+
+// PriceTable é a tabela de preços versionada usada pela estimativa de custos
+type PriceTable struct {
+	Version       string  `yaml:"version"`
+	Region        string  `yaml:"region"`
+	Currency      string  `yaml:"currency"`
+	HoursPerMonth float64 `yaml:"hours_per_month"`
+	// Hourly, Monthly, PerGBMonth e PerGB são os preços por componente
+	// (ex: hourly.nat_gateway, per_gb_month.ebs_gp3)
+	Hourly     map[string]float64 `yaml:"hourly"`
+	Monthly    map[string]float64 `yaml:"monthly"`
+	PerGBMonth map[string]float64 `yaml:"per_gb_month"`
+	PerGB      map[string]float64 `yaml:"per_gb"`
+	// SpotFactor é a fração do preço on-demand cobrada por instâncias spot
+	SpotFactor float64 `yaml:"spot_factor"`
+	// Instances é o preço por hora de cada tipo de instância EC2
+	Instances map[string]float64 `yaml:"instances"`
+
+	path string
+}
+
+// LoadPriceTable lê uma tabela de preços em YAML
+func LoadPriceTable(path string) (*PriceTable, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prices := &PriceTable{path: path}
+	if err := yaml.Unmarshal(content, prices); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if prices.Version == "" || prices.HoursPerMonth <= 0 {
+		return nil, fmt.Errorf("%s: version e hours_per_month são obrigatórios", path)
+	}
+	return prices, nil
+}
+
+// price retorna um preço da tabela, com erro quando ausente para não subestimar o custo
+func (p *PriceTable) price(table map[string]float64, section, key string) (float64, error) {
+	value, ok := table[key]
+	if !ok {
+		return 0, fmt.Errorf("preço %s.%s ausente na tabela %s (versão %s)", section, key, p.path, p.Version)
+	}
+	return value, nil
+}
+
+func (p *PriceTable) monthlyHours(key string) (float64, error) {
+	hourly, err := p.price(p.Hourly, "hourly", key)
+	return hourly * p.HoursPerMonth, err
+}
+
+func (p *PriceTable) instanceMonthly(instanceType string) (float64, error) {
+	hourly, err := p.price(p.Instances, "instances", instanceType)
+	return hourly * p.HoursPerMonth, err
+}
+
+// CostRange é um custo ou uma quantidade mensal mínima, esperada e máxima
+type CostRange struct {
+	Min      float64 `yaml:"min" json:"min"`
+	Expected float64 `yaml:"expected" json:"expected"`
+	Max      float64 `yaml:"max" json:"max"`
+}
+
+// fixedCost retorna um custo que não varia com a escala
+func fixedCost(value float64) CostRange {
+	return CostRange{Min: value, Expected: value, Max: value}
+}
+
+// Add soma dois intervalos
+func (r CostRange) Add(other CostRange) CostRange {
+	return CostRange{Min: r.Min + other.Min, Expected: r.Expected + other.Expected, Max: r.Max + other.Max}
+}
+
+// Scale multiplica o intervalo por um fator
+func (r CostRange) Scale(factor float64) CostRange {
+	return CostRange{Min: r.Min * factor, Expected: r.Expected * factor, Max: r.Max * factor}
+}
+
+// CostUsage são as premissas de uso para componentes cobrados por volume
+type CostUsage struct {
+	// LogIngestionGB é o volume ingerido por mês em cada log group
+	LogIngestionGB CostRange `yaml:"log_ingestion_gb"`
+	// S3StorageGB é o volume armazenado em cada bucket
+	S3StorageGB CostRange `yaml:"s3_storage_gb"`
+}
+
+// EnvironmentCostConfig é o orçamento e as premissas de uso de um ambiente
+type EnvironmentCostConfig struct {
+	// Budget limita o custo mensal esperado
+	Budget float64 `yaml:"budget"`
+	// MaxBudget, opcional, limita o custo com todos os node groups no max_size
+	MaxBudget float64   `yaml:"max_budget"`
+	Usage     CostUsage `yaml:"usage"`
+}
+
+// CostConfig é a configuração da estimativa de custos (testdata/costs.yaml)
+type CostConfig struct {
+	// Prices é o caminho da tabela de preços, relativo ao arquivo de configuração
+	Prices       string                            `yaml:"prices"`
+	Environments map[string]*EnvironmentCostConfig `yaml:"environments"`
+
+	path string
+}
+
+// GetCostConfigPath retorna o caminho da configuração de orçamentos
+func GetCostConfigPath() string {
+	return GetTestPath("testdata", "costs.yaml")
+}
+
+// LoadCostConfig lê a configuração de orçamentos e premissas de uso
+func LoadCostConfig(path string) (*CostConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &CostConfig{path: path}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if config.Prices == "" {
+		return nil, fmt.Errorf("%s: prices é obrigatório", path)
+	}
+	return config, nil
+}
+
+// Environment retorna a configuração do ambiente, buscando também pelo nome sem
+// a nuvem (ex: "gcp/staging" usa "staging"), ou nil
+func (c *CostConfig) Environment(env string) *EnvironmentCostConfig {
+	if config, ok := c.Environments[env]; ok {
+		return config
+	}
+	return c.Environments[path.Base(env)]
+}
+
+// LoadPrices lê a tabela de preços referenciada pela configuração
+func (c *CostConfig) LoadPrices() (*PriceTable, error) {
+	prices := filepath.FromSlash(c.Prices)
+	if !filepath.IsAbs(prices) {
+		prices = filepath.Join(filepath.Dir(c.path), prices)
+	}
+	return LoadPriceTable(prices)
+}
+
+// CostItem é o custo mensal de um recurso
+type CostItem struct {
+	Address string `json:"address"`
+	// Component agrupa os itens na saída (ex: "NAT Gateway", "EC2")
+	Component string    `json:"component"`
+	Detail    string    `json:"detail"`
+	Monthly   CostRange `json:"monthly"`
+}
+
+// CostEstimate é a estimativa de custo mensal de um ambiente
+type CostEstimate struct {
+	Environment  string      `json:"environment"`
+	PriceVersion string      `json:"price_version"`
+	Currency     string      `json:"currency"`
+	Items        []*CostItem `json:"items"`
+}
+
+// Total soma os custos dos itens
+func (e *CostEstimate) Total() CostRange {
+	total := CostRange{}
+	for _, item := range e.Items {
+		total = total.Add(item.Monthly)
+	}
+	return total
+}
+
+// Markdown formata a estimativa como tabela, um recurso por linha
+func (e *CostEstimate) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Custo mensal estimado: %s\n\n", e.Environment)
+	fmt.Fprintf(&b, "Tabela de preços %s (%s).\n\n", e.PriceVersion, e.Currency)
+	b.WriteString("| Componente | Recurso | Detalhe | Mínimo | Esperado | Máximo |\n")
+	b.WriteString("|------------|---------|---------|-------:|---------:|-------:|\n")
+	for _, item := range e.Items {
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %s | %s |\n", item.Component, item.Address, item.Detail,
+			formatMoney(item.Monthly.Min), formatMoney(item.Monthly.Expected), formatMoney(item.Monthly.Max))
+	}
+	total := e.Total()
+	fmt.Fprintf(&b, "| **Total** | | | **%s** | **%s** | **%s** |\n",
+		formatMoney(total.Min), formatMoney(total.Expected), formatMoney(total.Max))
+	return b.String()
+}
+
+// formatMoney formata um valor com duas casas e separador de milhar
+func formatMoney(value float64) string {
+	s := fmt.Sprintf("%.2f", value)
+	integer, decimals := s[:len(s)-3], s[len(s)-3:]
+	sign := ""
+	if strings.HasPrefix(integer, "-") {
+		sign, integer = "-", integer[1:]
+	}
+	for i := len(integer) - 3; i > 0; i -= 3 {
+		integer = integer[:i] + "," + integer[i:]
+	}
+	return "$" + sign + integer + decimals
+}
+
+// EstimateEnvironmentCost avalia o ambiente e estima seu custo mensal
+func EstimateEnvironmentCost(env string, config *CostConfig) (*CostEstimate, error) {
+	envConfig := config.Environment(env)
+	if envConfig == nil {
+		return nil, fmt.Errorf("%s: ambiente %s sem orçamento e premissas de uso", config.path, env)
+	}
+	prices, err := config.LoadPrices()
+	if err != nil {
+		return nil, err
+	}
+	ev, err := NewEnvironmentEvaluator(env)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, err
+	}
+	estimate, err := EstimateCost(instances, prices, envConfig.Usage)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", env, err)
+	}
+	estimate.Environment = env
+	return estimate, nil
+}
+
+// EstimateCost calcula o custo mensal das instâncias expandidas: control plane do
+// EKS, NAT gateways, IPs públicos, endpoints de interface por AZ, node groups
+// (min_size, desired_size e max_size), discos dos nodes, logs do CloudWatch,
+// buckets S3 e chaves KMS. Recursos sem custo fixo são ignorados.
+func EstimateCost(instances []*ResourceInstance, prices *PriceTable, usage CostUsage) (*CostEstimate, error) {
+	estimate := &CostEstimate{PriceVersion: prices.Version, Currency: prices.Currency}
+	byAddress := make(map[string]*ResourceInstance, len(instances))
+	for _, inst := range instances {
+		byAddress[inst.Address()] = inst
+	}
+
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" {
+			continue
+		}
+		attrs := inst.Values()
+		var items []*CostItem
+		var err error
+
+		switch inst.Resource.Type {
+		case "aws_eks_cluster":
+			items, err = hourlyItem(prices, inst, "EKS control plane", "eks_cluster", 1, "1 cluster")
+		case "aws_nat_gateway":
+			items, err = hourlyItem(prices, inst, "NAT Gateway", "nat_gateway", 1, "1 gateway")
+		case "aws_eip":
+			items, err = hourlyItem(prices, inst, "IPv4 público", "eip", 1, "1 endereço")
+		case "aws_vpc_endpoint":
+			if attrs["vpc_endpoint_type"] != "Interface" {
+				continue
+			}
+			azs := len(listValue(attrs["subnet_ids"]))
+			items, err = hourlyItem(prices, inst, "VPC endpoint", "vpc_endpoint_interface", float64(azs), fmt.Sprintf("interface em %d AZs", azs))
+		case "aws_eks_node_group":
+			items, err = nodeGroupItems(prices, inst, attrs, byAddress)
+		case "aws_cloudwatch_log_group":
+			items, err = logGroupItems(prices, inst, attrs, usage.LogIngestionGB)
+		case "aws_s3_bucket":
+			var perGB float64
+			perGB, err = prices.price(prices.PerGBMonth, "per_gb_month", "s3_standard")
+			items = []*CostItem{{
+				Address: inst.Address(), Component: "S3",
+				Detail:  fmt.Sprintf("%s-%s GB", formatNumber(usage.S3StorageGB.Min), formatNumber(usage.S3StorageGB.Max)),
+				Monthly: usage.S3StorageGB.Scale(perGB),
+			}}
+		case "aws_kms_key":
+			var monthly float64
+			monthly, err = prices.price(prices.Monthly, "monthly", "kms_key")
+			items = []*CostItem{{Address: inst.Address(), Component: "KMS", Detail: "1 chave", Monthly: fixedCost(monthly)}}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", inst.Address(), err)
+		}
+		estimate.Items = append(estimate.Items, items...)
+	}
+
+	sort.SliceStable(estimate.Items, func(i, j int) bool {
+		a, b := estimate.Items[i], estimate.Items[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Address < b.Address
+	})
+	return estimate, nil
+}
+
+// hourlyItem retorna o custo de um componente cobrado por hora
+func hourlyItem(prices *PriceTable, inst *ResourceInstance, component, key string, quantity float64, detail string) ([]*CostItem, error) {
+	monthly, err := prices.monthlyHours(key)
+	if err != nil {
+		return nil, err
+	}
+	return []*CostItem{{Address: inst.Address(), Component: component, Detail: detail, Monthly: fixedCost(monthly * quantity)}}, nil
+}
+
+// nodeGroupItems retorna o custo das instâncias e dos discos de um node group:
+// o mínimo usa min_size e o tipo mais barato, o esperado desired_size e o
+// primeiro tipo, e o máximo max_size e o tipo mais caro
+func nodeGroupItems(prices *PriceTable, inst *ResourceInstance, attrs map[string]interface{}, byAddress map[string]*ResourceInstance) ([]*CostItem, error) {
+	scaling := CostRange{}
+	for _, config := range listValue(attrs["scaling_config"]) {
+		config, _ := config.(map[string]interface{})
+		scaling.Min, _ = toFloat(config["min_size"])
+		scaling.Expected, _ = toFloat(config["desired_size"])
+		scaling.Max, _ = toFloat(config["max_size"])
+	}
+
+	var types []string
+	for _, t := range listValue(attrs["instance_types"]) {
+		types = append(types, fmt.Sprint(t))
+	}
+	if len(types) == 0 {
+		types = []string{defaultNodeInstanceType}
+	}
+	perNode := CostRange{}
+	for i, instanceType := range types {
+		monthly, err := prices.instanceMonthly(instanceType)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			perNode = fixedCost(monthly)
+		}
+		if monthly < perNode.Min {
+			perNode.Min = monthly
+		}
+		if monthly > perNode.Max {
+			perNode.Max = monthly
+		}
+	}
+	capacity := "on-demand"
+	if attrs["capacity_type"] == "SPOT" {
+		capacity = "spot"
+		perNode = perNode.Scale(prices.SpotFactor)
+	}
+
+	compute := &CostItem{
+		Address: inst.Address(), Component: "EC2",
+		Detail: fmt.Sprintf("%s %s, min %s, desejado %s, máx %s nodes", strings.Join(types, "/"), capacity,
+			formatNumber(scaling.Min), formatNumber(scaling.Expected), formatNumber(scaling.Max)),
+		Monthly: CostRange{
+			Min:      scaling.Min * perNode.Min,
+			Expected: scaling.Expected * perNode.Expected,
+			Max:      scaling.Max * perNode.Max,
+		},
+	}
+
+	size, volumeType := nodeDisk(attrs, byAddress)
+	perGB, err := prices.price(prices.PerGBMonth, "per_gb_month", "ebs_"+volumeType)
+	if err != nil {
+		return nil, err
+	}
+	disk := &CostItem{
+		Address: inst.Address(), Component: "EBS",
+		Detail:  fmt.Sprintf("%s GB %s por node", formatNumber(size), volumeType),
+		Monthly: scaling.Scale(size * perGB),
+	}
+	return []*CostItem{compute, disk}, nil
+}
+
+// nodeDisk retorna o tamanho e o tipo do disco raiz dos nodes: o do launch
+// template referenciado pelo node group, o disk_size ou o padrão do EKS
+func nodeDisk(attrs map[string]interface{}, byAddress map[string]*ResourceInstance) (float64, string) {
+	for _, lt := range listValue(attrs["launch_template"]) {
+		lt, _ := lt.(map[string]interface{})
+		id, _ := lt["id"].(string)
+		address := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(id, "${"), "}"), ".id")
+		template, ok := byAddress[address]
+		if !ok {
+			continue
+		}
+		for _, mapping := range listValue(template.Values()["block_device_mappings"]) {
+			mapping, _ := mapping.(map[string]interface{})
+			for _, ebs := range listValue(mapping["ebs"]) {
+				ebs, _ := ebs.(map[string]interface{})
+				size, ok := toFloat(ebs["volume_size"])
+				if !ok {
+					continue
+				}
+				volumeType, _ := ebs["volume_type"].(string)
+				if volumeType == "" {
+					volumeType = "gp3"
+				}
+				return size, volumeType
+			}
+		}
+	}
+	if size, ok := toFloat(attrs["disk_size"]); ok {
+		return size, "gp2"
+	}
+	return defaultNodeDiskGB, "gp2"
+}
+
+// logGroupItems retorna o custo de ingestão e de armazenamento de um log group;
+// o armazenamento acumula retention_in_days de ingestão (12 meses sem retenção)
+func logGroupItems(prices *PriceTable, inst *ResourceInstance, attrs map[string]interface{}, ingestion CostRange) ([]*CostItem, error) {
+	ingest, err := prices.price(prices.PerGB, "per_gb", "cloudwatch_logs_ingestion")
+	if err != nil {
+		return nil, err
+	}
+	storage, err := prices.price(prices.PerGBMonth, "per_gb_month", "cloudwatch_logs_storage")
+	if err != nil {
+		return nil, err
+	}
+	retention, ok := toFloat(attrs["retention_in_days"])
+	if !ok || retention <= 0 {
+		retention = 365
+	}
+	stored := retention / 30
+	return []*CostItem{{
+		Address: inst.Address(), Component: "CloudWatch Logs",
+		Detail: fmt.Sprintf("%s-%s GB/mês, retenção de %s dias",
+			formatNumber(ingestion.Min), formatNumber(ingestion.Max), formatNumber(retention)),
+		Monthly: ingestion.Scale(ingest + stored*storage),
+	}}, nil
+}
+
+// formatNumber formata quantidades sem casas decimais desnecessárias
+func formatNumber(value float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
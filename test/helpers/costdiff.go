@@ -0,0 +1,589 @@
+package helpers
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// costEpsilon é a menor diferença mensal considerada na comparação
+const costEpsilon = 0.005
+
+// CostSource é um dos lados comparados por DiffCost: o diretório de um ambiente
+// na árvore atual ou em uma revisão extraída do git
+type CostSource struct {
+	// Label identifica o lado na saída (ex: "staging", "origin/main")
+	Label string
+	// Environment é o ambiente cujas premissas de uso são lidas do costs.yaml
+	Environment string
+	// Dir é o diretório do ambiente
+	Dir string
+}
+
+// EnvironmentCostSource retorna o ambiente na árvore atual
+func EnvironmentCostSource(env string) CostSource {
+	return CostSource{Label: env, Environment: env, Dir: GetEnvironmentPath(env)}
+}
+
+// RevisionCostSource extrai a raiz do projeto na revisão informada para um
+// diretório temporário e retorna o ambiente nessa revisão. A função retornada
+// remove o diretório temporário.
+func RevisionCostSource(rev, env string) (CostSource, func(), error) {
+	root := GetProjectRoot()
+	rel, err := filepath.Rel(root, GetEnvironmentPath(env))
+	if err != nil {
+		return CostSource{}, nil, err
+	}
+	return RepositoryCostSource(root, rev, env, rel)
+}
+
+// RepositoryCostSource extrai root na revisão informada e retorna o diretório
+// rel (relativo a root) nessa revisão como o ambiente env
+func RepositoryCostSource(root, rev, env, rel string) (CostSource, func(), error) {
+	dir, err := os.MkdirTemp("", "templatecheck-cost-")
+	if err != nil {
+		return CostSource{}, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	// "rev:./" limita o arquivo à raiz do projeto, que pode ser um subdiretório do repositório
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", root, "archive", "--format=tar", rev+":./")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return CostSource{}, nil, fmt.Errorf("git archive %s: %v: %s", rev, err, strings.TrimSpace(stderr.String()))
+	}
+	if err := extractTar(&stdout, dir); err != nil {
+		cleanup()
+		return CostSource{}, nil, fmt.Errorf("revisão %s: %w", rev, err)
+	}
+	return CostSource{Label: env + "@" + rev, Environment: env, Dir: filepath.Join(dir, rel)}, cleanup, nil
+}
+
+// extractTar grava os arquivos e diretórios do tar em dir
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("caminho inválido no arquivo: %s", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			err = writeTarFile(tr, target, header.FileInfo().Mode())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeTarFile(r io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CostAttribution explica parte da diferença de custo: a mudança de uma
+// variável, das premissas de uso ou o restante (código dos módulos e combinação
+// de variáveis)
+type CostAttribution struct {
+	// Variable é a variável de entrada alterada; vazio para as demais origens
+	Variable string   `json:"variable,omitempty"`
+	Source   string   `json:"source"`
+	Change   string   `json:"change"`
+	Effects  []string `json:"effects,omitempty"`
+	// Delta é a diferença mensal, positiva quando o custo de Head é maior
+	Delta CostRange `json:"delta"`
+}
+
+// CostDelta é a diferença de custo de um item (recurso e componente)
+type CostDelta struct {
+	Address    string    `json:"address"`
+	Component  string    `json:"component"`
+	Change     string    `json:"change"`
+	BaseDetail string    `json:"base_detail,omitempty"`
+	HeadDetail string    `json:"head_detail,omitempty"`
+	Base       CostRange `json:"base"`
+	Head       CostRange `json:"head"`
+	Delta      CostRange `json:"delta"`
+}
+
+// CostDiff compara as estimativas de custo de dois ambientes ou de duas revisões
+// do mesmo ambiente
+type CostDiff struct {
+	Base *CostEstimate `json:"base"`
+	Head *CostEstimate `json:"head"`
+	// Attributions atribui a diferença total às variáveis alteradas, às premissas
+	// de uso e ao restante; a soma dos deltas é a diferença total
+	Attributions []*CostAttribution `json:"attributions"`
+	// Unchanged são as variáveis alteradas sem efeito no custo
+	Unchanged []string     `json:"unchanged_cost_variables,omitempty"`
+	Resources []*CostDelta `json:"resources"`
+}
+
+// Delta retorna a diferença total (Head - Base)
+func (d *CostDiff) Delta() CostRange {
+	return subtractCost(d.Head.Total(), d.Base.Total())
+}
+
+func subtractCost(a, b CostRange) CostRange {
+	return a.Add(b.Scale(-1))
+}
+
+// costSide é um lado avaliado da comparação
+type costSide struct {
+	module    *Module
+	inputs    map[string]cty.Value
+	evaluator *Evaluator
+	usage     CostUsage
+	estimate  *CostEstimate
+}
+
+// DiffCost estima os dois lados com a mesma tabela de preços e atribui a
+// diferença. Cada variável alterada é medida isoladamente: o lado head é
+// estimado novamente com o valor da variável no lado base, e a diferença entre
+// as duas estimativas é o efeito da variável. O mesmo é feito com as premissas
+// de uso do costs.yaml.
+func DiffCost(base, head CostSource, config *CostConfig) (*CostDiff, error) {
+	prices, err := config.LoadPrices()
+	if err != nil {
+		return nil, err
+	}
+	baseSide, err := loadCostSide(base, config, prices)
+	if err != nil {
+		return nil, err
+	}
+	headSide, err := loadCostSide(head, config, prices)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &CostDiff{Base: baseSide.estimate, Head: headSide.estimate}
+	diff.Resources = diffCostItems(baseSide.estimate, headSide.estimate)
+	headTotal := headSide.estimate.Total()
+	attributed := CostRange{}
+
+	if baseSide.usage != headSide.usage {
+		instances, err := headSide.evaluator.Expand()
+		if err != nil {
+			return nil, err
+		}
+		alternative, err := EstimateCost(instances, prices, baseSide.usage)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", head.Label, err)
+		}
+		attribution := &CostAttribution{
+			Source:  "premissas de uso (costs.yaml)",
+			Change:  fmt.Sprintf("%s → %s", base.Environment, head.Environment),
+			Effects: componentEffects(alternative, headSide.estimate),
+			Delta:   subtractCost(headTotal, alternative.Total()),
+		}
+		diff.Attributions = append(diff.Attributions, attribution)
+		attributed = attributed.Add(attribution.Delta)
+	}
+
+	for _, name := range sortedVariableNames(headSide.module) {
+		if _, ok := baseSide.module.Variables[name]; !ok {
+			continue
+		}
+		before, after := baseSide.evaluator.Var(name), headSide.evaluator.Var(name)
+		if before.RawEquals(after) {
+			continue
+		}
+
+		inputs := make(map[string]cty.Value, len(headSide.inputs)+1)
+		for k, v := range headSide.inputs {
+			inputs[k] = v
+		}
+		inputs[name] = before
+		alternative, err := estimateCostSide(headSide.module, inputs, prices, headSide.usage)
+		if err != nil {
+			return nil, fmt.Errorf("%s com %s de %s: %w", head.Label, name, base.Label, err)
+		}
+
+		delta := subtractCost(headTotal, alternative.Total())
+		effects := componentEffects(alternative, headSide.estimate)
+		if len(effects) == 0 && !costChanged(delta) {
+			diff.Unchanged = append(diff.Unchanged, name)
+			continue
+		}
+		attribution := &CostAttribution{
+			Variable: name,
+			Source:   "variável",
+			Change:   describeValueChange(before, after, headSide.module.Variables[name].Sensitive),
+			Effects:  effects,
+			Delta:    delta,
+		}
+		diff.Attributions = append(diff.Attributions, attribution)
+		attributed = attributed.Add(delta)
+	}
+
+	// O restante inclui mudanças no código dos módulos, variáveis novas e o
+	// efeito combinado de variáveis que dependem umas das outras
+	if rest := subtractCost(diff.Delta(), attributed); costChanged(rest) {
+		diff.Attributions = append(diff.Attributions, &CostAttribution{
+			Source: "outras mudanças",
+			Change: "código dos módulos, variáveis novas e combinação de variáveis",
+			Delta:  rest,
+		})
+	}
+	return diff, nil
+}
+
+// loadCostSide avalia e estima um lado da comparação
+func loadCostSide(source CostSource, config *CostConfig, prices *PriceTable) (*costSide, error) {
+	envConfig := config.Environment(source.Environment)
+	if envConfig == nil {
+		return nil, fmt.Errorf("%s: ambiente %s sem orçamento e premissas de uso", config.path, source.Environment)
+	}
+	mod, inputs, err := loadEnvironment(source.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source.Label, err)
+	}
+	ev, err := NewEvaluator(mod, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source.Label, err)
+	}
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source.Label, err)
+	}
+	estimate, err := EstimateCost(instances, prices, envConfig.Usage)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source.Label, err)
+	}
+	estimate.Environment = source.Label
+	return &costSide{module: mod, inputs: inputs, evaluator: ev, usage: envConfig.Usage, estimate: estimate}, nil
+}
+
+// estimateCostSide estima o módulo com outras entradas
+func estimateCostSide(mod *Module, inputs map[string]cty.Value, prices *PriceTable, usage CostUsage) (*CostEstimate, error) {
+	ev, err := NewEvaluator(mod, inputs)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, err
+	}
+	return EstimateCost(instances, prices, usage)
+}
+
+func sortedVariableNames(mod *Module) []string {
+	names := make([]string, 0, len(mod.Variables))
+	for name := range mod.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// costChanged indica se algum valor da faixa é diferente de zero
+func costChanged(r CostRange) bool {
+	return math.Abs(r.Min) >= costEpsilon || math.Abs(r.Expected) >= costEpsilon || math.Abs(r.Max) >= costEpsilon
+}
+
+// diffCostItems compara os itens pelo endereço e componente, ordenados pela
+// maior diferença no custo esperado
+func diffCostItems(base, head *CostEstimate) []*CostDelta {
+	type key struct{ address, component string }
+	baseItems := make(map[key]*CostItem, len(base.Items))
+	for _, item := range base.Items {
+		baseItems[key{item.Address, item.Component}] = item
+	}
+
+	var deltas []*CostDelta
+	seen := make(map[key]bool)
+	for _, item := range head.Items {
+		k := key{item.Address, item.Component}
+		seen[k] = true
+		delta := &CostDelta{Address: item.Address, Component: item.Component, Change: "adicionado", HeadDetail: item.Detail, Head: item.Monthly}
+		if before, ok := baseItems[k]; ok {
+			delta.Change, delta.BaseDetail, delta.Base = "alterado", before.Detail, before.Monthly
+		}
+		delta.Delta = subtractCost(delta.Head, delta.Base)
+		if delta.Change == "adicionado" || costChanged(delta.Delta) {
+			deltas = append(deltas, delta)
+		}
+	}
+	for _, item := range base.Items {
+		k := key{item.Address, item.Component}
+		if !seen[k] {
+			deltas = append(deltas, &CostDelta{Address: item.Address, Component: item.Component, Change: "removido",
+				BaseDetail: item.Detail, Base: item.Monthly, Delta: item.Monthly.Scale(-1)})
+		}
+	}
+
+	sort.SliceStable(deltas, func(i, j int) bool {
+		a, b := math.Abs(deltas[i].Delta.Expected), math.Abs(deltas[j].Delta.Expected)
+		if a != b {
+			return a > b
+		}
+		return deltas[i].Address < deltas[j].Address
+	})
+	return deltas
+}
+
+// componentEffects resume por componente a diferença entre duas estimativas:
+// a mudança na quantidade de itens (ex: "+2 NAT Gateway (1 → 3)") ou, com a
+// mesma quantidade, a diferença no custo esperado (ex: "EC2 +$1,200.00")
+func componentEffects(before, after *CostEstimate) []string {
+	type summary struct {
+		count    int
+		expected float64
+	}
+	summarize := func(estimate *CostEstimate) map[string]*summary {
+		byComponent := make(map[string]*summary)
+		for _, item := range estimate.Items {
+			s := byComponent[item.Component]
+			if s == nil {
+				s = &summary{}
+				byComponent[item.Component] = s
+			}
+			s.count++
+			s.expected += item.Monthly.Expected
+		}
+		return byComponent
+	}
+	a, b := summarize(before), summarize(after)
+
+	components := make(map[string]bool)
+	for component := range a {
+		components[component] = true
+	}
+	for component := range b {
+		components[component] = true
+	}
+	names := make([]string, 0, len(components))
+	for component := range components {
+		names = append(names, component)
+	}
+	sort.Strings(names)
+
+	var effects []string
+	for _, component := range names {
+		x, y := a[component], b[component]
+		if x == nil {
+			x = &summary{}
+		}
+		if y == nil {
+			y = &summary{}
+		}
+		switch {
+		case x.count != y.count:
+			effects = append(effects, fmt.Sprintf("%+d %s (%d → %d)", y.count-x.count, component, x.count, y.count))
+		case math.Abs(y.expected-x.expected) >= costEpsilon:
+			effects = append(effects, fmt.Sprintf("%s %s", component, formatDelta(y.expected-x.expected)))
+		}
+	}
+	return effects
+}
+
+// maxValueChanges limita os caminhos listados na mudança de uma variável composta
+const maxValueChanges = 4
+
+// describeValueChange descreve a mudança de uma variável (ex: "true → false"). Em
+// mapas e objetos, lista os atributos alterados; valores sensíveis não são exibidos.
+func describeValueChange(before, after cty.Value, sensitive bool) string {
+	if sensitive {
+		return "(valor sensível)"
+	}
+	var changes []string
+	collectValueChanges("", before, after, &changes)
+	if len(changes) > maxValueChanges {
+		changes = append(changes[:maxValueChanges], fmt.Sprintf("e mais %d", len(changes)-maxValueChanges))
+	}
+	return strings.Join(changes, "; ")
+}
+
+func collectValueChanges(prefix string, before, after cty.Value, changes *[]string) {
+	if before.RawEquals(after) {
+		return
+	}
+	if isKeyed(before) && isKeyed(after) {
+		keys := make(map[string]bool)
+		for k := range before.AsValueMap() {
+			keys[k] = true
+		}
+		for k := range after.AsValueMap() {
+			keys[k] = true
+		}
+		names := make([]string, 0, len(keys))
+		for k := range keys {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			collectValueChanges(path, attributeOrNull(before, k), attributeOrNull(after, k), changes)
+		}
+		return
+	}
+	change := fmt.Sprintf("%s → %s", formatCtyValue(before), formatCtyValue(after))
+	if prefix != "" {
+		change = prefix + ": " + change
+	}
+	*changes = append(*changes, change)
+}
+
+// isKeyed indica se o valor é um mapa ou objeto conhecido e não nulo
+func isKeyed(v cty.Value) bool {
+	if v.IsNull() || !v.IsWhollyKnown() {
+		return false
+	}
+	ty := v.Type()
+	return ty.IsMapType() || ty.IsObjectType()
+}
+
+func attributeOrNull(v cty.Value, key string) cty.Value {
+	if value, ok := v.AsValueMap()[key]; ok {
+		return value
+	}
+	return cty.NullVal(cty.DynamicPseudoType)
+}
+
+// formatCtyValue formata um valor no estilo do HCL (ex: "t3.large", ["a", "b"])
+func formatCtyValue(v cty.Value) string {
+	switch {
+	case v.IsNull():
+		return "null"
+	case !v.IsKnown():
+		return "(desconhecido)"
+	}
+	ty := v.Type()
+	switch {
+	case ty == cty.String:
+		return fmt.Sprintf("%q", v.AsString())
+	case ty == cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	case ty == cty.Bool:
+		return fmt.Sprintf("%t", v.True())
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		var elems []string
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			elems = append(elems, formatCtyValue(elem))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	var fields []string
+	for it := v.ElementIterator(); it.Next(); {
+		k, elem := it.Element()
+		fields = append(fields, fmt.Sprintf("%s = %s", k.AsString(), formatCtyValue(elem)))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// formatDelta formata uma diferença com sinal (ex: "+$65.70", "-$1,200.00")
+func formatDelta(value float64) string {
+	if math.Abs(value) < costEpsilon {
+		return formatMoney(0)
+	}
+	if value < 0 {
+		return "-" + formatMoney(-value)
+	}
+	return "+" + formatMoney(value)
+}
+
+// Markdown formata a comparação para o comentário do plano no pull request:
+// totais, atribuição da diferença e recursos alterados
+func (d *CostDiff) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Diferença de custo mensal: %s → %s\n\n", d.Base.Environment, d.Head.Environment)
+	fmt.Fprintf(&b, "Tabela de preços %s (%s). Valores positivos aumentam o custo de %s em relação a %s.\n\n",
+		d.Head.PriceVersion, d.Head.Currency, d.Head.Environment, d.Base.Environment)
+
+	b.WriteString("| | Mínimo | Esperado | Máximo |\n")
+	b.WriteString("|---|-------:|---------:|-------:|\n")
+	for _, estimate := range []*CostEstimate{d.Base, d.Head} {
+		total := estimate.Total()
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", estimate.Environment,
+			formatMoney(total.Min), formatMoney(total.Expected), formatMoney(total.Max))
+	}
+	delta := d.Delta()
+	fmt.Fprintf(&b, "| **Diferença** | **%s** | **%s** | **%s** |\n",
+		formatDelta(delta.Min), formatDelta(delta.Expected), formatDelta(delta.Max))
+
+	if len(d.Attributions) == 0 && len(d.Resources) == 0 {
+		b.WriteString("\nSem diferença de custo.\n")
+		return b.String()
+	}
+
+	if len(d.Attributions) > 0 {
+		b.WriteString("\n#### Origem da diferença\n\n")
+		b.WriteString("| Origem | Mudança | Efeito | Esperado |\n")
+		b.WriteString("|--------|---------|--------|---------:|\n")
+		for _, a := range d.Attributions {
+			source := a.Source
+			if a.Variable != "" {
+				source = "`" + a.Variable + "`"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", source, escapeTableCell(a.Change),
+				strings.Join(a.Effects, "; "), formatDelta(a.Delta.Expected))
+		}
+	}
+	if len(d.Unchanged) > 0 {
+		names := make([]string, len(d.Unchanged))
+		for i, name := range d.Unchanged {
+			names[i] = "`" + name + "`"
+		}
+		fmt.Fprintf(&b, "\nVariáveis alteradas sem efeito no custo: %s.\n", strings.Join(names, ", "))
+	}
+
+	if len(d.Resources) > 0 {
+		b.WriteString("\n<details>\n<summary>Recursos com custo alterado</summary>\n\n")
+		b.WriteString("| Recurso | Componente | Mudança | Detalhe | Antes | Depois | Diferença |\n")
+		b.WriteString("|---------|------------|---------|---------|------:|-------:|----------:|\n")
+		for _, r := range d.Resources {
+			detail := r.HeadDetail
+			switch {
+			case r.Change == "removido":
+				detail = r.BaseDetail
+			case r.BaseDetail != "" && r.BaseDetail != r.HeadDetail:
+				detail = r.BaseDetail + " → " + r.HeadDetail
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s | %s |\n", r.Address, r.Component, r.Change,
+				escapeTableCell(detail), formatMoney(r.Base.Expected), formatMoney(r.Head.Expected), formatDelta(r.Delta.Expected))
+		}
+		b.WriteString("\n</details>\n")
+	}
+	return b.String()
+}
+
+// escapeTableCell evita que "|" quebre a tabela Markdown
+func escapeTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
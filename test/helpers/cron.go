@@ -0,0 +1,202 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronDescriptors são os atalhos aceitos pelo parser de cron do Velero
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField descreve os limites e os nomes aceitos em um campo da expressão
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minuto", min: 0, max: 59},
+	{name: "hora", min: 0, max: 23},
+	{name: "dia do mês", min: 1, max: 31},
+	{name: "mês", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "dia da semana", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// cronWindowDays é a janela em que as execuções são enumeradas: 28 anos repetem o
+// calendário (dias da semana e anos bissextos) entre 1901 e 2099
+const cronWindowDays = 28*365 + 7
+
+// cronWindowStart é o início da janela de enumeração (UTC, o fuso do Velero)
+var cronWindowStart = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// CronSchedule é uma expressão cron de 5 campos (minuto, hora, dia do mês, mês e
+// dia da semana) ou um atalho como @daily ou @every 6h, com o pior RPO e o número
+// médio de execuções por dia
+type CronSchedule struct {
+	Expression string
+	// RPO é o maior intervalo entre duas execuções consecutivas
+	RPO time.Duration
+	// BackupsPerDay é a média de execuções por dia
+	BackupsPerDay float64
+
+	fields [5][]bool
+	// dayStar e weekdayStar indicam dia do mês e dia da semana irrestritos: se os
+	// dois forem restritos, basta um deles casar
+	dayStar, weekdayStar bool
+}
+
+// ParseCron interpreta a expressão e calcula o RPO e as execuções por dia
+func ParseCron(expression string) (*CronSchedule, error) {
+	c := &CronSchedule{Expression: expression}
+	spec := strings.TrimSpace(expression)
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("cron %q: intervalo inválido", expression)
+		}
+		c.RPO = interval
+		c.BackupsPerDay = float64(24*time.Hour) / float64(interval)
+		return c, nil
+	}
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		spec = descriptor
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron %q: esperados %d campos, encontrados %d", expression, len(cronFields), len(parts))
+	}
+	for i, part := range parts {
+		values, err := cronFields[i].parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expression, err)
+		}
+		c.fields[i] = values
+	}
+	// Domingo pode ser 0 ou 7
+	c.fields[4][0] = c.fields[4][0] || c.fields[4][7]
+	c.dayStar = strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[2], "?")
+	c.weekdayStar = strings.HasPrefix(parts[4], "*") || strings.HasPrefix(parts[4], "?")
+
+	var first, previous time.Time
+	count := 0
+	for day := 0; day < cronWindowDays; day++ {
+		date := cronWindowStart.AddDate(0, 0, day)
+		if !c.matchesDay(date) {
+			continue
+		}
+		for hour, ok := range c.fields[1] {
+			if !ok {
+				continue
+			}
+			for minute, ok := range c.fields[0] {
+				if !ok {
+					continue
+				}
+				t := date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+				if count == 0 {
+					first = t
+				} else if t.Sub(previous) > c.RPO {
+					c.RPO = t.Sub(previous)
+				}
+				previous = t
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("cron %q: nunca executa", expression)
+	}
+	// A janela se repete: a última execução é seguida pela primeira da próxima janela
+	if wrap := first.AddDate(0, 0, cronWindowDays).Sub(previous); wrap > c.RPO {
+		c.RPO = wrap
+	}
+	c.BackupsPerDay = float64(count) / cronWindowDays
+	return c, nil
+}
+
+// matchesDay indica se a expressão executa na data
+func (c *CronSchedule) matchesDay(date time.Time) bool {
+	if !c.fields[3][int(date.Month())] {
+		return false
+	}
+	day := c.fields[2][date.Day()]
+	weekday := c.fields[4][int(date.Weekday())]
+	if c.dayStar || c.weekdayStar {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// parse interpreta um campo com listas, intervalos, passos e nomes
+func (f cronField) parse(part string) ([]bool, error) {
+	values := make([]bool, f.max+1)
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%s: passo inválido em %q", f.name, item)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			low, high = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = f.value(from); err != nil {
+				return nil, err
+			}
+			if high, err = f.value(to); err != nil {
+				return nil, err
+			}
+		default:
+			n, err := f.value(rangePart)
+			if err != nil {
+				return nil, err
+			}
+			low, high = n, n
+			// "a/n" vai de a até o fim do campo
+			if hasStep {
+				high = f.max
+			}
+		}
+		if low > high {
+			return nil, fmt.Errorf("%s: intervalo inválido %q", f.name, item)
+		}
+		for n := low; n <= high; n += step {
+			values[n] = true
+		}
+	}
+	return values, nil
+}
+
+// value converte um número ou nome do campo, validando os limites
+func (f cronField) value(s string) (int, error) {
+	for n, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return n, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("%s: valor inválido %q (%d-%d)", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Evaluator cria o avaliador do ambiente com a configuração: as variáveis
+// substituem as do terraform.tfvars.example e os demais campos substituem os
+// argumentos escritos como literais nos blocos module do main.tf
+func (c *EnvironmentConfig) Evaluator(env string) (*Evaluator, error) {
+	mod, inputs, err := loadEnvironment(GetEnvironmentPath(env))
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]cty.Value, len(c.AvailabilityZones))
+	for i, zone := range c.AvailabilityZones {
+		zones[i] = cty.StringVal(zone)
+	}
+	inputs["aws_region"] = cty.StringVal(c.Region)
+	inputs["availability_zones"] = cty.ListVal(zones)
+	inputs["vpc_cidr"] = cty.StringVal(c.VPCCIDR)
+	inputs["single_nat_gateway"] = cty.BoolVal(c.SingleNATGateway)
+	inputs["enable_vpc_endpoints"] = cty.BoolVal(c.EnableVPCEndpoints)
+	inputs["node_groups"] = NodeGroupsValue(c.NodeGroups)
+	inputs["control_plane_log_retention_days"] = cty.NumberIntVal(int64(c.ControlPlaneLogRetentionDays))
+
+	ev, err := NewEvaluator(mod, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := map[string]map[string]cty.Value{
+		"policy_engine": {
+			"engine":           cty.StringVal(c.PolicyEngine),
+			"enforcement_mode": cty.StringVal(c.EnforcementMode),
+		},
+		"ingress": {
+			"ingress_type": cty.StringVal(c.IngressType),
+		},
+		"observability": {
+			"prometheus_retention_days": cty.NumberIntVal(int64(c.PrometheusRetentionDays)),
+			"loki_retention_days":       cty.NumberIntVal(int64(c.LokiRetentionDays)),
+		},
+		"velero": {
+			"backup_schedule":       cty.StringVal(c.BackupSchedule),
+			"backup_retention_days": cty.NumberIntVal(int64(c.BackupRetentionDays)),
+		},
+	}
+	for module, values := range arguments {
+		for argument, val := range values {
+			if err := ev.OverrideModuleArgument(module, argument, val); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ev, nil
+}
@@ -0,0 +1,933 @@
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Evaluator avalia as expressões de um módulo Terraform sem executar providers.
+// Atributos calculados pelos providers (ids, ARNs, etc.) são representados por
+// placeholders no formato "${endereço.atributo}".
+type Evaluator struct {
+	Module *Module
+	// Path é o endereço do módulo na árvore (vazio para a raiz, ex: "module.ingress")
+	Path string
+
+	parent     *Evaluator
+	vars       map[string]cty.Value
+	locals     map[string]cty.Value
+	outputs    map[string]cty.Value
+	resources  map[string]cty.Value
+	inProgress map[string]bool
+	refs       map[string][][]pathStep
+	children   map[string]*Evaluator
+	functions  map[string]function.Function
+	// overrides substituem argumentos de chamadas de módulo, indexados pelo nome do módulo
+	overrides map[string]map[string]cty.Value
+}
+
+// ResourceInstance representa uma instância expandida de um recurso (após count/for_each)
+type ResourceInstance struct {
+	Resource    *Resource
+	Module      string
+	Key         cty.Value
+	Attributes  cty.Value
+	Diagnostics hcl.Diagnostics
+}
+
+// Address retorna o endereço completo da instância (ex: module.eks_cluster.aws_subnet.private[0])
+func (i *ResourceInstance) Address() string {
+	addr := i.Resource.Address() + instanceKeySuffix(i.Key)
+	if i.Module != "" {
+		return i.Module + "." + addr
+	}
+	return addr
+}
+
+// Attr retorna o valor de um atributo de primeiro nível da instância
+func (i *ResourceInstance) Attr(name string) cty.Value {
+	if i.Attributes.IsNull() || !i.Attributes.Type().IsObjectType() || !i.Attributes.Type().HasAttribute(name) {
+		return cty.NilVal
+	}
+	return i.Attributes.GetAttr(name)
+}
+
+// Values retorna os atributos da instância como tipos nativos Go
+func (i *ResourceInstance) Values() map[string]interface{} {
+	values, _ := CtyToGo(i.Attributes).(map[string]interface{})
+	return values
+}
+
+// instanceScope guarda os símbolos disponíveis durante a avaliação de uma instância
+type instanceScope struct {
+	countIndex cty.Value
+	eachKey    cty.Value
+	eachValue  cty.Value
+	iterators  map[string]cty.Value
+}
+
+func (s *instanceScope) withIterator(name string, val cty.Value) *instanceScope {
+	child := &instanceScope{iterators: make(map[string]cty.Value)}
+	if s != nil {
+		child.countIndex, child.eachKey, child.eachValue = s.countIndex, s.eachKey, s.eachValue
+		for k, v := range s.iterators {
+			child.iterators[k] = v
+		}
+	}
+	child.iterators[name] = val
+	return child
+}
+
+// pathStep é um passo de acesso a um valor: atributo ou índice (Index nil = qualquer elemento)
+type pathStep struct {
+	Attr  string
+	Index *cty.Value
+}
+
+var reservedRoots = map[string]bool{
+	"var": true, "local": true, "count": true, "each": true, "path": true,
+	"terraform": true, "self": true, "module": true, "data": true,
+}
+
+// NewEvaluator cria um avaliador para um módulo com os valores de entrada informados
+func NewEvaluator(mod *Module, inputs map[string]cty.Value) (*Evaluator, error) {
+	return newEvaluator(mod, inputs, nil, "")
+}
+
+// NewEnvironmentEvaluator cria um avaliador para um ambiente (ver GetEnvironmentPath), usando
+// os valores do terraform.tfvars.example como entrada
+func NewEnvironmentEvaluator(env string) (*Evaluator, error) {
+	mod, inputs, err := loadEnvironment(GetEnvironmentPath(env))
+	if err != nil {
+		return nil, err
+	}
+	return NewEvaluator(mod, inputs)
+}
+
+// loadEnvironment carrega o módulo de um diretório de ambiente e as entradas do
+// seu terraform.tfvars.example
+func loadEnvironment(dir string) (*Module, map[string]cty.Value, error) {
+	mod, err := LoadModule(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, err := ReadTFVars(filepath.Join(dir, "terraform.tfvars.example"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return mod, inputs, nil
+}
+
+func newEvaluator(mod *Module, inputs map[string]cty.Value, parent *Evaluator, path string) (*Evaluator, error) {
+	e := &Evaluator{
+		Module:     mod,
+		Path:       path,
+		parent:     parent,
+		vars:       make(map[string]cty.Value),
+		locals:     make(map[string]cty.Value),
+		outputs:    make(map[string]cty.Value),
+		resources:  make(map[string]cty.Value),
+		inProgress: make(map[string]bool),
+		children:   make(map[string]*Evaluator),
+		functions:  terraformFunctions(),
+		overrides:  make(map[string]map[string]cty.Value),
+	}
+
+	for name := range inputs {
+		if _, ok := mod.Variables[name]; !ok {
+			return nil, fmt.Errorf("%s: variável %q não declarada", e.describe(), name)
+		}
+	}
+
+	for name, v := range mod.Variables {
+		val, ok := inputs[name]
+		if !ok || val.IsNull() {
+			if v.Required() {
+				return nil, fmt.Errorf("%s: variável obrigatória %q sem valor", e.describe(), name)
+			}
+			val = v.Default
+		}
+		if v.Defaults != nil {
+			val = v.Defaults.Apply(val)
+		}
+		converted, err := convert.Convert(val, v.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: valor inválido para a variável %q: %w", e.describe(), name, err)
+		}
+		e.vars[name] = converted
+	}
+
+	e.refs = collectModuleRefs(mod)
+	return e, nil
+}
+
+func (e *Evaluator) describe() string {
+	if e.Path == "" {
+		return e.Module.Dir
+	}
+	return e.Path
+}
+
+// Var retorna o valor final (convertido para o tipo declarado) de uma variável de entrada
+func (e *Evaluator) Var(name string) cty.Value {
+	return e.vars[name]
+}
+
+// ValidateVariables avalia os blocos validation de todas as variáveis e
+// retorna as mensagens de erro das condições que falharam
+func (e *Evaluator) ValidateVariables() []error {
+	var errs []error
+	names := make([]string, 0, len(e.Module.Variables))
+	for name := range e.Module.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(e.vars)},
+		Functions: e.functions,
+	}
+	for _, name := range names {
+		for _, validation := range e.Module.Variables[name].Validations {
+			result, diags := validation.Condition.Value(ctx)
+			if diags.HasErrors() {
+				errs = append(errs, fmt.Errorf("var.%s: erro ao avaliar validation: %s", name, diags.Error()))
+				continue
+			}
+			if result.IsKnown() && !result.IsNull() && result.False() {
+				msg, _ := validation.ErrorMessage.Value(ctx)
+				text := "condição de validation falhou"
+				if msg.IsKnown() && !msg.IsNull() && msg.Type() == cty.String {
+					text = msg.AsString()
+				}
+				errs = append(errs, fmt.Errorf("var.%s: %s", name, text))
+			}
+		}
+	}
+	return errs
+}
+
+// Local retorna o valor avaliado de um local
+func (e *Evaluator) Local(name string) (cty.Value, error) {
+	if val, ok := e.locals[name]; ok {
+		return val, nil
+	}
+	local, ok := e.Module.Locals[name]
+	if !ok {
+		return cty.NilVal, fmt.Errorf("%s: local %q não declarado", e.describe(), name)
+	}
+	key := "local." + name
+	if e.inProgress[key] {
+		return cty.NilVal, fmt.Errorf("%s: referência circular em %s", e.describe(), key)
+	}
+	e.inProgress[key] = true
+	defer delete(e.inProgress, key)
+
+	val, diags := e.EvalExpr(local.Expr)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("%s: erro ao avaliar %s: %s", e.describe(), key, diags.Error())
+	}
+	e.locals[name] = val
+	return val, nil
+}
+
+// Output retorna o valor avaliado de um output
+func (e *Evaluator) Output(name string) (cty.Value, error) {
+	if val, ok := e.outputs[name]; ok {
+		return val, nil
+	}
+	output, ok := e.Module.Outputs[name]
+	if !ok || output.Expr == nil {
+		return cty.NilVal, fmt.Errorf("%s: output %q não declarado", e.describe(), name)
+	}
+	val, diags := e.EvalExpr(output.Expr)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("%s: erro ao avaliar output %s: %s", e.describe(), name, diags.Error())
+	}
+	e.outputs[name] = val
+	return val, nil
+}
+
+// OverrideModuleArgument substitui o valor de um argumento de um bloco module,
+// usado para variar valores escritos como literais no ambiente (ex: o engine do
+// policy_engine). Deve ser chamado antes da primeira avaliação do módulo filho
+func (e *Evaluator) OverrideModuleArgument(module, argument string, val cty.Value) error {
+	if _, ok := e.Module.ModuleCalls[module]; !ok {
+		return fmt.Errorf("%s: módulo %q não declarado", e.describe(), module)
+	}
+	if _, ok := e.children[module]; ok {
+		return fmt.Errorf("%s: módulo %q já avaliado", e.describe(), module)
+	}
+	if e.overrides[module] == nil {
+		e.overrides[module] = make(map[string]cty.Value)
+	}
+	e.overrides[module][argument] = val
+	return nil
+}
+
+// Child retorna o avaliador de um módulo filho declarado com um bloco module
+func (e *Evaluator) Child(name string) (*Evaluator, error) {
+	if child, ok := e.children[name]; ok {
+		return child, nil
+	}
+	call, ok := e.Module.ModuleCalls[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: módulo %q não declarado", e.describe(), name)
+	}
+
+	mod, err := LoadModule(filepath.Join(e.Module.Dir, call.Source))
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make(map[string]cty.Value)
+	for argName, attr := range call.Body.Attributes {
+		if moduleMetaArguments[argName] {
+			continue
+		}
+		val, diags := e.EvalExpr(attr.Expr)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%s: erro ao avaliar argumento %s do módulo %s: %s", e.describe(), argName, name, diags.Error())
+		}
+		inputs[argName] = val
+	}
+	for argName, val := range e.overrides[name] {
+		inputs[argName] = val
+	}
+
+	path := "module." + name
+	if e.Path != "" {
+		path = e.Path + "." + path
+	}
+	child, err := newEvaluator(mod, inputs, e, path)
+	if err != nil {
+		return nil, err
+	}
+	e.children[name] = child
+	return child, nil
+}
+
+// EvalExpr avalia uma expressão no escopo do módulo
+func (e *Evaluator) EvalExpr(expr hcl.Expression) (cty.Value, hcl.Diagnostics) {
+	return e.evalExpr(expr, nil)
+}
+
+func (e *Evaluator) evalExpr(expr hcl.Expression, scope *instanceScope) (cty.Value, hcl.Diagnostics) {
+	ctx, diags := e.buildContext(expr.Variables(), scope)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	val, valDiags := expr.Value(ctx)
+	return val, append(diags, valDiags...)
+}
+
+// buildContext monta o EvalContext contendo apenas os símbolos referenciados
+func (e *Evaluator) buildContext(traversals []hcl.Traversal, scope *instanceScope) (*hcl.EvalContext, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	vars := make(map[string]cty.Value)
+	locals := make(map[string]cty.Value)
+	managed := make(map[string]map[string]cty.Value)
+	data := make(map[string]map[string]cty.Value)
+	modules := make(map[string]map[string]cty.Value)
+
+	for _, traversal := range traversals {
+		root := traversal.RootName()
+		if scope != nil {
+			if _, ok := scope.iterators[root]; ok {
+				continue
+			}
+		}
+		names := traversalNames(traversal)
+
+		switch root {
+		case "var":
+			vars["var"] = cty.ObjectVal(e.vars)
+		case "local":
+			if len(names) < 2 {
+				continue
+			}
+			val, err := e.Local(names[1])
+			if err != nil {
+				diags = append(diags, errorDiag(err, traversal.SourceRange()))
+				continue
+			}
+			locals[names[1]] = val
+		case "count", "each", "self", "terraform", "path":
+			// Tratados abaixo a partir do escopo
+		case "module":
+			if len(names) < 3 {
+				diags = append(diags, errorDiag(fmt.Errorf("referência a módulo sem output: %s", strings.Join(names, ".")), traversal.SourceRange()))
+				continue
+			}
+			child, err := e.Child(names[1])
+			if err != nil {
+				diags = append(diags, errorDiag(err, traversal.SourceRange()))
+				continue
+			}
+			val, err := child.Output(names[2])
+			if err != nil {
+				diags = append(diags, errorDiag(err, traversal.SourceRange()))
+				continue
+			}
+			if modules[names[1]] == nil {
+				modules[names[1]] = make(map[string]cty.Value)
+			}
+			modules[names[1]][names[2]] = val
+		case "data":
+			if len(names) < 3 {
+				continue
+			}
+			val, err := e.resourceValue("data." + names[1] + "." + names[2])
+			if err != nil {
+				diags = append(diags, errorDiag(err, traversal.SourceRange()))
+				continue
+			}
+			if data[names[1]] == nil {
+				data[names[1]] = make(map[string]cty.Value)
+			}
+			data[names[1]][names[2]] = val
+		default:
+			if len(names) < 2 {
+				continue
+			}
+			val, err := e.resourceValue(root + "." + names[1])
+			if err != nil {
+				diags = append(diags, errorDiag(err, traversal.SourceRange()))
+				continue
+			}
+			if managed[root] == nil {
+				managed[root] = make(map[string]cty.Value)
+			}
+			managed[root][names[1]] = val
+		}
+	}
+
+	ctx := &hcl.EvalContext{Variables: vars, Functions: e.functions}
+	if len(locals) > 0 {
+		vars["local"] = cty.ObjectVal(locals)
+	}
+	for resourceType, byName := range managed {
+		vars[resourceType] = cty.ObjectVal(byName)
+	}
+	if len(data) > 0 {
+		byType := make(map[string]cty.Value, len(data))
+		for resourceType, byName := range data {
+			byType[resourceType] = cty.ObjectVal(byName)
+		}
+		vars["data"] = cty.ObjectVal(byType)
+	}
+	if len(modules) > 0 {
+		byName := make(map[string]cty.Value, len(modules))
+		for name, outputs := range modules {
+			byName[name] = cty.ObjectVal(outputs)
+		}
+		vars["module"] = cty.ObjectVal(byName)
+	}
+
+	vars["path"] = cty.ObjectVal(map[string]cty.Value{
+		"module": cty.StringVal(e.Module.Dir),
+		"root":   cty.StringVal(e.rootEvaluator().Module.Dir),
+		"cwd":    cty.StringVal(e.rootEvaluator().Module.Dir),
+	})
+	vars["terraform"] = cty.ObjectVal(map[string]cty.Value{"workspace": cty.StringVal("default")})
+
+	if scope != nil {
+		if scope.countIndex != cty.NilVal {
+			vars["count"] = cty.ObjectVal(map[string]cty.Value{"index": scope.countIndex})
+		}
+		if scope.eachKey != cty.NilVal {
+			vars["each"] = cty.ObjectVal(map[string]cty.Value{"key": scope.eachKey, "value": scope.eachValue})
+		}
+		for name, val := range scope.iterators {
+			vars[name] = val
+		}
+	}
+
+	return ctx, diags
+}
+
+func (e *Evaluator) rootEvaluator() *Evaluator {
+	for e.parent != nil {
+		e = e.parent
+	}
+	return e
+}
+
+// traversalNames retorna os nomes dos atributos iniciais de um traversal
+func traversalNames(traversal hcl.Traversal) []string {
+	var names []string
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		default:
+			return names
+		}
+	}
+	return names
+}
+
+func errorDiag(err error, rng hcl.Range) *hcl.Diagnostic {
+	return &hcl.Diagnostic{Severity: hcl.DiagError, Summary: err.Error(), Subject: rng.Ptr()}
+}
+
+// Expand expande todos os recursos do módulo e dos módulos filhos em instâncias
+func (e *Evaluator) Expand() ([]*ResourceInstance, error) {
+	var all []*ResourceInstance
+	for _, r := range e.Module.SortedResources() {
+		instances, err := e.Instances(r.Address())
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, instances...)
+	}
+
+	names := make([]string, 0, len(e.Module.ModuleCalls))
+	for name := range e.Module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child, err := e.Child(name)
+		if err != nil {
+			return nil, err
+		}
+		instances, err := child.Expand()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, instances...)
+	}
+	return all, nil
+}
+
+// Instances expande um recurso do módulo (ex: "helm_release.argocd") em suas instâncias
+func (e *Evaluator) Instances(addr string) ([]*ResourceInstance, error) {
+	r, ok := e.Module.Resources[addr]
+	if !ok {
+		return nil, fmt.Errorf("%s: recurso %s não declarado", e.describe(), addr)
+	}
+
+	scopes, keys, err := e.instanceScopes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*ResourceInstance, 0, len(scopes))
+	for i, scope := range scopes {
+		attrs, diags := e.evalBody(r.Body, scope)
+		inst := &ResourceInstance{
+			Resource:    r,
+			Module:      e.Path,
+			Key:         keys[i],
+			Attributes:  withKnownComputed(r.Type, attrs),
+			Diagnostics: diags,
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
+
+// instanceScopes avalia count/for_each e retorna um escopo por instância
+func (e *Evaluator) instanceScopes(r *Resource) ([]*instanceScope, []cty.Value, error) {
+	switch {
+	case r.Count != nil:
+		val, diags := e.evalExpr(r.Count, nil)
+		if diags.HasErrors() {
+			return nil, nil, fmt.Errorf("%s: erro ao avaliar count de %s: %s", e.describe(), r.Address(), diags.Error())
+		}
+		n, _ := val.AsBigFloat().Int64()
+		scopes := make([]*instanceScope, n)
+		keys := make([]cty.Value, n)
+		for i := range scopes {
+			keys[i] = cty.NumberIntVal(int64(i))
+			scopes[i] = &instanceScope{countIndex: keys[i]}
+		}
+		return scopes, keys, nil
+	case r.ForEach != nil:
+		val, diags := e.evalExpr(r.ForEach, nil)
+		if diags.HasErrors() {
+			return nil, nil, fmt.Errorf("%s: erro ao avaliar for_each de %s: %s", e.describe(), r.Address(), diags.Error())
+		}
+		var scopes []*instanceScope
+		var keys []cty.Value
+		if val.Type().IsSetType() {
+			for it := val.ElementIterator(); it.Next(); {
+				_, v := it.Element()
+				keys = append(keys, v)
+				scopes = append(scopes, &instanceScope{eachKey: v, eachValue: v})
+			}
+			return scopes, keys, nil
+		}
+		elems := val.AsValueMap()
+		names := make([]string, 0, len(elems))
+		for k := range elems {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			key := cty.StringVal(k)
+			keys = append(keys, key)
+			scopes = append(scopes, &instanceScope{eachKey: key, eachValue: elems[k]})
+		}
+		return scopes, keys, nil
+	default:
+		return []*instanceScope{nil}, []cty.Value{cty.NilVal}, nil
+	}
+}
+
+// evalBody avalia atributos e blocos (incluindo blocos dynamic) de um corpo HCL.
+// Blocos são representados como listas de objetos, agrupados por tipo.
+func (e *Evaluator) evalBody(body *hclsyntax.Body, scope *instanceScope) (cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	attrs := make(map[string]cty.Value)
+
+	for name, attr := range body.Attributes {
+		if metaArguments[name] {
+			continue
+		}
+		val, valDiags := e.evalExpr(attr.Expr, scope)
+		diags = append(diags, valDiags...)
+		if valDiags.HasErrors() {
+			continue
+		}
+		attrs[name] = val
+	}
+
+	blocks := make(map[string][]cty.Value)
+	var order []string
+	addBlock := func(blockType string, val cty.Value) {
+		if _, ok := blocks[blockType]; !ok {
+			order = append(order, blockType)
+		}
+		if val != cty.NilVal {
+			blocks[blockType] = append(blocks[blockType], val)
+		} else if blocks[blockType] == nil {
+			blocks[blockType] = []cty.Value{}
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if metaBlocks[block.Type] {
+			continue
+		}
+		if block.Type != "dynamic" {
+			val, blockDiags := e.evalBody(block.Body, scope)
+			diags = append(diags, blockDiags...)
+			addBlock(block.Type, val)
+			continue
+		}
+
+		blockType := block.Labels[0]
+		iterator := blockType
+		if attr, ok := block.Body.Attributes["iterator"]; ok {
+			iterator = hcl.ExprAsKeyword(attr.Expr)
+		}
+		forEachAttr, ok := block.Body.Attributes["for_each"]
+		if !ok {
+			continue
+		}
+		collection, forDiags := e.evalExpr(forEachAttr.Expr, scope)
+		diags = append(diags, forDiags...)
+		if forDiags.HasErrors() {
+			continue
+		}
+
+		addBlock(blockType, cty.NilVal)
+		for it := collection.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			iterScope := scope.withIterator(iterator, cty.ObjectVal(map[string]cty.Value{"key": k, "value": v}))
+			for _, content := range block.Body.Blocks {
+				if content.Type != "content" {
+					continue
+				}
+				val, blockDiags := e.evalBody(content.Body, iterScope)
+				diags = append(diags, blockDiags...)
+				addBlock(blockType, val)
+			}
+		}
+	}
+
+	for _, blockType := range order {
+		attrs[blockType] = cty.TupleVal(blocks[blockType])
+	}
+	return cty.ObjectVal(attrs), diags
+}
+
+// resourceValue retorna o valor de um recurso para uso em referências, incluindo
+// placeholders para os atributos calculados referenciados no módulo
+func (e *Evaluator) resourceValue(addr string) (cty.Value, error) {
+	if val, ok := e.resources[addr]; ok {
+		return val, nil
+	}
+	if e.inProgress[addr] {
+		return cty.NilVal, fmt.Errorf("%s: referência circular em %s", e.describe(), addr)
+	}
+	e.inProgress[addr] = true
+	defer delete(e.inProgress, addr)
+
+	r, ok := e.Module.Resources[addr]
+	if !ok {
+		return cty.NilVal, fmt.Errorf("%s: recurso %s não declarado", e.describe(), addr)
+	}
+	instances, err := e.Instances(addr)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	refs := e.refs[addr]
+	expanded := r.Count != nil || r.ForEach != nil
+	complete := func(inst *ResourceInstance) cty.Value {
+		val := inst.Attributes
+		for _, ref := range refs {
+			steps := ref
+			if expanded {
+				if len(steps) == 0 || steps[0].Attr != "" {
+					continue
+				}
+				steps = steps[1:]
+			}
+			val = ensurePath(val, steps, inst.Address())
+		}
+		return val
+	}
+
+	var val cty.Value
+	switch {
+	case r.ForEach != nil:
+		byKey := make(map[string]cty.Value, len(instances))
+		for _, inst := range instances {
+			byKey[inst.Key.AsString()] = complete(inst)
+		}
+		val = cty.ObjectVal(byKey)
+	case r.Count != nil:
+		elems := make([]cty.Value, 0, len(instances))
+		for _, inst := range instances {
+			elems = append(elems, complete(inst))
+		}
+		val = cty.TupleVal(elems)
+	default:
+		val = complete(instances[0])
+	}
+
+	e.resources[addr] = val
+	return val, nil
+}
+
+// ensurePath garante que o caminho exista no valor, criando placeholders para
+// os atributos calculados que não foram configurados
+func ensurePath(val cty.Value, steps []pathStep, prefix string) cty.Value {
+	if len(steps) == 0 {
+		if val == cty.NilVal {
+			return cty.StringVal(ComputedPlaceholder(prefix))
+		}
+		return val
+	}
+	step, rest := steps[0], steps[1:]
+
+	if val == cty.NilVal || val.IsNull() {
+		return buildPath(steps, prefix)
+	}
+	if !val.IsKnown() {
+		return val
+	}
+
+	ty := val.Type()
+	if step.Attr != "" {
+		if !ty.IsObjectType() {
+			return val
+		}
+		attrs := val.AsValueMap()
+		if attrs == nil {
+			attrs = make(map[string]cty.Value)
+		}
+		attrs[step.Attr] = ensurePath(attrs[step.Attr], rest, prefix+"."+step.Attr)
+		return cty.ObjectVal(attrs)
+	}
+
+	switch {
+	case ty.IsTupleType() || ty.IsListType():
+		elems := val.AsValueSlice()
+		if len(elems) == 0 {
+			return buildPath(steps, prefix)
+		}
+		for i := range elems {
+			elems[i] = ensurePath(elems[i], rest, fmt.Sprintf("%s[%d]", prefix, i))
+		}
+		return cty.TupleVal(elems)
+	case ty.IsObjectType() || ty.IsMapType():
+		if step.Index == nil || step.Index.Type() != cty.String {
+			return val
+		}
+		attrs := val.AsValueMap()
+		if attrs == nil {
+			attrs = make(map[string]cty.Value)
+		}
+		key := step.Index.AsString()
+		attrs[key] = ensurePath(attrs[key], rest, fmt.Sprintf("%s[%q]", prefix, key))
+		return cty.ObjectVal(attrs)
+	}
+	return val
+}
+
+// buildPath cria um valor com placeholder no final do caminho
+func buildPath(steps []pathStep, prefix string) cty.Value {
+	if len(steps) == 0 {
+		return cty.StringVal(ComputedPlaceholder(prefix))
+	}
+	step, rest := steps[0], steps[1:]
+	if step.Attr != "" {
+		return cty.ObjectVal(map[string]cty.Value{step.Attr: buildPath(rest, prefix+"."+step.Attr)})
+	}
+	if step.Index != nil && step.Index.Type() == cty.String {
+		key := step.Index.AsString()
+		return cty.ObjectVal(map[string]cty.Value{key: buildPath(rest, fmt.Sprintf("%s[%q]", prefix, key))})
+	}
+	return cty.TupleVal([]cty.Value{buildPath(rest, prefix+"[0]")})
+}
+
+// ComputedPlaceholder retorna a representação de um atributo conhecido apenas após o apply
+func ComputedPlaceholder(address string) string {
+	return "${" + address + "}"
+}
+
+// IsComputedPlaceholder indica se a string é (ou contém) um atributo calculado
+func IsComputedPlaceholder(s string) bool {
+	return strings.Contains(s, "${")
+}
+
+// withKnownComputed adiciona atributos calculados cujo valor pode ser derivado da configuração
+func withKnownComputed(resourceType string, attrs cty.Value) cty.Value {
+	if !attrs.Type().IsObjectType() {
+		return attrs
+	}
+	values := attrs.AsValueMap()
+	if values == nil {
+		values = make(map[string]cty.Value)
+	}
+
+	switch resourceType {
+	case "aws_s3_bucket":
+		bucket, ok := values["bucket"]
+		if !ok || bucket.IsNull() || !bucket.IsKnown() || bucket.Type() != cty.String {
+			return attrs
+		}
+		values["id"] = bucket
+		values["arn"] = cty.StringVal("arn:aws:s3:::" + bucket.AsString())
+	default:
+		return attrs
+	}
+	return cty.ObjectVal(values)
+}
+
+func instanceKeySuffix(key cty.Value) string {
+	if key == cty.NilVal {
+		return ""
+	}
+	if key.Type() == cty.String {
+		return fmt.Sprintf("[%q]", key.AsString())
+	}
+	i, _ := key.AsBigFloat().Int64()
+	return fmt.Sprintf("[%d]", i)
+}
+
+// collectModuleRefs percorre todas as expressões do módulo e registra, para cada
+// recurso, os caminhos de atributos referenciados
+func collectModuleRefs(mod *Module) map[string][][]pathStep {
+	refs := make(map[string][][]pathStep)
+	record := func(traversal hcl.Traversal, extra []pathStep) {
+		root := traversal.RootName()
+		names := traversalNames(traversal)
+		var addr string
+		var skip int
+		switch {
+		case root == "data" && len(names) >= 3:
+			addr, skip = "data."+names[1]+"."+names[2], 3
+		case !reservedRoots[root] && len(names) >= 2:
+			addr, skip = root+"."+names[1], 2
+		default:
+			return
+		}
+		steps := traversalSteps(traversal[skip:])
+		steps = append(steps, extra...)
+		refs[addr] = append(refs[addr], steps)
+	}
+
+	visit := func(expr hclsyntax.Expression) {
+		hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			switch n := node.(type) {
+			case *hclsyntax.ScopeTraversalExpr:
+				record(n.Traversal, nil)
+			case *hclsyntax.RelativeTraversalExpr:
+				if base, prefix := unwrapSource(n.Source); base != nil {
+					record(base.Traversal, append(prefix, traversalSteps(n.Traversal)...))
+				}
+			case *hclsyntax.SplatExpr:
+				if base, prefix := unwrapSource(n.Source); base != nil {
+					steps := append(prefix, pathStep{})
+					if each, ok := n.Each.(*hclsyntax.RelativeTraversalExpr); ok {
+						steps = append(steps, traversalSteps(each.Traversal)...)
+					}
+					record(base.Traversal, steps)
+				}
+			}
+			return nil
+		})
+	}
+
+	var visitBody func(body *hclsyntax.Body)
+	visitBody = func(body *hclsyntax.Body) {
+		for _, attr := range body.Attributes {
+			visit(attr.Expr)
+		}
+		for _, block := range body.Blocks {
+			visitBody(block.Body)
+		}
+	}
+	for _, file := range mod.Files {
+		visitBody(file.Body.(*hclsyntax.Body))
+	}
+	return refs
+}
+
+// unwrapSource desce por expressões de índice até encontrar o traversal de origem
+func unwrapSource(expr hclsyntax.Expression) (*hclsyntax.ScopeTraversalExpr, []pathStep) {
+	var steps []pathStep
+	for {
+		switch n := expr.(type) {
+		case *hclsyntax.ScopeTraversalExpr:
+			return n, steps
+		case *hclsyntax.IndexExpr:
+			steps = append([]pathStep{{}}, steps...)
+			expr = n.Collection
+		default:
+			return nil, nil
+		}
+	}
+}
+
+func traversalSteps(traversal hcl.Traversal) []pathStep {
+	steps := make([]pathStep, 0, len(traversal))
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseAttr:
+			steps = append(steps, pathStep{Attr: s.Name})
+		case hcl.TraverseIndex:
+			key := s.Key
+			steps = append(steps, pathStep{Index: &key})
+		case hcl.TraverseSplat:
+			steps = append(steps, pathStep{})
+		}
+	}
+	return steps
+}
@@ -0,0 +1,155 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// route53UnscopedActions são as ações do Route53 usadas pelo external-dns que não
+// aceitam restrição por recurso e por isso são concedidas em "*"
+var route53UnscopedActions = map[string]bool{
+	"route53:ListHostedZones":       true,
+	"route53:ListHostedZonesByName": true,
+	"route53:GetChange":             true,
+}
+
+// ExternalDNSScope é o escopo de atuação do external-dns de um ambiente: a zona e o
+// domínio passados ao módulo, os filtros renderizados nos values do chart e as
+// permissões da policy IAM
+type ExternalDNSScope struct {
+	Env           string
+	Release       string
+	ZoneID        string
+	DomainName    string
+	DomainFilters []string
+	ZoneIDFilters []string
+	TXTOwnerID    string
+	Grants        []*IAMGrant
+	File          string
+	Line          int
+}
+
+// ExternalDNSScopes retorna o escopo de cada módulo filho do ambiente que instala
+// o external-dns (helm_release.external_dns e data.aws_iam_policy_document.external_dns)
+func ExternalDNSScopes(env string, ev *Evaluator) ([]*ExternalDNSScope, error) {
+	names := make([]string, 0, len(ev.Module.ModuleCalls))
+	for name := range ev.Module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var scopes []*ExternalDNSScope
+	for _, name := range names {
+		child, err := ev.Child(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := child.Module.Resources["helm_release.external_dns"]; !ok {
+			continue
+		}
+		releases, err := child.Instances("helm_release.external_dns")
+		if err != nil {
+			return nil, err
+		}
+		documents, err := child.Instances("data.aws_iam_policy_document.external_dns")
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) != 1 || len(documents) != 1 {
+			continue
+		}
+
+		release, err := RenderHelmRelease(releases[0])
+		if err != nil {
+			return nil, err
+		}
+		if documents[0].Diagnostics.HasErrors() {
+			return nil, fmt.Errorf("%s: erro ao avaliar recurso: %s", documents[0].Address(), documents[0].Diagnostics.Error())
+		}
+		grants, err := PolicyDocumentGrants(documents[0].Values())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", documents[0].Address(), err)
+		}
+
+		scope := &ExternalDNSScope{
+			Env: env, Release: release.Address, Grants: grants,
+			DomainFilters: stringList(release.Values["domainFilters"]),
+			File:          release.File, Line: release.Line,
+		}
+		if zone := child.Var("route53_zone_id"); isLiteralString(zone) {
+			scope.ZoneID = zone.AsString()
+		}
+		if domain := child.Var("domain_name"); isLiteralString(domain) {
+			scope.DomainName = domain.AsString()
+		}
+		scope.TXTOwnerID, _ = release.Values["txtOwnerId"].(string)
+		for _, arg := range stringList(release.Values["extraArgs"]) {
+			if value, ok := strings.CutPrefix(arg, "--zone-id-filter="); ok {
+				scope.ZoneIDFilters = append(scope.ZoneIDFilters, value)
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// ZoneARN retorna o ARN da zona Route53 do escopo
+func (s *ExternalDNSScope) ZoneARN() string {
+	return "arn:aws:route53:::hostedzone/" + s.ZoneID
+}
+
+// Problems lista onde o escopo do external-dns vai além da zona do ambiente:
+// permissões IAM em outros recursos e filtros de domínio ou zona diferentes
+func (s *ExternalDNSScope) Problems() []string {
+	var problems []string
+	if s.ZoneID == "" || s.DomainName == "" {
+		return []string{"route53_zone_id e domain_name devem ser definidos"}
+	}
+	for _, grant := range s.Grants {
+		if !strings.HasPrefix(grant.Action, "route53:") {
+			problems = append(problems, fmt.Sprintf("permissão fora do Route53: %s", grant))
+			continue
+		}
+		if grant.Resource == s.ZoneARN() {
+			continue
+		}
+		if grant.Resource == "*" && route53UnscopedActions[grant.Action] {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("permissão %s deve ser restrita a %s", grant, s.ZoneARN()))
+	}
+	if len(s.DomainFilters) != 1 || s.DomainFilters[0] != s.DomainName {
+		problems = append(problems, fmt.Sprintf("domainFilters %v deve ser [%s]", s.DomainFilters, s.DomainName))
+	}
+	if len(s.ZoneIDFilters) != 1 || s.ZoneIDFilters[0] != s.ZoneID {
+		problems = append(problems, fmt.Sprintf("--zone-id-filter %v deve ser [%s]", s.ZoneIDFilters, s.ZoneID))
+	}
+	if s.TXTOwnerID == "" {
+		problems = append(problems, "txtOwnerId deve ser definido")
+	}
+	return problems
+}
+
+// ExternalDNSOwnerConflicts retorna, para cada txtOwnerId usado por mais de um
+// ambiente, os escopos em conflito. Com o mesmo dono, o external-dns de um
+// ambiente considera seus os registros do outro e pode removê-los
+func ExternalDNSOwnerConflicts(scopes []*ExternalDNSScope) map[string][]*ExternalDNSScope {
+	owners := make(map[string][]*ExternalDNSScope)
+	for _, scope := range scopes {
+		if scope.TXTOwnerID != "" {
+			owners[scope.TXTOwnerID] = append(owners[scope.TXTOwnerID], scope)
+		}
+	}
+	conflicts := make(map[string][]*ExternalDNSScope)
+	for owner, users := range owners {
+		envs := make(map[string]bool)
+		for _, scope := range users {
+			envs[scope.Env] = true
+		}
+		if len(envs) > 1 {
+			conflicts[owner] = users
+		}
+	}
+	return conflicts
+}
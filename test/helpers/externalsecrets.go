@@ -0,0 +1,182 @@
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholderAccountID é a conta usada nos ARNs montados a partir das chaves dos
+// exemplos; IDs de conta calculados (data.aws_caller_identity) na policy são
+// tratados como curinga
+const placeholderAccountID = "123456789012"
+
+// computedExpression casa com atributos calculados dentro de strings avaliadas
+var computedExpression = regexp.MustCompile(`\$\{[^}]*\}`)
+
+// GetExternalSecretExamplesPath retorna o diretório de exemplos do módulo external-secrets
+func GetExternalSecretExamplesPath() string {
+	return filepath.Join(GetModulePath("platform/external-secrets"), "examples")
+}
+
+// SecretStore é um ClusterSecretStore AWS declarado em kubernetes_manifest
+type SecretStore struct {
+	Name    string
+	Service string
+	Region  string
+	Address string
+}
+
+// secretARN monta o ARN lido pelo controller para uma chave do store e a ação IAM
+// que a leitura exige
+func (s *SecretStore) secretARN(key string) (action, arn string, err error) {
+	switch s.Service {
+	case "SecretsManager":
+		if strings.HasPrefix(key, "arn:") {
+			return "secretsmanager:GetSecretValue", key, nil
+		}
+		// O Secrets Manager acrescenta 6 caracteres aleatórios ao nome no ARN
+		return "secretsmanager:GetSecretValue",
+			fmt.Sprintf("arn:aws:secretsmanager:%s:%s:secret:%s-AbCdEf", s.Region, placeholderAccountID, key), nil
+	case "ParameterStore":
+		if strings.HasPrefix(key, "arn:") {
+			return "ssm:GetParameter", key, nil
+		}
+		return "ssm:GetParameter",
+			fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", s.Region, placeholderAccountID, strings.TrimPrefix(key, "/")), nil
+	default:
+		return "", "", fmt.Errorf("service %q não suportado", s.Service)
+	}
+}
+
+// ExternalSecretContext é o que o módulo external-secrets cria em um ambiente: os
+// ClusterSecretStores e as permissões da role IRSA do controller
+type ExternalSecretContext struct {
+	Env    string
+	Stores map[string]*SecretStore
+	Grants []*IAMGrant
+}
+
+// NewExternalSecretContext avalia o ambiente e lê os ClusterSecretStores e a
+// policy data.aws_iam_policy_document.external_secrets do módulo que os declara
+func NewExternalSecretContext(env string) (*ExternalSecretContext, error) {
+	ev, err := NewEnvironmentEvaluator(env)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ev.Module.ModuleCalls))
+	for name := range ev.Module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := &ExternalSecretContext{Env: env, Stores: make(map[string]*SecretStore)}
+	for _, name := range names {
+		child, err := ev.Child(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := child.Module.Resources["data.aws_iam_policy_document.external_secrets"]; !ok {
+			continue
+		}
+		for _, r := range child.Module.SortedResources() {
+			if r.Mode != "managed" || r.Type != "kubernetes_manifest" {
+				continue
+			}
+			instances, err := child.Instances(r.Address())
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range instances {
+				if inst.Diagnostics.HasErrors() {
+					return nil, fmt.Errorf("%s: erro ao avaliar recurso: %s", inst.Address(), inst.Diagnostics.Error())
+				}
+				manifest, _ := inst.Values()["manifest"].(map[string]interface{})
+				if manifest["kind"] != "ClusterSecretStore" {
+					continue
+				}
+				store := &SecretStore{Address: inst.Address()}
+				store.Name, _ = lookupHelmValue(manifest, "metadata.name").(string)
+				store.Service, _ = lookupHelmValue(manifest, "spec.provider.aws.service").(string)
+				store.Region, _ = lookupHelmValue(manifest, "spec.provider.aws.region").(string)
+				ctx.Stores[store.Name] = store
+			}
+		}
+
+		documents, err := child.Instances("data.aws_iam_policy_document.external_secrets")
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			grants, err := PolicyDocumentGrants(document.Values())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", document.Address(), err)
+			}
+			for _, grant := range grants {
+				resource := computedExpression.ReplaceAllString(grant.Resource, "*")
+				ctx.Grants = append(ctx.Grants, &IAMGrant{Action: grant.Action, Resource: resource, Conditions: grant.Conditions})
+			}
+		}
+	}
+	if len(ctx.Stores) == 0 {
+		return nil, fmt.Errorf("%s: nenhum ClusterSecretStore declarado", env)
+	}
+	return ctx, nil
+}
+
+// ValidateExternalSecret verifica se o ExternalSecret referencia um
+// ClusterSecretStore do módulo e se cada chave remota pode ser lida pela role
+// IRSA do controller
+func ValidateExternalSecret(object *KubernetesObject, ctx *ExternalSecretContext) []string {
+	var problems []string
+	ref, _ := lookupHelmValue(object.Object, "spec.secretStoreRef").(map[string]interface{})
+	name, _ := ref["name"].(string)
+	kind, _ := ref["kind"].(string)
+	if kind == "" {
+		kind = "SecretStore"
+	}
+	store, ok := ctx.Stores[name]
+	if kind != "ClusterSecretStore" || !ok {
+		stores := make([]string, 0, len(ctx.Stores))
+		for storeName := range ctx.Stores {
+			stores = append(stores, storeName)
+		}
+		sort.Strings(stores)
+		return []string{fmt.Sprintf("secretStoreRef %s/%s; o módulo cria os ClusterSecretStores %s", kind, name, strings.Join(stores, ", "))}
+	}
+
+	var keys []string
+	data, _ := lookupHelmValue(object.Object, "spec.data").([]interface{})
+	for _, d := range data {
+		entry, _ := d.(map[string]interface{})
+		if key, ok := lookupHelmValue(entry, "remoteRef.key").(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	dataFrom, _ := lookupHelmValue(object.Object, "spec.dataFrom").([]interface{})
+	for _, d := range dataFrom {
+		entry, _ := d.(map[string]interface{})
+		if key, ok := lookupHelmValue(entry, "extract.key").(string); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		action, arn, err := store.secretARN(key)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", store.Name, err))
+			continue
+		}
+		if !anyCovers(ctx.Grants, &IAMGrant{Action: action, Resource: arn}) {
+			problems = append(problems, fmt.Sprintf("chave %s (%s): %s em %s não é permitido pela policy do IRSA no %s", key, store.Name, action, arn, ctx.Env))
+		}
+	}
+	return problems
+}
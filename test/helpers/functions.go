@@ -0,0 +1,331 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/ext/tryfunc"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	"gopkg.in/yaml.v3"
+)
+
+// terraformFunctions retorna as funções do Terraform suportadas pelo avaliador
+func terraformFunctions() map[string]function.Function {
+	return map[string]function.Function{
+		"abs":          stdlib.AbsoluteFunc,
+		"alltrue":      allTrueFunc,
+		"anytrue":      anyTrueFunc,
+		"base64decode": base64DecodeFunc,
+		"base64encode": base64EncodeFunc,
+		"can":          tryfunc.CanFunc,
+		"ceil":         stdlib.CeilFunc,
+		"chomp":        stdlib.ChompFunc,
+		"cidrhost":     cidrHostFunc,
+		"cidrsubnet":   cidrSubnetFunc,
+		"coalesce":     stdlib.CoalesceFunc,
+		"compact":      stdlib.CompactFunc,
+		"concat":       stdlib.ConcatFunc,
+		"contains":     stdlib.ContainsFunc,
+		"distinct":     stdlib.DistinctFunc,
+		"element":      stdlib.ElementFunc,
+		"endswith":     endsWithFunc,
+		"flatten":      stdlib.FlattenFunc,
+		"floor":        stdlib.FloorFunc,
+		"format":       stdlib.FormatFunc,
+		"formatlist":   stdlib.FormatListFunc,
+		"join":         stdlib.JoinFunc,
+		"jsondecode":   stdlib.JSONDecodeFunc,
+		"jsonencode":   stdlib.JSONEncodeFunc,
+		"keys":         stdlib.KeysFunc,
+		"length":       stdlib.LengthFunc,
+		"lookup":       stdlib.LookupFunc,
+		"lower":        stdlib.LowerFunc,
+		"max":          stdlib.MaxFunc,
+		"merge":        stdlib.MergeFunc,
+		"min":          stdlib.MinFunc,
+		"range":        stdlib.RangeFunc,
+		"regex":        stdlib.RegexFunc,
+		"regexall":     stdlib.RegexAllFunc,
+		"replace":      replaceFunc,
+		"reverse":      stdlib.ReverseListFunc,
+		"setunion":     stdlib.SetUnionFunc,
+		"sort":         stdlib.SortFunc,
+		"split":        stdlib.SplitFunc,
+		"startswith":   startsWithFunc,
+		"substr":       stdlib.SubstrFunc,
+		"title":        stdlib.TitleFunc,
+		"tolist":       stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+		"tomap":        stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tonumber":     stdlib.MakeToFunc(cty.Number),
+		"toset":        stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+		"tostring":     stdlib.MakeToFunc(cty.String),
+		"trimprefix":   stdlib.TrimPrefixFunc,
+		"trimspace":    stdlib.TrimSpaceFunc,
+		"trimsuffix":   stdlib.TrimSuffixFunc,
+		"try":          tryfunc.TryFunc,
+		"upper":        stdlib.UpperFunc,
+		"values":       stdlib.ValuesFunc,
+		"yamldecode":   yamlDecodeFunc,
+		"yamlencode":   yamlEncodeFunc,
+		"zipmap":       stdlib.ZipmapFunc,
+	}
+}
+
+var allTrueFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "list", Type: cty.List(cty.Bool)}},
+	Type:   function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		for it := args[0].ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if v.IsNull() || v.False() {
+				return cty.False, nil
+			}
+		}
+		return cty.True, nil
+	},
+})
+
+var anyTrueFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "list", Type: cty.List(cty.Bool)}},
+	Type:   function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		for it := args[0].ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if !v.IsNull() && v.True() {
+				return cty.True, nil
+			}
+		}
+		return cty.False, nil
+	},
+})
+
+var base64DecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		decoded, err := base64.StdEncoding.DecodeString(args[0].AsString())
+		if err != nil {
+			// Valores calculados (placeholders) não são base64 válido
+			return cty.StringVal(args[0].AsString()), nil
+		}
+		return cty.StringVal(string(decoded)), nil
+	},
+})
+
+var base64EncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+	},
+})
+
+var startsWithFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}, {Name: "prefix", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.BoolVal(strings.HasPrefix(args[0].AsString(), args[1].AsString())), nil
+	},
+})
+
+var endsWithFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}, {Name: "suffix", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.Bool),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.BoolVal(strings.HasSuffix(args[0].AsString(), args[1].AsString())), nil
+	},
+})
+
+// replaceFunc segue a semântica do Terraform: substr entre barras é tratado como regex
+var replaceFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+		{Name: "substr", Type: cty.String},
+		{Name: "replace", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		str, substr, replacement := args[0].AsString(), args[1].AsString(), args[2].AsString()
+		if len(substr) > 1 && strings.HasPrefix(substr, "/") && strings.HasSuffix(substr, "/") {
+			re, err := regexp.Compile(substr[1 : len(substr)-1])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(re.ReplaceAllString(str, replacement)), nil
+		}
+		return cty.StringVal(strings.ReplaceAll(str, substr, replacement)), nil
+	},
+})
+
+var cidrHostFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "prefix", Type: cty.String}, {Name: "hostnum", Type: cty.Number}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		_, network, err := net.ParseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("prefixo inválido: %w", err)
+		}
+		hostnum, _ := args[1].AsBigFloat().Int64()
+		ones, bits := network.Mask.Size()
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		num := big.NewInt(hostnum)
+		if num.Sign() < 0 {
+			num.Add(num, size)
+		}
+		if num.Sign() < 0 || num.Cmp(size) >= 0 {
+			return cty.NilVal, fmt.Errorf("prefixo %s não comporta o host %d", args[0].AsString(), hostnum)
+		}
+		return cty.StringVal(addToIP(network.IP, num).String()), nil
+	},
+})
+
+var cidrSubnetFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "newbits", Type: cty.Number},
+		{Name: "netnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		_, network, err := net.ParseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("prefixo inválido: %w", err)
+		}
+		newbits, _ := args[1].AsBigFloat().Int64()
+		netnum, _ := args[2].AsBigFloat().Int64()
+		ones, bits := network.Mask.Size()
+		newOnes := ones + int(newbits)
+		if newOnes > bits {
+			return cty.NilVal, fmt.Errorf("não há bits suficientes para estender o prefixo %s em %d", args[0].AsString(), newbits)
+		}
+		if netnum < 0 || netnum >= int64(1)<<uint(newbits) {
+			return cty.NilVal, fmt.Errorf("netnum %d não cabe em %d bits", netnum, newbits)
+		}
+		offset := new(big.Int).Lsh(big.NewInt(netnum), uint(bits-newOnes))
+		subnet := &net.IPNet{IP: addToIP(network.IP, offset), Mask: net.CIDRMask(newOnes, bits)}
+		return cty.StringVal(subnet.String()), nil
+	},
+})
+
+// addToIP soma um deslocamento a um endereço IP
+func addToIP(ip net.IP, offset *big.Int) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	sum := new(big.Int).Add(new(big.Int).SetBytes(ip), offset)
+	out := sum.Bytes()
+	result := make(net.IP, len(ip))
+	copy(result[len(result)-len(out):], out)
+	return result
+}
+
+var yamlEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "value", Type: cty.DynamicPseudoType, AllowNull: true}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		out, err := yaml.Marshal(CtyToGo(args[0]))
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.StringVal(string(out)), nil
+	},
+})
+
+var yamlDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "src", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(args[0].AsString()), &doc); err != nil {
+			return cty.NilVal, err
+		}
+		return GoToCty(doc)
+	},
+})
+
+// CtyToGo converte um valor cty em tipos nativos Go (map, slice, string, número, bool)
+func CtyToGo(val cty.Value) interface{} {
+	val, _ = val.UnmarkDeep()
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString()
+	case ty == cty.Bool:
+		return val.True()
+	case ty == cty.Number:
+		bf := val.AsBigFloat()
+		if bf.IsInt() {
+			if i, acc := bf.Int64(); acc == big.Exact {
+				return int(i)
+			}
+		}
+		f, _ := bf.Float64()
+		return f
+	case ty.IsObjectType() || ty.IsMapType():
+		out := make(map[string]interface{}, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			out[k.AsString()] = CtyToGo(v)
+		}
+		return out
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		out := make([]interface{}, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			out = append(out, CtyToGo(v))
+		}
+		return out
+	}
+	return nil
+}
+
+// GoToCty converte valores Go decodificados de JSON/YAML em um valor cty
+func GoToCty(v interface{}) (cty.Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case string:
+		return cty.StringVal(val), nil
+	case bool:
+		return cty.BoolVal(val), nil
+	case int:
+		return cty.NumberIntVal(int64(val)), nil
+	case int64:
+		return cty.NumberIntVal(val), nil
+	case float64:
+		return cty.NumberFloatVal(val), nil
+	case []interface{}:
+		elems := make([]cty.Value, 0, len(val))
+		for _, item := range val {
+			e, err := GoToCty(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems = append(elems, e)
+		}
+		return cty.TupleVal(elems), nil
+	case map[string]interface{}:
+		attrs := make(map[string]cty.Value, len(val))
+		for k, item := range val {
+			e, err := GoToCty(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = e
+		}
+		return cty.ObjectVal(attrs), nil
+	}
+
+	// Tipos não estruturados (ex: datas do YAML) são tratados como string
+	return cty.StringVal(fmt.Sprint(v)), nil
+}
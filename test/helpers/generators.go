@@ -1,7 +1,10 @@
 package helpers
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -88,7 +91,7 @@ type Taint struct {
 
 // GenNodeGroup gera configurações válidas de node group
 func GenNodeGroup() gopter.Gen {
-	return gen.Struct(reflect.TypeOf(&NodeGroupConfig{}), map[string]gopter.Gen{
+	return gen.StructPtr(reflect.TypeOf(&NodeGroupConfig{}), map[string]gopter.Gen{
 		"InstanceTypes": gen.SliceOf(GenInstanceType(), reflect.TypeOf("")),
 		"MinSize":       gen.IntRange(1, 5),
 		"MaxSize":       gen.IntRange(5, 50),
@@ -125,3 +128,269 @@ func GenBool() gopter.Gen {
 func GenPositiveInt(max int) gopter.Gen {
 	return gen.IntRange(1, max)
 }
+
+// regionZones são as regiões geradas e as letras das suas zonas de disponibilidade
+var regionZones = map[string]string{
+	"us-east-1": "abcdef",
+	"us-east-2": "abc",
+	"us-west-2": "abcd",
+	"eu-west-1": "abc",
+	"sa-east-1": "abc",
+}
+
+// GenRegion gera regiões AWS com pelo menos 3 zonas de disponibilidade
+func GenRegion() gopter.Gen {
+	return gen.OneConstOf("us-east-1", "us-east-2", "us-west-2", "eu-west-1", "sa-east-1")
+}
+
+// GenBackupSchedule gera schedules cron diários ou a cada N horas
+func GenBackupSchedule() gopter.Gen {
+	return gopter.CombineGens(
+		gen.IntRange(0, 59),
+		gen.OneConstOf("2", "0", "*/6", "*/12"),
+	).Map(func(values []interface{}) string {
+		return fmt.Sprintf("%d %s * * *", values[0], values[1])
+	})
+}
+
+// GenBucketName gera nomes de bucket S3 válidos (3-63 caracteres minúsculos)
+func GenBucketName() gopter.Gen {
+	return gen.RegexMatch(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`)
+}
+
+// GenSystemNodeGroup gera o node group system: label role=system e taint
+// CriticalAddonsOnly, tolerados pelos add-ons, e max_size - min_size ≤ 3 (6.7)
+func GenSystemNodeGroup() gopter.Gen {
+	return gopter.CombineGens(
+		gen.SliceOf(GenInstanceType()).SuchThat(func(types []string) bool { return len(types) > 0 }),
+		gen.IntRange(1, 4),
+		gen.IntRange(0, 3),
+		gen.IntRange(0, 3),
+		gen.IntRange(20, 200),
+	).Map(func(values []interface{}) *NodeGroupConfig {
+		minSize, growth, extra := values[1].(int), values[2].(int), values[3].(int)
+		if extra > growth {
+			extra = growth
+		}
+		return &NodeGroupConfig{
+			InstanceTypes: values[0].([]string),
+			MinSize:       minSize,
+			MaxSize:       minSize + growth,
+			DesiredSize:   minSize + extra,
+			DiskSize:      values[4].(int),
+			Labels:        map[string]string{"role": "system"},
+			Taints:        []Taint{systemTaint},
+		}
+	})
+}
+
+// systemTaint é o taint do node group system tolerado pelos add-ons
+var systemTaint = Taint{Key: "CriticalAddonsOnly", Value: "true", Effect: "NoSchedule"}
+
+// EnvironmentConfig é a configuração completa de um ambiente: variáveis do
+// terraform.tfvars e argumentos escritos como literais no main.tf (engine de
+// políticas, tipo de ingress, retenções e backup)
+type EnvironmentConfig struct {
+	Region                       string
+	AvailabilityZones            []string
+	VPCCIDR                      string
+	SingleNATGateway             bool
+	EnableVPCEndpoints           bool
+	NodeGroups                   map[string]*NodeGroupConfig
+	PolicyEngine                 string
+	EnforcementMode              string
+	IngressType                  string
+	ControlPlaneLogRetentionDays int
+	PrometheusRetentionDays      int
+	LokiRetentionDays            int
+	BackupSchedule               string
+	BackupRetentionDays          int
+}
+
+// GenEnvironmentConfig gera configurações completas de ambiente que respeitam as
+// restrições entre campos dos módulos: AZs da região escolhida, node group system
+// com o label e o taint esperados pelos add-ons, node group apps sem taints (onde
+// rodam os DaemonSets que não toleram o taint do system, como o node-exporter) e
+// node groups de aplicação com role igual ao nome. Usa ShrinkEnvironmentConfig
+// para reduzir contraexemplos
+func GenEnvironmentConfig() gopter.Gen {
+	return gopter.CombineGens(
+		GenRegion(),
+		GenAZCount(),
+		GenVPCCIDR(),
+		gen.Bool(),
+		gen.Bool(),
+		GenSystemNodeGroup(),
+		GenNodeGroup(),
+		gen.IntRange(0, 2),
+		gen.SliceOfN(2, GenNodeGroup()),
+		GenPolicyEngine(),
+		GenEnforcementMode(),
+		GenIngressType(),
+		GenRetentionDays(),
+		GenRetentionDays(),
+		GenRetentionDays(),
+		GenBackupSchedule(),
+		GenRetentionDays(),
+	).Map(func(values []interface{}) *EnvironmentConfig {
+		region := values[0].(string)
+		apps := values[6].(*NodeGroupConfig)
+		apps.Taints = nil
+		names := append([]string{"apps"}, extraNodeGroups[:values[7].(int)]...)
+		extras := append([]*NodeGroupConfig{apps}, values[8].([]*NodeGroupConfig)...)
+		groups := map[string]*NodeGroupConfig{"system": values[5].(*NodeGroupConfig)}
+		for i, name := range names {
+			group := extras[i]
+			if group.Labels == nil {
+				group.Labels = make(map[string]string)
+			}
+			group.Labels["role"] = name
+			groups[name] = group
+		}
+		return &EnvironmentConfig{
+			Region:                       region,
+			AvailabilityZones:            availabilityZones(region, values[1].(int)),
+			VPCCIDR:                      values[2].(string),
+			SingleNATGateway:             values[3].(bool),
+			EnableVPCEndpoints:           values[4].(bool),
+			NodeGroups:                   groups,
+			PolicyEngine:                 values[9].(string),
+			EnforcementMode:              values[10].(string),
+			IngressType:                  values[11].(string),
+			ControlPlaneLogRetentionDays: values[12].(int),
+			PrometheusRetentionDays:      values[13].(int),
+			LokiRetentionDays:            values[14].(int),
+			BackupSchedule:               values[15].(string),
+			BackupRetentionDays:          values[16].(int),
+		}
+	}).WithShrinker(ShrinkEnvironmentConfig)
+}
+
+// extraNodeGroups são os nomes dos node groups de aplicação além de apps
+var extraNodeGroups = []string{"batch", "data"}
+
+// availabilityZones retorna as primeiras n zonas da região, limitadas às existentes
+func availabilityZones(region string, n int) []string {
+	letters := regionZones[region]
+	if n > len(letters) {
+		n = len(letters)
+	}
+	zones := make([]string, n)
+	for i := range zones {
+		zones[i] = region + letters[i:i+1]
+	}
+	return zones
+}
+
+// ShrinkEnvironmentConfig reduz uma configuração de ambiente: cada candidato
+// desfaz uma única escolha, em direção a 2 AZs, só os node groups system e apps
+// com o mínimo de nodes e os valores de staging, sem quebrar as restrições de
+// GenEnvironmentConfig. O contraexemplo mínimo mantém só o que causa a falha
+func ShrinkEnvironmentConfig(v interface{}) gopter.Shrink {
+	c := v.(*EnvironmentConfig)
+	var candidates []interface{}
+	try := func(change func(*EnvironmentConfig)) {
+		next := c.clone()
+		change(next)
+		if !reflect.DeepEqual(next, c) {
+			candidates = append(candidates, next)
+		}
+	}
+
+	try(func(n *EnvironmentConfig) {
+		n.Region = "us-east-1"
+		n.AvailabilityZones = availabilityZones(n.Region, len(c.AvailabilityZones))
+	})
+	if len(c.AvailabilityZones) > 2 {
+		try(func(n *EnvironmentConfig) { n.AvailabilityZones = n.AvailabilityZones[:len(n.AvailabilityZones)-1] })
+	}
+	try(func(n *EnvironmentConfig) { n.VPCCIDR = "10.0.0.0/16" })
+	try(func(n *EnvironmentConfig) { n.SingleNATGateway = true })
+	try(func(n *EnvironmentConfig) { n.EnableVPCEndpoints = true })
+
+	for _, name := range c.nodeGroupNames() {
+		name := name
+		if name != "system" && name != "apps" {
+			try(func(n *EnvironmentConfig) { delete(n.NodeGroups, name) })
+		}
+		try(func(n *EnvironmentConfig) { n.NodeGroups[name].InstanceTypes = n.NodeGroups[name].InstanceTypes[:1] })
+		try(func(n *EnvironmentConfig) { n.NodeGroups[name].Labels = map[string]string{"role": name} })
+		if name != "system" {
+			try(func(n *EnvironmentConfig) { n.NodeGroups[name].Taints = nil })
+		}
+		try(func(n *EnvironmentConfig) {
+			g := n.NodeGroups[name]
+			g.MinSize, g.DesiredSize, g.MaxSize = 1, 1, 1
+		})
+		try(func(n *EnvironmentConfig) { n.NodeGroups[name].MaxSize = n.NodeGroups[name].DesiredSize })
+		try(func(n *EnvironmentConfig) { n.NodeGroups[name].DiskSize = 20 })
+	}
+
+	try(func(n *EnvironmentConfig) { n.PolicyEngine = "kyverno" })
+	try(func(n *EnvironmentConfig) { n.EnforcementMode = "audit" })
+	try(func(n *EnvironmentConfig) { n.IngressType = "alb" })
+	try(func(n *EnvironmentConfig) { n.ControlPlaneLogRetentionDays = 7 })
+	try(func(n *EnvironmentConfig) { n.PrometheusRetentionDays = 7 })
+	try(func(n *EnvironmentConfig) { n.LokiRetentionDays = 3 })
+	try(func(n *EnvironmentConfig) { n.BackupSchedule = "0 2 * * *" })
+	try(func(n *EnvironmentConfig) { n.BackupRetentionDays = 7 })
+
+	return func() (interface{}, bool) {
+		if len(candidates) == 0 {
+			return nil, false
+		}
+		next := candidates[0]
+		candidates = candidates[1:]
+		return next, true
+	}
+}
+
+// clone copia a configuração, incluindo node groups, labels e taints
+func (c *EnvironmentConfig) clone() *EnvironmentConfig {
+	n := *c
+	n.AvailabilityZones = append([]string(nil), c.AvailabilityZones...)
+	n.NodeGroups = make(map[string]*NodeGroupConfig, len(c.NodeGroups))
+	for name, group := range c.NodeGroups {
+		g := *group
+		g.InstanceTypes = append([]string(nil), group.InstanceTypes...)
+		g.Taints = append([]Taint(nil), group.Taints...)
+		g.Labels = make(map[string]string, len(group.Labels))
+		for key, value := range group.Labels {
+			g.Labels[key] = value
+		}
+		n.NodeGroups[name] = &g
+	}
+	return &n
+}
+
+// nodeGroupNames retorna os nomes dos node groups em ordem
+func (c *EnvironmentConfig) nodeGroupNames() []string {
+	names := make([]string, 0, len(c.NodeGroups))
+	for name := range c.NodeGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String resume a configuração em uma linha, usada nos contraexemplos do gopter
+func (c *EnvironmentConfig) String() string {
+	groups := make([]string, 0, len(c.NodeGroups))
+	for _, name := range c.nodeGroupNames() {
+		g := c.NodeGroups[name]
+		var taints []string
+		for _, t := range g.Taints {
+			taints = append(taints, fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect))
+		}
+		groups = append(groups, fmt.Sprintf("%s: %v %d/%d/%d %dGB labels=%v taints=%v",
+			name, g.InstanceTypes, g.MinSize, g.DesiredSize, g.MaxSize, g.DiskSize, g.Labels, taints))
+	}
+	nat := "por AZ"
+	if c.SingleNATGateway {
+		nat = "único"
+	}
+	return fmt.Sprintf("%s %v %s, NAT %s, endpoints=%t; node groups {%s}; %s/%s; ingress %s; retenção logs %dd, prometheus %dd, loki %dd; backup %q por %dd",
+		c.Region, c.AvailabilityZones, c.VPCCIDR, nat, c.EnableVPCEndpoints, strings.Join(groups, "; "),
+		c.PolicyEngine, c.EnforcementMode, c.IngressType, c.ControlPlaneLogRetentionDays,
+		c.PrometheusRetentionDays, c.LokiRetentionDays, c.BackupSchedule, c.BackupRetentionDays)
+}
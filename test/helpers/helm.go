@@ -0,0 +1,231 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HelmRelease representa um helm_release avaliado, com o documento de values final
+type HelmRelease struct {
+	Address string
+	Chart   string
+	Version string
+	Values  map[string]interface{}
+	// File e Line localizam o bloco helm_release no módulo
+	File string
+	Line int
+}
+
+// HelmReleases filtra as instâncias helm_release e renderiza seus values
+func HelmReleases(instances []*ResourceInstance) ([]*HelmRelease, error) {
+	var releases []*HelmRelease
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "helm_release" {
+			continue
+		}
+		release, err := RenderHelmRelease(inst)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// RenderHelmRelease monta o documento de values final de um helm_release, da
+// mesma forma que o provider Helm: cada item de `values` é mesclado em ordem
+// e os blocos `set`/`set_sensitive` são aplicados por último
+func RenderHelmRelease(inst *ResourceInstance) (*HelmRelease, error) {
+	if inst.Diagnostics.HasErrors() {
+		return nil, fmt.Errorf("%s: erro ao avaliar recurso: %s", inst.Address(), inst.Diagnostics.Error())
+	}
+
+	attrs := inst.Values()
+	release := &HelmRelease{
+		Address: inst.Address(),
+		Values:  make(map[string]interface{}),
+		File:    inst.Resource.Range.Filename,
+		Line:    inst.Resource.Range.Start.Line,
+	}
+	release.Chart, _ = attrs["chart"].(string)
+	release.Version, _ = attrs["version"].(string)
+
+	documents, _ := attrs["values"].([]interface{})
+	for i, doc := range documents {
+		text, ok := doc.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: values[%d] não é uma string", release.Address, i)
+		}
+		var parsed map[string]interface{}
+		if err := yaml.Unmarshal([]byte(text), &parsed); err != nil {
+			return nil, fmt.Errorf("%s: values[%d] não é um YAML válido: %w", release.Address, i, err)
+		}
+		release.Values = mergeHelmValues(release.Values, parsed)
+	}
+
+	for _, blockType := range []string{"set", "set_sensitive"} {
+		blocks, _ := attrs[blockType].([]interface{})
+		for _, block := range blocks {
+			set, _ := block.(map[string]interface{})
+			name, _ := set["name"].(string)
+			valueType, _ := set["type"].(string)
+			value := fmt.Sprint(set["value"])
+			if err := setHelmValue(release.Values, name, parseHelmSetValue(value, valueType)); err != nil {
+				return nil, fmt.Errorf("%s: %s %q: %w", release.Address, blockType, name, err)
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// mergeHelmValues mescla src sobre dst recursivamente (mapas são mesclados, demais valores substituídos)
+func mergeHelmValues(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			dst[key] = mergeHelmValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// parseHelmSetValue converte o valor de um bloco set como o `helm --set`:
+// com type = "string" o valor é mantido, caso contrário booleanos, inteiros e null são reconhecidos
+func parseHelmSetValue(value, valueType string) interface{} {
+	if valueType == "string" {
+		return value
+	}
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return int(i)
+	}
+	return value
+}
+
+// setHelmValue aplica um valor em um caminho no formato do `helm --set`
+// (ex: controller.nodeSelector.role, tolerations[0].key, annotations.eks\.amazonaws\.com/role-arn)
+func setHelmValue(values map[string]interface{}, name string, value interface{}) error {
+	keys, err := splitHelmPath(name)
+	if err != nil {
+		return err
+	}
+	if keys[0].index >= 0 {
+		return fmt.Errorf("o caminho não pode começar por um índice")
+	}
+	_, err = setHelmNode(values, keys, value)
+	return err
+}
+
+// setHelmNode aplica o valor no nó e retorna o nó atualizado (listas podem ser realocadas)
+func setHelmNode(node interface{}, keys []helmPathKey, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	key := keys[0]
+
+	if key.index < 0 {
+		m, ok := node.(map[string]interface{})
+		if node == nil {
+			m, ok = make(map[string]interface{}), true
+		}
+		if !ok {
+			return nil, fmt.Errorf("chave %q aplicada a um valor que não é mapa", key.name)
+		}
+		child, err := setHelmNode(m[key.name], keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		m[key.name] = child
+		return m, nil
+	}
+
+	list, ok := node.([]interface{})
+	if node == nil {
+		ok = true
+	}
+	if !ok {
+		return nil, fmt.Errorf("índice [%d] aplicado a um valor que não é lista", key.index)
+	}
+	for len(list) <= key.index {
+		list = append(list, nil)
+	}
+	child, err := setHelmNode(list[key.index], keys[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	list[key.index] = child
+	return list, nil
+}
+
+// helmPathKey é um segmento de caminho do `helm --set` (chave de mapa ou índice de lista)
+type helmPathKey struct {
+	name  string
+	index int
+}
+
+// splitHelmPath separa um caminho por pontos, respeitando "\." e índices "[n]"
+func splitHelmPath(name string) ([]helmPathKey, error) {
+	var keys []helmPathKey
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			keys = append(keys, helmPathKey{name: current.String(), index: -1})
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; c {
+		case '\\':
+			if i+1 < len(name) {
+				i++
+				current.WriteByte(name[i])
+			}
+		case '.':
+			flush()
+		case '[':
+			flush()
+			end := strings.IndexByte(name[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("índice sem ']' em %q", name)
+			}
+			index, err := strconv.Atoi(name[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("índice inválido em %q", name)
+			}
+			keys = append(keys, helmPathKey{index: index})
+			i += end
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("caminho vazio")
+	}
+	return keys, nil
+}
+
+// YAML retorna o documento de values final renderizado como YAML
+func (r *HelmRelease) YAML() (string, error) {
+	out, err := yaml.Marshal(r.Values)
+	if err != nil {
+		return "", fmt.Errorf("%s: erro ao renderizar values: %w", r.Address, err)
+	}
+	return string(out), nil
+}
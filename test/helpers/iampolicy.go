@@ -0,0 +1,304 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IAMGrant é uma permissão de uma policy IAM: uma ação sobre um recurso, com as
+// condições do statement no formato "Operador chave=valores"
+type IAMGrant struct {
+	Action     string
+	Resource   string
+	Conditions []string
+}
+
+func (g *IAMGrant) String() string {
+	if len(g.Conditions) == 0 {
+		return fmt.Sprintf("%s em %s", g.Action, g.Resource)
+	}
+	return fmt.Sprintf("%s em %s se %s", g.Action, g.Resource, strings.Join(g.Conditions, " e "))
+}
+
+// covers indica se a permissão g concede pelo menos o mesmo que other: a ação e o
+// recurso de g (com curingas) casam com os de other e as condições de g são um
+// subconjunto das de other
+func (g *IAMGrant) covers(other *IAMGrant) bool {
+	if !iamWildcard(g.Action).MatchString(other.Action) || !iamWildcard(g.Resource).MatchString(other.Resource) {
+		return false
+	}
+	for _, condition := range g.Conditions {
+		if !containsCondition(other.Conditions, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsCondition(conditions []string, condition string) bool {
+	for _, c := range conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// iamWildcard converte um padrão IAM (* e ?) em expressão regular; ações não
+// diferenciam maiúsculas de minúsculas
+func iamWildcard(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("(?i)^" + quoted + "$")
+}
+
+// IAMPolicyDrift é a diferença entre a policy do módulo e a de referência
+type IAMPolicyDrift struct {
+	// Missing são permissões da referência que a policy do módulo não concede
+	Missing []*IAMGrant
+	// Added são permissões com ações que a referência não usa
+	Added []*IAMGrant
+	// Loosened são permissões com ações da referência mas com recurso mais amplo ou menos condições
+	Loosened []*IAMGrant
+}
+
+// Empty indica se as policies são equivalentes
+func (d *IAMPolicyDrift) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Added) == 0 && len(d.Loosened) == 0
+}
+
+// CompareIAMPolicy compara as permissões da policy do módulo com as da referência
+func CompareIAMPolicy(local, reference []*IAMGrant) *IAMPolicyDrift {
+	drift := &IAMPolicyDrift{}
+	for _, want := range reference {
+		if !anyCovers(local, want) {
+			drift.Missing = append(drift.Missing, want)
+		}
+	}
+	for _, have := range local {
+		if anyCovers(reference, have) {
+			continue
+		}
+		known := false
+		for _, want := range reference {
+			if iamWildcard(have.Action).MatchString(want.Action) {
+				known = true
+				break
+			}
+		}
+		if known {
+			drift.Loosened = append(drift.Loosened, have)
+		} else {
+			drift.Added = append(drift.Added, have)
+		}
+	}
+	return drift
+}
+
+func anyCovers(grants []*IAMGrant, other *IAMGrant) bool {
+	for _, g := range grants {
+		if g.covers(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChartIAMPolicyPath retorna o caminho da policy IAM de referência de um chart/versão
+func GetChartIAMPolicyPath(chart, version string) string {
+	return GetTestPath("testdata", "charts", chart, version, "iam_policy.json")
+}
+
+// iamStatementJSON é um statement de policy IAM em JSON, em que Action, Resource e
+// os valores das condições podem ser string ou lista
+type iamStatementJSON struct {
+	Effect    string                           `json:"Effect"`
+	Action    iamStrings                       `json:"Action"`
+	Resource  iamStrings                       `json:"Resource"`
+	Condition map[string]map[string]iamStrings `json:"Condition"`
+}
+
+type iamStrings []string
+
+func (s *iamStrings) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []string{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// LoadIAMPolicyJSON lê as permissões de um documento de policy IAM em JSON
+func LoadIAMPolicyJSON(path string) ([]*IAMGrant, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Statement []iamStatementJSON `json:"Statement"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var grants []*IAMGrant
+	for i, statement := range doc.Statement {
+		if statement.Effect != "Allow" {
+			return nil, fmt.Errorf("%s: Statement[%d]: apenas Effect Allow é suportado", path, i)
+		}
+		var conditions []string
+		for test, variables := range statement.Condition {
+			for variable, values := range variables {
+				conditions = append(conditions, iamCondition(test, variable, values))
+			}
+		}
+		grants = append(grants, iamGrants(statement.Action, statement.Resource, conditions)...)
+	}
+	return grants, nil
+}
+
+// PolicyDocumentGrants extrai as permissões de um data aws_iam_policy_document avaliado
+func PolicyDocumentGrants(values map[string]interface{}) ([]*IAMGrant, error) {
+	statements, _ := values["statement"].([]interface{})
+	var grants []*IAMGrant
+	for i, s := range statements {
+		statement, _ := s.(map[string]interface{})
+		if effect, ok := statement["effect"].(string); ok && effect != "Allow" {
+			return nil, fmt.Errorf("statement[%d]: apenas effect Allow é suportado", i)
+		}
+		if statement["not_actions"] != nil || statement["not_resources"] != nil {
+			return nil, fmt.Errorf("statement[%d]: not_actions e not_resources não são suportados", i)
+		}
+		var conditions []string
+		blocks, _ := statement["condition"].([]interface{})
+		for _, b := range blocks {
+			block, _ := b.(map[string]interface{})
+			test, _ := block["test"].(string)
+			variable, _ := block["variable"].(string)
+			conditions = append(conditions, iamCondition(test, variable, stringList(block["values"])))
+		}
+		grants = append(grants, iamGrants(stringList(statement["actions"]), stringList(statement["resources"]), conditions)...)
+	}
+	return grants, nil
+}
+
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		list = append(list, fmt.Sprint(item))
+	}
+	return list
+}
+
+// iamCondition formata uma condição com os valores ordenados
+func iamCondition(test, variable string, values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("%s %s=%s", test, variable, strings.Join(sorted, ","))
+}
+
+// iamGrants expande um statement em uma permissão por ação e recurso
+func iamGrants(actions, resources, conditions []string) []*IAMGrant {
+	sort.Strings(conditions)
+	if len(resources) == 0 {
+		resources = []string{"*"}
+	}
+	var grants []*IAMGrant
+	for _, action := range actions {
+		for _, resource := range resources {
+			grants = append(grants, &IAMGrant{Action: action, Resource: resource, Conditions: conditions})
+		}
+	}
+	return grants
+}
+
+// IAMPolicyComparison associa um helm_release com policy de referência vendorizada
+// ao aws_iam_policy_document de mesmo nome no módulo
+type IAMPolicyComparison struct {
+	Release  string
+	Chart    string
+	Version  string
+	Document string
+	Drift    *IAMPolicyDrift
+	// ReferenceError indica que a policy de referência da versão não está vendorizada
+	ReferenceError error
+	File           string
+	Line           int
+}
+
+// iamPolicyCharts são os charts cuja policy IRSA é sempre comparada com a de
+// referência da versão instalada
+var iamPolicyCharts = []string{"aws-load-balancer-controller"}
+
+// CompareChartIAMPolicies compara, para cada helm_release cujo chart/versão tem
+// iam_policy.json em testdata/charts, o data aws_iam_policy_document de mesmo nome
+// no mesmo módulo com a policy de referência; releases de iamPolicyCharts sem a
+// referência da versão instalada trazem ReferenceError
+func CompareChartIAMPolicies(instances []*ResourceInstance) ([]*IAMPolicyComparison, error) {
+	documents := make(map[string]*ResourceInstance)
+	for _, inst := range instances {
+		if inst.Resource.Mode == "data" && inst.Resource.Type == "aws_iam_policy_document" {
+			documents[inst.Module+"."+inst.Resource.Name] = inst
+		}
+	}
+
+	var comparisons []*IAMPolicyComparison
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "helm_release" {
+			continue
+		}
+		values := inst.Values()
+		chart, _ := values["chart"].(string)
+		version, _ := values["version"].(string)
+		path := GetChartIAMPolicyPath(chart, version)
+		document, ok := documents[inst.Module+"."+inst.Resource.Name]
+		if _, err := os.Stat(path); err != nil {
+			if !containsString(iamPolicyCharts, chart) {
+				continue
+			}
+			comparison := &IAMPolicyComparison{
+				Release: inst.Address(), Chart: chart, Version: version, Drift: &IAMPolicyDrift{},
+				ReferenceError: fmt.Errorf("policy de referência de %s %s não vendorizada", chart, version),
+				File:           inst.Resource.Range.Filename, Line: inst.Resource.Range.Start.Line,
+			}
+			if ok {
+				comparison.Document = document.Address()
+				comparison.File, comparison.Line = document.Resource.Range.Filename, document.Resource.Range.Start.Line
+			}
+			comparisons = append(comparisons, comparison)
+			continue
+		}
+		reference, err := LoadIAMPolicyJSON(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: data.aws_iam_policy_document.%s não encontrado para comparar com %s",
+				inst.Address(), inst.Resource.Name, path)
+		}
+		if document.Diagnostics.HasErrors() {
+			return nil, fmt.Errorf("%s: erro ao avaliar recurso: %s", document.Address(), document.Diagnostics.Error())
+		}
+		local, err := PolicyDocumentGrants(document.Values())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", document.Address(), err)
+		}
+		comparisons = append(comparisons, &IAMPolicyComparison{
+			Release: inst.Address(), Chart: chart, Version: version, Document: document.Address(),
+			Drift: CompareIAMPolicy(local, reference),
+			File:  document.Resource.Range.Filename, Line: document.Resource.Range.Start.Line,
+		})
+	}
+	return comparisons, nil
+}
@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// Anotações de Ingress verificadas nos exemplos
+const (
+	ingressClassAnnotation     = "kubernetes.io/ingress.class"
+	clusterIssuerAnnotation    = "cert-manager.io/cluster-issuer"
+	namespacedIssuerAnnotation = "cert-manager.io/issuer"
+	controllerAnnotationInfix  = ".ingress.kubernetes.io/"
+)
+
+// ingressPathRegex casa com caracteres de expressão regular em paths do Ingress
+var ingressPathRegex = regexp.MustCompile(`[()|$^*+?\[\]{}\\]`)
+
+// GetKubernetesSchemaPath retorna o caminho do schema de um kind Kubernetes
+func GetKubernetesSchemaPath(apiVersion, kind string) string {
+	return GetTestPath(append(append([]string{"testdata", "kubernetes"}, strings.Split(apiVersion, "/")...), kind+".schema.json")...)
+}
+
+// GetIngressExamplesPath retorna o diretório de exemplos de Ingress do módulo ingress
+func GetIngressExamplesPath() string {
+	return filepath.Join(GetModulePath("platform/ingress"), "examples")
+}
+
+// KubernetesObject é um documento de um YAML de exemplo com o conteúdo completo
+type KubernetesObject struct {
+	Object map[string]interface{}
+	File   string
+	Line   int
+}
+
+// Kind retorna o kind do objeto
+func (o *KubernetesObject) Kind() string {
+	kind, _ := o.Object["kind"].(string)
+	return kind
+}
+
+// Name retorna metadata.name do objeto
+func (o *KubernetesObject) Name() string {
+	name, _ := lookupHelmValue(o.Object, "metadata.name").(string)
+	return name
+}
+
+// YAMLObjects lê os objetos Kubernetes de um arquivo YAML com múltiplos documentos
+func YAMLObjects(path string) ([]*KubernetesObject, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo %s: %w", path, err)
+	}
+
+	var objects []*KubernetesObject
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("erro ao parsear YAML %s: %w", path, err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		var object map[string]interface{}
+		if err := doc.Content[0].Decode(&object); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, doc.Content[0].Line, err)
+		}
+		objects = append(objects, &KubernetesObject{Object: object, File: path, Line: doc.Content[0].Line})
+	}
+	return objects, nil
+}
+
+// IngressContext é o que o módulo ingress cria em um ambiente com um tipo de
+// controller: a IngressClass, o ClusterIssuer e o domínio do external-dns
+type IngressContext struct {
+	Env          string
+	IngressType  string
+	IngressClass string
+	IssuerName   string
+	DomainName   string
+}
+
+// NewIngressContext avalia o ambiente com ingress_type substituído por
+// ingressType no módulo que declara essa variável
+func NewIngressContext(env, ingressType string) (*IngressContext, error) {
+	ev, err := NewEnvironmentEvaluator(env)
+	if err != nil {
+		return nil, err
+	}
+	for name, call := range ev.Module.ModuleCalls {
+		mod, err := LoadModule(filepath.Join(ev.Module.Dir, call.Source))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := mod.Variables["ingress_type"]; ok {
+			if err := ev.OverrideModuleArgument(name, "ingress_type", cty.StringVal(ingressType)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	issuers, err := ClusterIssuers(env, ev)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := ExternalDNSScopes(env, ev)
+	if err != nil {
+		return nil, err
+	}
+	if len(issuers) != 1 || len(scopes) != 1 {
+		return nil, fmt.Errorf("%s: esperado um ClusterIssuer e um external-dns, encontrados %d e %d", env, len(issuers), len(scopes))
+	}
+	return &IngressContext{
+		Env: env, IngressType: ingressType, IngressClass: issuers[0].IngressClass,
+		IssuerName: issuers[0].Name, DomainName: scopes[0].DomainName,
+	}, nil
+}
+
+// coversHost indica se o host está sob o domínio do contexto
+func (c *IngressContext) coversHost(host string) bool {
+	host = strings.TrimPrefix(host, "*.")
+	return host == c.DomainName || strings.HasSuffix(host, "."+c.DomainName)
+}
+
+// ValidateIngress valida um Ingress contra o schema networking.k8s.io/v1 e contra
+// o que o módulo cria no contexto: IngressClass do controller, anotações do
+// controller apenas com a classe correspondente, ClusterIssuer existente e hosts
+// sob o domínio do ambiente
+func ValidateIngress(object *KubernetesObject, schema *Schema, ctx *IngressContext) []string {
+	var problems []string
+	for _, err := range schema.Validate(object.Object) {
+		problems = append(problems, err.Error())
+	}
+
+	class, _ := lookupHelmValue(object.Object, "spec.ingressClassName").(string)
+	if class != ctx.IngressClass {
+		problems = append(problems, fmt.Sprintf("spec.ingressClassName %q; o controller %s atende %q", class, ctx.IngressType, ctx.IngressClass))
+	}
+
+	annotations := stringMap(lookupHelmValue(object.Object, "metadata.annotations"))
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch {
+		case key == ingressClassAnnotation:
+			problems = append(problems, fmt.Sprintf("anotação %s é rejeitada pela API junto com spec.ingressClassName", key))
+		case key == namespacedIssuerAnnotation:
+			problems = append(problems, fmt.Sprintf("anotação %s referencia um Issuer; o módulo cria o ClusterIssuer %q", key, ctx.IssuerName))
+		case key == clusterIssuerAnnotation && annotations[key] != ctx.IssuerName:
+			problems = append(problems, fmt.Sprintf("anotação %s=%q; o módulo cria o ClusterIssuer %q", key, annotations[key], ctx.IssuerName))
+		case strings.Contains(key, controllerAnnotationInfix):
+			if prefix := strings.SplitN(key, ".", 2)[0]; prefix != class {
+				problems = append(problems, fmt.Sprintf("anotação %s exige ingressClassName %s", key, prefix))
+			}
+		}
+	}
+
+	var hosts []string
+	rules, _ := lookupHelmValue(object.Object, "spec.rules").([]interface{})
+	for i, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		if host, ok := rule["host"].(string); ok {
+			hosts = append(hosts, host)
+		}
+		paths, _ := lookupHelmValue(rule, "http.paths").([]interface{})
+		for j, p := range paths {
+			entry, _ := p.(map[string]interface{})
+			path, _ := entry["path"].(string)
+			if entry["pathType"] != "ImplementationSpecific" && ingressPathRegex.MatchString(path) {
+				problems = append(problems, fmt.Sprintf("spec.rules[%d].http.paths[%d]: path %q é uma expressão regular e exige pathType ImplementationSpecific", i, j, path))
+			}
+		}
+	}
+	tls, _ := lookupHelmValue(object.Object, "spec.tls").([]interface{})
+	for _, t := range tls {
+		entry, _ := t.(map[string]interface{})
+		hosts = append(hosts, stringList(entry["hosts"])...)
+	}
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		if !ctx.coversHost(host) {
+			problems = append(problems, fmt.Sprintf("host %s fora do domínio %s do %s", host, ctx.DomainName, ctx.Env))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,401 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// KubernetesManifest identifica um objeto Kubernetes declarado no repositório,
+// seja em um recurso kubernetes_manifest ou em um YAML de exemplo
+type KubernetesManifest struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	// Source é o endereço do recurso ou o índice do documento no YAML
+	Source string
+	File   string
+	Line   int
+}
+
+// APIRemoval descreve uma versão de API removida do Kubernetes
+type APIRemoval struct {
+	GroupVersion string
+	Kind         string
+	RemovedIn    string
+	Replacement  string
+}
+
+// APIFinding associa um manifesto a uma remoção de API que o afeta
+type APIFinding struct {
+	Manifest *KubernetesManifest
+	Removal  APIRemoval
+	// AlreadyRemoved indica que a API já não existe na versão atual do cluster
+	AlreadyRemoved bool
+}
+
+func (f *APIFinding) String() string {
+	return fmt.Sprintf("%s:%d %s %s/%s (%s): removido em %s, migrar para %s",
+		f.Manifest.File, f.Manifest.Line, f.Manifest.Source, f.Manifest.APIVersion,
+		f.Manifest.Kind, f.Manifest.Name, f.Removal.RemovedIn, f.Removal.Replacement)
+}
+
+// apiRemovals é a tabela de APIs removidas por versão do Kubernetes
+// (https://kubernetes.io/docs/reference/using-api/deprecation-guide/)
+var apiRemovals = []APIRemoval{
+	{"extensions/v1beta1", "Ingress", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.22", "networking.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.22", "apiregistration.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", "1.22", "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", "1.22", "authorization.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.22", "coordination.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.25", "Pod Security Admission"},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.25", "node.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.26", "autoscaling/v2"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// APIRemovals retorna a tabela de remoções de API conhecidas
+func APIRemovals() []APIRemoval {
+	return append([]APIRemoval(nil), apiRemovals...)
+}
+
+// CRDRemoval descreve uma versão de API de CRDs que deixa de ser servida a partir
+// de uma versão do chart que instala os CRDs
+type CRDRemoval struct {
+	Chart string
+	// RemovedIn é a primeira versão do chart que não serve a API
+	RemovedIn    string
+	GroupVersion string
+	Kinds        []string
+	Replacement  string
+}
+
+// crdRemovals é a tabela de APIs de CRDs removidas por versão do chart, conforme
+// as notas de upgrade de cada projeto. Velero (velero.io/v1), Argo CD
+// (argoproj.io/v1alpha1), Gatekeeper (templates.gatekeeper.sh/v1) e o Prometheus
+// Operator (monitoring.coreos.com/v1) não removeram as versões usadas aqui.
+var crdRemovals = []CRDRemoval{
+	{"cert-manager", "1.7", "cert-manager.io/v1alpha2", []string{"Certificate", "CertificateRequest", "Issuer", "ClusterIssuer"}, "cert-manager.io/v1"},
+	{"cert-manager", "1.7", "cert-manager.io/v1alpha3", []string{"Certificate", "CertificateRequest", "Issuer", "ClusterIssuer"}, "cert-manager.io/v1"},
+	{"cert-manager", "1.7", "cert-manager.io/v1beta1", []string{"Certificate", "CertificateRequest", "Issuer", "ClusterIssuer"}, "cert-manager.io/v1"},
+	{"cert-manager", "1.7", "acme.cert-manager.io/v1alpha2", []string{"Order", "Challenge"}, "acme.cert-manager.io/v1"},
+	{"cert-manager", "1.7", "acme.cert-manager.io/v1alpha3", []string{"Order", "Challenge"}, "acme.cert-manager.io/v1"},
+	{"cert-manager", "1.7", "acme.cert-manager.io/v1beta1", []string{"Order", "Challenge"}, "acme.cert-manager.io/v1"},
+	{"external-secrets", "0.17", "external-secrets.io/v1alpha1", []string{"ExternalSecret", "SecretStore", "ClusterSecretStore"}, "external-secrets.io/v1"},
+	{"kyverno", "3.0", "kyverno.io/v1alpha2", []string{"ReportChangeRequest", "ClusterReportChangeRequest"}, "reports.kyverno.io/v1"},
+}
+
+// CRDRemovalTable retorna a tabela de remoções de APIs de CRDs conhecidas
+func CRDRemovalTable() []CRDRemoval {
+	return append([]CRDRemoval(nil), crdRemovals...)
+}
+
+// compareChartVersions compara versões major.minor.patch de charts, ignorando o
+// prefixo "v" e sufixos de pré-release; partes ausentes valem 0
+func compareChartVersions(a, b string) int {
+	parts := func(version string) []int {
+		version, _, _ = strings.Cut(strings.TrimPrefix(version, "v"), "-")
+		var numbers []int
+		for _, part := range strings.Split(version, ".") {
+			n, _ := strconv.Atoi(part)
+			numbers = append(numbers, n)
+		}
+		return numbers
+	}
+	pa, pb := parts(a), parts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// FindCRDRemovals retorna os manifestos que usam APIs de CRDs do chart removidas
+// até a versão alvo do chart. Remoções em versões até a instalada são marcadas
+// como AlreadyRemoved.
+func FindCRDRemovals(manifests []*KubernetesManifest, chart, current, target string) []*APIFinding {
+	var findings []*APIFinding
+	for _, manifest := range manifests {
+		for _, removal := range crdRemovals {
+			if removal.Chart != chart || removal.GroupVersion != manifest.APIVersion || !containsString(removal.Kinds, manifest.Kind) {
+				continue
+			}
+			if compareChartVersions(removal.RemovedIn, target) > 0 {
+				continue
+			}
+			findings = append(findings, &APIFinding{
+				Manifest: manifest,
+				Removal: APIRemoval{
+					GroupVersion: removal.GroupVersion,
+					Kind:         manifest.Kind,
+					RemovedIn:    chart + " " + removal.RemovedIn,
+					Replacement:  removal.Replacement,
+				},
+				AlreadyRemoved: compareChartVersions(removal.RemovedIn, current) <= 0,
+			})
+		}
+	}
+	return findings
+}
+
+// ParseMinorVersion extrai o minor de uma versão Kubernetes no formato 1.x
+func ParseMinorVersion(version string) (int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return 0, fmt.Errorf("versão Kubernetes inválida: %q", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("versão Kubernetes inválida: %q", version)
+	}
+	return minor, nil
+}
+
+// FindAPIRemovals retorna os manifestos que usam APIs removidas até a versão alvo.
+// Remoções em versões até a atual são marcadas como AlreadyRemoved.
+func FindAPIRemovals(manifests []*KubernetesManifest, current, target string) ([]*APIFinding, error) {
+	currentMinor, err := ParseMinorVersion(current)
+	if err != nil {
+		return nil, err
+	}
+	targetMinor, err := ParseMinorVersion(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*APIFinding
+	for _, manifest := range manifests {
+		for _, removal := range apiRemovals {
+			if removal.GroupVersion != manifest.APIVersion || removal.Kind != manifest.Kind {
+				continue
+			}
+			removedMinor, err := ParseMinorVersion(removal.RemovedIn)
+			if err != nil {
+				return nil, err
+			}
+			if removedMinor > targetMinor {
+				continue
+			}
+			findings = append(findings, &APIFinding{
+				Manifest:       manifest,
+				Removal:        removal,
+				AlreadyRemoved: removedMinor <= currentMinor,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// ModuleManifests extrai apiVersion/kind/nome dos recursos kubernetes_manifest de um módulo
+func ModuleManifests(mod *Module) []*KubernetesManifest {
+	var manifests []*KubernetesManifest
+	for _, r := range mod.ResourcesOfType("kubernetes_manifest") {
+		attr, ok := r.Body.Attributes["manifest"]
+		if !ok {
+			continue
+		}
+		manifest := &KubernetesManifest{
+			Source: r.Address(),
+			File:   attr.SrcRange.Filename,
+			Line:   attr.SrcRange.Start.Line,
+		}
+		obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
+		if ok {
+			manifest.APIVersion = objectLiteralString(obj, "apiVersion")
+			manifest.Kind = objectLiteralString(obj, "kind")
+			if metadata, ok := objectItem(obj, "metadata").(*hclsyntax.ObjectConsExpr); ok {
+				manifest.Name = objectLiteralString(metadata, "name")
+			}
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests
+}
+
+func objectItem(obj *hclsyntax.ObjectConsExpr, key string) hclsyntax.Expression {
+	for _, item := range obj.Items {
+		k, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || k.Type() != cty.String || k.AsString() != key {
+			continue
+		}
+		return item.ValueExpr
+	}
+	return nil
+}
+
+func objectLiteralString(obj *hclsyntax.ObjectConsExpr, key string) string {
+	expr := objectItem(obj, key)
+	if expr == nil {
+		return ""
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
+
+// YAMLManifests extrai os objetos Kubernetes de um arquivo YAML com múltiplos documentos
+func YAMLManifests(path string) ([]*KubernetesManifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo %s: %w", path, err)
+	}
+
+	var manifests []*KubernetesManifest
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for index := 0; ; index++ {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("erro ao parsear YAML %s: %w", path, err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+		manifest := &KubernetesManifest{
+			Source: fmt.Sprintf("documento %d", index),
+			File:   path,
+			Line:   root.Line,
+		}
+		manifest.APIVersion = yamlScalar(root, "apiVersion")
+		manifest.Kind = yamlScalar(root, "kind")
+		if metadata := yamlChild(root, "metadata"); metadata != nil {
+			manifest.Name = yamlScalar(metadata, "name")
+		}
+		if manifest.APIVersion == "" && manifest.Kind == "" {
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+func yamlChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlScalar(node *yaml.Node, key string) string {
+	child := yamlChild(node, key)
+	if child == nil || child.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return child.Value
+}
+
+// RepositoryManifests coleta os objetos Kubernetes de todos os módulos e dos
+// exemplos em modules/platform/*/examples
+func RepositoryManifests() ([]*KubernetesManifest, error) {
+	modulesRoot := GetModulesPath()
+
+	dirs, err := ModuleDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []*KubernetesManifest
+	for _, dir := range dirs {
+		mod, err := LoadModule(dir)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, ModuleManifests(mod)...)
+	}
+
+	var examples []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(modulesRoot, "platform", "*", "examples", pattern))
+		if err != nil {
+			return nil, err
+		}
+		examples = append(examples, matches...)
+	}
+	sort.Strings(examples)
+
+	for _, path := range examples {
+		found, err := YAMLManifests(path)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, found...)
+	}
+	return manifests, nil
+}
+
+// ModuleDirs retorna os diretórios de modules/ que contêm arquivos .tf, em ordem
+func ModuleDirs() ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(GetModulesPath(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		tfFiles, _ := filepath.Glob(filepath.Join(path, "*.tf"))
+		if len(tfFiles) > 0 {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
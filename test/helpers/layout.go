@@ -0,0 +1,253 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LayoutFile marca a raiz do repositório e descreve onde ficam módulos,
+	// ambientes, testes e spec
+	LayoutFile = ".template-layout.yaml"
+	// ProjectRootEnv fixa a raiz do repositório sem procurar pelo marcador
+	ProjectRootEnv = "TEMPLATE_ROOT"
+)
+
+// Layout descreve a organização do repositório. Os caminhos são relativos à raiz,
+// o que permite usar os testes em um repositório que copia este template para
+// outra estrutura de diretórios.
+type Layout struct {
+	// Modules é o diretório dos módulos reutilizáveis
+	Modules string `yaml:"modules"`
+	// Environments contém um diretório por nuvem e, dentro dele, um por ambiente
+	// (ex: live/aws/staging, live/gcp/staging)
+	Environments string `yaml:"environments"`
+	// DefaultCloud é a nuvem usada quando o ambiente é informado sem prefixo
+	DefaultCloud string `yaml:"default_cloud"`
+	// Tests é o diretório do módulo Go de testes (testdata, unit, property)
+	Tests string `yaml:"tests"`
+	// Spec é o diretório com requirements.md e design.md
+	Spec string `yaml:"spec"`
+	// Baseline é o arquivo de achados aceitos pelo templatecheck
+	Baseline string `yaml:"baseline"`
+}
+
+// DefaultLayout retorna a organização deste repositório, usada quando não há
+// LayoutFile na raiz ou quando o arquivo omite algum campo
+func DefaultLayout() *Layout {
+	return &Layout{
+		Modules:      "modules",
+		Environments: "live",
+		DefaultCloud: "aws",
+		Tests:        "test",
+		Spec:         filepath.Join(".kiro", "specs", "terraform-eks-aws-template"),
+		Baseline:     ".templatecheck-baseline.yaml",
+	}
+}
+
+var (
+	// projectRoot substitui a detecção da raiz quando definido por SetProjectRoot
+	projectRoot string
+	layoutMu    sync.Mutex
+	layouts     = make(map[string]*Layout)
+)
+
+// SetProjectRoot fixa a raiz do projeto usada pelos helpers (ex: flag --root)
+func SetProjectRoot(dir string) {
+	layoutMu.Lock()
+	defer layoutMu.Unlock()
+	projectRoot = dir
+}
+
+// GetProjectRoot retorna o diretório raiz do projeto, na ordem: SetProjectRoot,
+// variável TEMPLATE_ROOT e o primeiro diretório acima do atual reconhecido por
+// FindProjectRoot. Sem nenhum deles, assume o diretório atual.
+func GetProjectRoot() string {
+	layoutMu.Lock()
+	root := projectRoot
+	layoutMu.Unlock()
+	if root != "" {
+		return root
+	}
+	if root := os.Getenv(ProjectRootEnv); root != "" {
+		return root
+	}
+
+	dir, _ := os.Getwd()
+	if root, err := FindProjectRoot(dir); err == nil {
+		return root
+	}
+	return dir
+}
+
+// FindProjectRoot sobe a partir de start até o primeiro diretório que contém
+// LayoutFile ou, sem o marcador, os diretórios de módulos e ambientes do
+// DefaultLayout
+func FindProjectRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	defaults := DefaultLayout()
+	for {
+		if FileExists(filepath.Join(dir, LayoutFile)) {
+			return dir, nil
+		}
+		if DirectoryExists(filepath.Join(dir, defaults.Modules)) && DirectoryExists(filepath.Join(dir, defaults.Environments)) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("raiz do repositório não encontrada a partir de %s: crie %s ou defina %s", start, LayoutFile, ProjectRootEnv)
+		}
+		dir = parent
+	}
+}
+
+// LoadLayout lê o LayoutFile da raiz informada, completando os campos omitidos
+// com o DefaultLayout. Sem o arquivo, retorna o DefaultLayout.
+func LoadLayout(root string) (*Layout, error) {
+	layout := DefaultLayout()
+	content, err := os.ReadFile(filepath.Join(root, LayoutFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return layout, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &Layout{}
+	if err := yaml.Unmarshal(content, loaded); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(root, LayoutFile), err)
+	}
+	for _, field := range []struct{ value, target *string }{
+		{&loaded.Modules, &layout.Modules},
+		{&loaded.Environments, &layout.Environments},
+		{&loaded.DefaultCloud, &layout.DefaultCloud},
+		{&loaded.Tests, &layout.Tests},
+		{&loaded.Spec, &layout.Spec},
+		{&loaded.Baseline, &layout.Baseline},
+	} {
+		if *field.value != "" {
+			*field.target = filepath.FromSlash(*field.value)
+		}
+	}
+	return layout, nil
+}
+
+// GetLayout retorna o layout da raiz do projeto. Um LayoutFile inválido faz
+// os helpers usarem o DefaultLayout; use LoadLayout para obter o erro.
+func GetLayout() *Layout {
+	root := GetProjectRoot()
+
+	layoutMu.Lock()
+	defer layoutMu.Unlock()
+	if layout, ok := layouts[root]; ok {
+		return layout
+	}
+	layout, err := LoadLayout(root)
+	if err != nil {
+		layout = DefaultLayout()
+	}
+	layouts[root] = layout
+	return layout
+}
+
+// GetModulePath retorna o caminho para um módulo
+func GetModulePath(moduleName string) string {
+	return filepath.Join(GetProjectRoot(), GetLayout().Modules, moduleName)
+}
+
+// GetModulesPath retorna o diretório dos módulos
+func GetModulesPath() string {
+	return filepath.Join(GetProjectRoot(), GetLayout().Modules)
+}
+
+// GetEnvironmentPath retorna o caminho para um ambiente da nuvem padrão
+// (ex: "staging") ou de uma nuvem específica (ex: "gcp/staging")
+func GetEnvironmentPath(env string) string {
+	layout := GetLayout()
+	cloud, name := layout.DefaultCloud, env
+	if dir, base := filepath.Split(filepath.FromSlash(env)); dir != "" {
+		cloud, name = filepath.Clean(dir), base
+	}
+	return filepath.Join(GetProjectRoot(), layout.Environments, cloud, name)
+}
+
+// GetTestPath retorna um caminho dentro do diretório de testes
+// (ex: GetTestPath("testdata", "charts"))
+func GetTestPath(elem ...string) string {
+	return filepath.Join(append([]string{GetProjectRoot(), GetLayout().Tests}, elem...)...)
+}
+
+// GetSpecPath retorna um arquivo do diretório do spec (ex: "requirements.md")
+func GetSpecPath(name string) string {
+	return filepath.Join(GetProjectRoot(), GetLayout().Spec, name)
+}
+
+// GetBaselinePath retorna o arquivo de baseline do templatecheck
+func GetBaselinePath() string {
+	return filepath.Join(GetProjectRoot(), GetLayout().Baseline)
+}
+
+// ListClouds retorna as nuvens com diretório de ambientes, em ordem
+func ListClouds() ([]string, error) {
+	return subdirectories(filepath.Join(GetProjectRoot(), GetLayout().Environments))
+}
+
+// ListEnvironments retorna os ambientes de uma nuvem, em ordem. Os nomes de
+// nuvens diferentes da padrão são prefixados (ex: "gcp/staging"), no formato
+// aceito por GetEnvironmentPath.
+func ListEnvironments(cloud string) ([]string, error) {
+	layout := GetLayout()
+	envs, err := subdirectories(filepath.Join(GetProjectRoot(), layout.Environments, cloud))
+	if err != nil || cloud == layout.DefaultCloud {
+		return envs, err
+	}
+	for i, env := range envs {
+		envs[i] = cloud + "/" + env
+	}
+	return envs, nil
+}
+
+// ListAllEnvironments retorna os ambientes de todas as nuvens
+func ListAllEnvironments() ([]string, error) {
+	clouds, err := ListClouds()
+	if err != nil {
+		return nil, err
+	}
+	var all []string
+	for _, cloud := range clouds {
+		envs, err := ListEnvironments(cloud)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, envs...)
+	}
+	return all, nil
+}
+
+// subdirectories lista os subdiretórios de dir, em ordem; dir inexistente não é erro
+func subdirectories(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
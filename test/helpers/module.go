@@ -0,0 +1,336 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Module representa um módulo Terraform carregado a partir de um diretório
+type Module struct {
+	Dir         string
+	Files       map[string]*hcl.File
+	Variables   map[string]*Variable
+	Locals      map[string]*Local
+	Outputs     map[string]*Output
+	Resources   map[string]*Resource
+	ModuleCalls map[string]*ModuleCall
+}
+
+// Variable representa um bloco variable de um módulo
+type Variable struct {
+	Name        string
+	Description string
+	Type        cty.Type
+	Defaults    *typeexpr.Defaults
+	Default     cty.Value
+	Sensitive   bool
+	Validations []*Validation
+	Range       hcl.Range
+}
+
+// Required indica se a variável não possui valor default
+func (v *Variable) Required() bool {
+	return v.Default == cty.NilVal
+}
+
+// Validation representa um bloco validation dentro de uma variável
+type Validation struct {
+	Condition    hcl.Expression
+	ErrorMessage hcl.Expression
+	Range        hcl.Range
+}
+
+// Local representa um valor declarado em um bloco locals
+type Local struct {
+	Name  string
+	Expr  hcl.Expression
+	Range hcl.Range
+}
+
+// Output representa um bloco output de um módulo
+type Output struct {
+	Name        string
+	Description string
+	Sensitive   bool
+	Expr        hcl.Expression
+	Range       hcl.Range
+}
+
+// Resource representa um bloco resource ou data de um módulo
+type Resource struct {
+	Mode    string // "managed" ou "data"
+	Type    string
+	Name    string
+	Count   hcl.Expression
+	ForEach hcl.Expression
+	Body    *hclsyntax.Body
+	Range   hcl.Range
+}
+
+// Address retorna o endereço Terraform do recurso (ex: aws_vpc.main, data.aws_region.current)
+func (r *Resource) Address() string {
+	if r.Mode == "data" {
+		return "data." + r.Type + "." + r.Name
+	}
+	return r.Type + "." + r.Name
+}
+
+// ModuleCall representa um bloco module que instancia um módulo filho
+type ModuleCall struct {
+	Name   string
+	Source string
+	Body   *hclsyntax.Body
+	Range  hcl.Range
+}
+
+// metaArguments são argumentos de recursos tratados pelo Terraform e não pelo provider
+var metaArguments = map[string]bool{
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+	"provider":   true,
+}
+
+// moduleMetaArguments são argumentos de blocos module que não são variáveis do módulo filho
+var moduleMetaArguments = map[string]bool{
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+	"providers":  true,
+	"source":     true,
+	"version":    true,
+}
+
+// metaBlocks são blocos tratados pelo Terraform e não pelo provider
+var metaBlocks = map[string]bool{
+	"lifecycle":   true,
+	"provisioner": true,
+	"connection":  true,
+}
+
+// LoadModule faz parse de todos os arquivos .tf de um diretório
+func LoadModule(dir string) (*Module, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo .tf encontrado em %s", dir)
+	}
+	sort.Strings(paths)
+
+	mod := &Module{
+		Dir:         dir,
+		Files:       make(map[string]*hcl.File),
+		Variables:   make(map[string]*Variable),
+		Locals:      make(map[string]*Local),
+		Outputs:     make(map[string]*Output),
+		Resources:   make(map[string]*Resource),
+		ModuleCalls: make(map[string]*ModuleCall),
+	}
+
+	parser := hclparse.NewParser()
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler arquivo %s: %w", path, err)
+		}
+		file, diags := parser.ParseHCL(content, path)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("erro ao parsear HCL: %s", diags.Error())
+		}
+		mod.Files[path] = file
+
+		body := file.Body.(*hclsyntax.Body)
+		for _, block := range body.Blocks {
+			if err := mod.addBlock(block); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return mod, nil
+}
+
+func (m *Module) addBlock(block *hclsyntax.Block) error {
+	switch block.Type {
+	case "variable":
+		v, err := decodeVariable(block)
+		if err != nil {
+			return err
+		}
+		m.Variables[v.Name] = v
+	case "locals":
+		for name, attr := range block.Body.Attributes {
+			m.Locals[name] = &Local{Name: name, Expr: attr.Expr, Range: attr.SrcRange}
+		}
+	case "output":
+		o := &Output{Name: block.Labels[0], Range: block.DefRange()}
+		if attr, ok := block.Body.Attributes["value"]; ok {
+			o.Expr = attr.Expr
+		}
+		o.Description = literalString(block.Body, "description")
+		o.Sensitive = literalBool(block.Body, "sensitive")
+		m.Outputs[o.Name] = o
+	case "resource", "data":
+		r := &Resource{
+			Mode:  "managed",
+			Type:  block.Labels[0],
+			Name:  block.Labels[1],
+			Body:  block.Body,
+			Range: block.DefRange(),
+		}
+		if block.Type == "data" {
+			r.Mode = "data"
+		}
+		if attr, ok := block.Body.Attributes["count"]; ok {
+			r.Count = attr.Expr
+		}
+		if attr, ok := block.Body.Attributes["for_each"]; ok {
+			r.ForEach = attr.Expr
+		}
+		m.Resources[r.Address()] = r
+	case "module":
+		mc := &ModuleCall{
+			Name:   block.Labels[0],
+			Source: literalString(block.Body, "source"),
+			Body:   block.Body,
+			Range:  block.DefRange(),
+		}
+		m.ModuleCalls[mc.Name] = mc
+	}
+	return nil
+}
+
+func decodeVariable(block *hclsyntax.Block) (*Variable, error) {
+	v := &Variable{
+		Name:  block.Labels[0],
+		Type:  cty.DynamicPseudoType,
+		Range: block.DefRange(),
+	}
+	v.Description = literalString(block.Body, "description")
+	v.Sensitive = literalBool(block.Body, "sensitive")
+
+	if attr, ok := block.Body.Attributes["type"]; ok {
+		ty, defaults, diags := typeexpr.TypeConstraintWithDefaults(attr.Expr)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("tipo inválido na variável %s: %s", v.Name, diags.Error())
+		}
+		v.Type = ty
+		v.Defaults = defaults
+	}
+
+	if attr, ok := block.Body.Attributes["default"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("default inválido na variável %s: %s", v.Name, diags.Error())
+		}
+		v.Default = val
+	}
+
+	for _, child := range block.Body.Blocks {
+		if child.Type != "validation" {
+			continue
+		}
+		validation := &Validation{Range: child.DefRange()}
+		if attr, ok := child.Body.Attributes["condition"]; ok {
+			validation.Condition = attr.Expr
+		}
+		if attr, ok := child.Body.Attributes["error_message"]; ok {
+			validation.ErrorMessage = attr.Expr
+		}
+		v.Validations = append(v.Validations, validation)
+	}
+
+	return v, nil
+}
+
+// literalString avalia um atributo que não depende de contexto e retorna seu valor como string
+func literalString(body *hclsyntax.Body, name string) string {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return ""
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+	return strings.TrimSpace(val.AsString())
+}
+
+// literalBool avalia um atributo booleano que não depende de contexto
+func literalBool(body *hclsyntax.Body, name string) bool {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.Bool {
+		return false
+	}
+	return val.True()
+}
+
+// SortedResources retorna os recursos do módulo ordenados por arquivo e linha
+func (m *Module) SortedResources() []*Resource {
+	resources := make([]*Resource, 0, len(m.Resources))
+	for _, r := range m.Resources {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i].Range, resources[j].Range
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Start.Line < b.Start.Line
+	})
+	return resources
+}
+
+// ResourcesOfType retorna os recursos managed de um tipo, ordenados por posição
+func (m *Module) ResourcesOfType(resourceType string) []*Resource {
+	var resources []*Resource
+	for _, r := range m.SortedResources() {
+		if r.Mode == "managed" && r.Type == resourceType {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// ReadTFVars faz parse de um arquivo .tfvars e retorna os valores declarados
+func ReadTFVars(path string) (map[string]cty.Value, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo %s: %w", path, err)
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("erro ao parsear HCL: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("erro ao ler atributos de %s: %s", path, diags.Error())
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("erro ao avaliar %s em %s: %s", name, path, diags.Error())
+		}
+		values[name] = val
+	}
+	return values, nil
+}
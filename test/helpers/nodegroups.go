@@ -0,0 +1,157 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// eksModuleInputs são as entradas obrigatórias do módulo clusters/eks, exceto
+// node_groups, usadas para expandir node groups gerados
+var eksModuleInputs = map[string]cty.Value{
+	"cluster_name":       cty.StringVal("eks-property"),
+	"environment":        cty.StringVal("staging"),
+	"vpc_cidr":           cty.StringVal("10.0.0.0/16"),
+	"availability_zones": cty.ListVal([]cty.Value{cty.StringVal("us-east-1a"), cty.StringVal("us-east-1b"), cty.StringVal("us-east-1c")}),
+}
+
+// CtyValue converte a configuração em um item da variável node_groups do módulo
+// clusters/eks; o tipo final é aplicado pela conversão da variável
+func (c *NodeGroupConfig) CtyValue() cty.Value {
+	types := make([]cty.Value, len(c.InstanceTypes))
+	for i, instanceType := range c.InstanceTypes {
+		types[i] = cty.StringVal(instanceType)
+	}
+	labels := make(map[string]cty.Value, len(c.Labels))
+	for key, value := range c.Labels {
+		labels[key] = cty.StringVal(value)
+	}
+	taints := make([]cty.Value, len(c.Taints))
+	for i, taint := range c.Taints {
+		taints[i] = cty.ObjectVal(map[string]cty.Value{
+			"key":    cty.StringVal(taint.Key),
+			"value":  cty.StringVal(taint.Value),
+			"effect": cty.StringVal(taint.Effect),
+		})
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"instance_types": cty.TupleVal(types),
+		"min_size":       cty.NumberIntVal(int64(c.MinSize)),
+		"max_size":       cty.NumberIntVal(int64(c.MaxSize)),
+		"desired_size":   cty.NumberIntVal(int64(c.DesiredSize)),
+		"disk_size":      cty.NumberIntVal(int64(c.DiskSize)),
+		"labels":         cty.ObjectVal(labels),
+		"taints":         cty.TupleVal(taints),
+	})
+}
+
+// NodeGroupsValue converte node groups nomeados na variável node_groups
+func NodeGroupsValue(groups map[string]*NodeGroupConfig) cty.Value {
+	values := make(map[string]cty.Value, len(groups))
+	for name, group := range groups {
+		values[name] = group.CtyValue()
+	}
+	return cty.ObjectVal(values)
+}
+
+// ExpandedNodeGroup é um node group expandido pelo módulo clusters/eks
+type ExpandedNodeGroup struct {
+	Name           string
+	NodeGroup      *ResourceInstance
+	LaunchTemplate *ResourceInstance
+}
+
+// ExpandEKSNodeGroups avalia o módulo clusters/eks com os node groups
+// informados: retorna as mensagens dos blocos validation que falharam e, se a
+// entrada for válida, as instâncias de aws_eks_node_group.main e
+// aws_launch_template.node_group de cada node group
+func ExpandEKSNodeGroups(groups map[string]*NodeGroupConfig) ([]*ExpandedNodeGroup, []error, error) {
+	mod, err := LoadModule(GetModulePath("clusters/eks"))
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs := make(map[string]cty.Value, len(eksModuleInputs)+1)
+	for name, value := range eksModuleInputs {
+		inputs[name] = value
+	}
+	inputs["node_groups"] = NodeGroupsValue(groups)
+
+	ev, err := NewEvaluator(mod, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if errs := ev.ValidateVariables(); len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	byName := make(map[string]*ExpandedNodeGroup)
+	for _, addr := range []string{"aws_eks_node_group.main", "aws_launch_template.node_group"} {
+		instances, err := ev.Instances(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, inst := range instances {
+			if inst.Diagnostics.HasErrors() {
+				return nil, nil, fmt.Errorf("%s: %s", inst.Address(), inst.Diagnostics.Error())
+			}
+			name := instanceName(inst)
+			group := byName[name]
+			if group == nil {
+				group = &ExpandedNodeGroup{Name: name}
+				byName[name] = group
+			}
+			if inst.Resource.Type == "aws_eks_node_group" {
+				group.NodeGroup = inst
+			} else {
+				group.LaunchTemplate = inst
+			}
+		}
+	}
+
+	expanded := make([]*ExpandedNodeGroup, 0, len(byName))
+	for _, group := range byName {
+		expanded = append(expanded, group)
+	}
+	sort.Slice(expanded, func(i, j int) bool { return expanded[i].Name < expanded[j].Name })
+	return expanded, nil, nil
+}
+
+// NodeGroupConfigFromInstances reconstrói a configuração de um node group a
+// partir das instâncias expandidas: tamanhos e taints do aws_eks_node_group,
+// labels sem o "node-group" adicionado pelo módulo e o disco do launch template
+func NodeGroupConfigFromInstances(group *ExpandedNodeGroup) *NodeGroupConfig {
+	config := &NodeGroupConfig{Labels: make(map[string]string)}
+	if group.NodeGroup != nil {
+		attrs := group.NodeGroup.Values()
+		for _, t := range listValue(attrs["instance_types"]) {
+			config.InstanceTypes = append(config.InstanceTypes, fmt.Sprint(t))
+		}
+		for _, scaling := range listValue(attrs["scaling_config"]) {
+			scaling, _ := scaling.(map[string]interface{})
+			for key, target := range map[string]*int{"min_size": &config.MinSize, "desired_size": &config.DesiredSize, "max_size": &config.MaxSize} {
+				value, _ := toFloat(scaling[key])
+				*target = int(value)
+			}
+		}
+		if labels, ok := attrs["labels"].(map[string]interface{}); ok {
+			for key, value := range labels {
+				if key != "node-group" {
+					config.Labels[key] = fmt.Sprint(value)
+				}
+			}
+		}
+		config.Taints = nodeGroupFromInstance(group.NodeGroup).Taints
+	}
+	if group.LaunchTemplate != nil {
+		for _, device := range listValue(group.LaunchTemplate.Values()["block_device_mappings"]) {
+			device, _ := device.(map[string]interface{})
+			for _, ebs := range listValue(device["ebs"]) {
+				ebs, _ := ebs.(map[string]interface{})
+				size, _ := toFloat(ebs["volume_size"])
+				config.DiskSize = int(size)
+			}
+		}
+	}
+	return config
+}
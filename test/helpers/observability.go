@@ -0,0 +1,271 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// ObservabilityUsage são as premissas de ingestão de métricas e logs de um ambiente
+type ObservabilityUsage struct {
+	ActiveSeries          int     `yaml:"active_series"`
+	ScrapeIntervalSeconds int     `yaml:"scrape_interval_seconds"`
+	LogGBPerDay           float64 `yaml:"log_gb_per_day"`
+}
+
+// ObservabilityConfig é o modelo de dimensionamento dos volumes de observabilidade
+// (testdata/observability.yaml)
+type ObservabilityConfig struct {
+	BytesPerSample      float64                        `yaml:"bytes_per_sample"`
+	LogCompressionRatio float64                        `yaml:"log_compression_ratio"`
+	Overhead            float64                        `yaml:"overhead"`
+	MaxUsage            float64                        `yaml:"max_usage"`
+	Environments        map[string]*ObservabilityUsage `yaml:"environments"`
+
+	path string
+}
+
+// GetObservabilityConfigPath retorna o caminho das premissas de observabilidade
+func GetObservabilityConfigPath() string {
+	return GetTestPath("testdata", "observability.yaml")
+}
+
+// LoadObservabilityConfig lê as premissas de ingestão e o modelo de dimensionamento
+func LoadObservabilityConfig(path string) (*ObservabilityConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &ObservabilityConfig{path: path}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, value := range map[string]float64{
+		"bytes_per_sample": config.BytesPerSample, "log_compression_ratio": config.LogCompressionRatio,
+		"overhead": config.Overhead, "max_usage": config.MaxUsage,
+	} {
+		if value <= 0 {
+			return nil, fmt.Errorf("%s: %s deve ser positivo", path, name)
+		}
+	}
+	return config, nil
+}
+
+// Environment retorna as premissas do ambiente, buscando também pelo nome sem a
+// nuvem (ex: "gcp/staging" usa "staging"), ou nil
+func (c *ObservabilityConfig) Environment(env string) *ObservabilityUsage {
+	if usage, ok := c.Environments[env]; ok {
+		return usage
+	}
+	return c.Environments[path.Base(env)]
+}
+
+// PrometheusDataBytes estima o dado retido pelo TSDB: amostras por segundo
+// (séries ativas / intervalo de scrape) × bytes por amostra × retenção
+func PrometheusDataBytes(series, scrapeIntervalSeconds int, bytesPerSample float64, retention time.Duration) float64 {
+	if scrapeIntervalSeconds <= 0 {
+		return 0
+	}
+	samplesPerSecond := float64(series) / float64(scrapeIntervalSeconds)
+	return samplesPerSecond * bytesPerSample * retention.Seconds()
+}
+
+// LokiDataBytes estima os chunks retidos pelo Loki: logs brutos por dia ×
+// retenção em dias / razão de compressão
+func LokiDataBytes(gbPerDay, compressionRatio float64, retention time.Duration) float64 {
+	if compressionRatio <= 0 {
+		return 0
+	}
+	return gbPerDay * 1e9 * retention.Hours() / 24 / compressionRatio
+}
+
+// RequiredBytes é o volume necessário para o dado retido, com o overhead e a
+// ocupação máxima do modelo
+func (c *ObservabilityConfig) RequiredBytes(data float64) float64 {
+	return data * c.Overhead / c.MaxUsage
+}
+
+// StorageSizing é a retenção e o dimensionamento do volume de um componente
+type StorageSizing struct {
+	Component string `json:"component"`
+	Release   string `json:"release"`
+	// RetentionDays é a variável do módulo e Retention o valor renderizado no chart
+	RetentionDays int     `json:"retention_days"`
+	Retention     string  `json:"retention"`
+	Volume        string  `json:"volume"`
+	VolumeBytes   float64 `json:"volume_bytes"`
+	DataBytes     float64 `json:"data_bytes"`
+	RequiredBytes float64 `json:"required_bytes"`
+	// Rate descreve a taxa de ingestão usada na estimativa
+	Rate string `json:"rate"`
+	// RetentionProblems são divergências entre a variável e os values do chart
+	RetentionProblems []string `json:"retention_problems,omitempty"`
+
+	// file e line localizam o helm_release no módulo
+	file string
+	line int
+}
+
+// Fits indica se o volume comporta a janela de retenção
+func (s *StorageSizing) Fits() bool {
+	return s.RequiredBytes <= s.VolumeBytes
+}
+
+// EnvironmentObservability é o dimensionamento do Prometheus e do Loki de um ambiente
+type EnvironmentObservability struct {
+	Environment string         `json:"environment"`
+	Prometheus  *StorageSizing `json:"prometheus"`
+	Loki        *StorageSizing `json:"loki"`
+}
+
+// EstimateEnvironmentObservability avalia o ambiente e dimensiona os volumes de
+// observabilidade com as premissas do ambiente
+func EstimateEnvironmentObservability(env string, config *ObservabilityConfig) (*EnvironmentObservability, error) {
+	ev, err := NewEnvironmentEvaluator(env)
+	if err != nil {
+		return nil, err
+	}
+	return EstimateObservability(ev, env, config)
+}
+
+// EstimateObservability lê as variáveis do módulo observability e os values
+// renderizados de kube-prometheus-stack e loki: compara a retenção do chart com a
+// variável e estima o volume necessário para a janela de retenção
+func EstimateObservability(ev *Evaluator, env string, config *ObservabilityConfig) (*EnvironmentObservability, error) {
+	usage := config.Environment(env)
+	if usage == nil {
+		return nil, fmt.Errorf("%s: environments.%s ausente", config.path, env)
+	}
+	child, err := ev.Child("observability")
+	if err != nil {
+		return nil, err
+	}
+	instances, err := child.Expand()
+	if err != nil {
+		return nil, err
+	}
+	releases, err := HelmReleases(instances)
+	if err != nil {
+		return nil, err
+	}
+	charts := make(map[string]*HelmRelease)
+	for _, release := range releases {
+		charts[release.Chart] = release
+	}
+
+	prometheus, err := sizeStorage(child, charts["kube-prometheus-stack"], "Prometheus", "prometheus_retention_days",
+		"prometheus.prometheusSpec.retention",
+		"prometheus.prometheusSpec.storageSpec.volumeClaimTemplate.spec.resources.requests.storage")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", env, err)
+	}
+	retention := time.Duration(prometheus.RetentionDays) * 24 * time.Hour
+	prometheus.DataBytes = PrometheusDataBytes(usage.ActiveSeries, usage.ScrapeIntervalSeconds, config.BytesPerSample, retention)
+	prometheus.RequiredBytes = config.RequiredBytes(prometheus.DataBytes)
+	prometheus.Rate = fmt.Sprintf("%d séries a cada %ds", usage.ActiveSeries, usage.ScrapeIntervalSeconds)
+
+	loki, err := sizeStorage(child, charts["loki"], "Loki", "loki_retention_days",
+		"loki.limits_config.retention_period", "singleBinary.persistence.size")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", env, err)
+	}
+	// Sem o compactor com retenção habilitada, retention_period não apaga chunks
+	if enabled, _ := lookupHelmValue(charts["loki"].Values, "loki.compactor.retention_enabled").(bool); !enabled {
+		loki.RetentionProblems = append(loki.RetentionProblems,
+			"loki.compactor.retention_enabled deve ser true para aplicar retention_period")
+	}
+	retention = time.Duration(loki.RetentionDays) * 24 * time.Hour
+	loki.DataBytes = LokiDataBytes(usage.LogGBPerDay, config.LogCompressionRatio, retention)
+	loki.RequiredBytes = config.RequiredBytes(loki.DataBytes)
+	loki.Rate = fmt.Sprintf("%s GB de logs por dia", formatNumber(usage.LogGBPerDay))
+
+	return &EnvironmentObservability{Environment: env, Prometheus: prometheus, Loki: loki}, nil
+}
+
+// sizeStorage lê a variável de retenção, a retenção renderizada e o tamanho do volume de um release
+func sizeStorage(module *Evaluator, release *HelmRelease, component, variable, retentionPath, volumePath string) (*StorageSizing, error) {
+	if release == nil {
+		return nil, fmt.Errorf("helm_release de %s não encontrado em %s", component, module.Path)
+	}
+	days := module.Var(variable)
+	if days.IsNull() || !days.IsKnown() || days.Type() != cty.Number {
+		return nil, fmt.Errorf("%s: %s não definido", module.Path, variable)
+	}
+	retentionDays, _ := days.AsBigFloat().Int64()
+	sizing := &StorageSizing{Component: component, Release: release.Address, RetentionDays: int(retentionDays),
+		file: release.File, line: release.Line}
+
+	sizing.Retention = fmt.Sprint(lookupHelmValue(release.Values, retentionPath))
+	rendered, err := parsePrometheusDuration(sizing.Retention)
+	switch {
+	case err != nil:
+		sizing.RetentionProblems = append(sizing.RetentionProblems, fmt.Sprintf("%s: %v", retentionPath, err))
+	case rendered != time.Duration(retentionDays)*24*time.Hour:
+		sizing.RetentionProblems = append(sizing.RetentionProblems,
+			fmt.Sprintf("%s = %q difere de %s = %d dias", retentionPath, sizing.Retention, variable, retentionDays))
+	}
+
+	sizing.Volume = fmt.Sprint(lookupHelmValue(release.Values, volumePath))
+	if sizing.VolumeBytes, err = parseQuantityBytes(sizing.Volume); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", release.Address, volumePath, err)
+	}
+	return sizing, nil
+}
+
+// prometheusDuration é o formato de duração do Prometheus e do Loki (ex: "7d", "72h", "1d12h")
+var prometheusDuration = regexp.MustCompile(`^((\d+)(ms|y|w|d|h|m|s))+$`)
+
+var prometheusDurationUnits = map[string]time.Duration{
+	"y": 365 * 24 * time.Hour, "w": 7 * 24 * time.Hour, "d": 24 * time.Hour,
+	"h": time.Hour, "m": time.Minute, "s": time.Second, "ms": time.Millisecond,
+}
+
+// parsePrometheusDuration converte uma duração no formato do Prometheus
+func parsePrometheusDuration(s string) (time.Duration, error) {
+	if !prometheusDuration.MatchString(s) {
+		return 0, fmt.Errorf("duração inválida %q", s)
+	}
+	var total time.Duration
+	for _, match := range regexp.MustCompile(`(\d+)(ms|y|w|d|h|m|s)`).FindAllStringSubmatch(s, -1) {
+		value, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("duração inválida %q", s)
+		}
+		total += time.Duration(value) * prometheusDurationUnits[match[2]]
+	}
+	return total, nil
+}
+
+// formatGiB formata bytes em GiB
+func formatGiB(bytes float64) string {
+	return fmt.Sprintf("%.1f GiB", bytes/(1<<30))
+}
+
+// Markdown formata a retenção e o dimensionamento dos volumes do ambiente
+func (o *EnvironmentObservability) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Observabilidade: %s\n\n", o.Environment)
+	b.WriteString("| Componente | Retenção | Ingestão | Dado retido | Necessário | Volume |\n")
+	b.WriteString("|------------|----------|----------|-------------|------------|--------|\n")
+	for _, s := range []*StorageSizing{o.Prometheus, o.Loki} {
+		status := "✅"
+		if !s.Fits() {
+			status = "❌"
+		}
+		fmt.Fprintf(&b, "| %s | %d dias (%s) | %s | %s | %s | %s %s |\n", s.Component, s.RetentionDays, s.Retention,
+			s.Rate, formatGiB(s.DataBytes), formatGiB(s.RequiredBytes), s.Volume, status)
+	}
+	for _, s := range []*StorageSizing{o.Prometheus, o.Loki} {
+		for _, problem := range s.RetentionProblems {
+			fmt.Fprintf(&b, "- ❌ %s: %s\n", s.Component, problem)
+		}
+	}
+	return b.String()
+}
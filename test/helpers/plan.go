@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// TerraformPlan é o subconjunto da saída de "terraform show -json <plano>" usado
+// pelas verificações de plano
+type TerraformPlan struct {
+	FormatVersion   string            `json:"format_version"`
+	ResourceChanges []*ResourceChange `json:"resource_changes"`
+}
+
+// ResourceChange é a mudança planejada para uma instância de recurso
+type ResourceChange struct {
+	Address      string `json:"address"`
+	Mode         string `json:"mode"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	ProviderName string `json:"provider_name"`
+	Change       struct {
+		Actions []string               `json:"actions"`
+		Before  map[string]interface{} `json:"before"`
+		After   map[string]interface{} `json:"after"`
+		// AfterUnknown marca os atributos conhecidos apenas após o apply
+		AfterUnknown map[string]interface{} `json:"after_unknown"`
+	} `json:"change"`
+}
+
+// LoadTerraformPlan lê um plano em JSON gerado por "terraform show -json"
+func LoadTerraformPlan(path string) (*TerraformPlan, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plan := &TerraformPlan{}
+	if err := json.Unmarshal(content, plan); err != nil {
+		return nil, fmt.Errorf("%s: plano JSON inválido (use terraform show -json): %w", path, err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("%s: format_version ausente; não parece uma saída de terraform show -json", path)
+	}
+	return plan, nil
+}
+
+// hasAction indica se a mudança inclui a ação informada (create, update, delete, read, no-op)
+func (c *ResourceChange) hasAction(action string) bool {
+	for _, a := range c.Change.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Deletes indica se a mudança remove a instância, inclusive em uma substituição
+func (c *ResourceChange) Deletes() bool {
+	return c.hasAction("delete")
+}
+
+// Replaces indica se a instância é destruída e recriada
+func (c *ResourceChange) Replaces() bool {
+	return c.hasAction("delete") && c.hasAction("create")
+}
+
+// Writes indica se a mudança cria ou altera a instância
+func (c *ResourceChange) Writes() bool {
+	return c.hasAction("create") || c.hasAction("update")
+}
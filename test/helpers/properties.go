@@ -0,0 +1,306 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpecProperty é uma propriedade de corretude declarada no design do spec
+type SpecProperty struct {
+	Number int
+	Title  string
+	Line   int
+}
+
+// PropertyCheck é uma chamada prop.ForAll dentro de um teste de propriedade
+type PropertyCheck struct {
+	// Description é o nome passado para properties.Property, quando literal
+	Description string
+	Line        int
+	// Arguments são os parâmetros da função verificada, preenchidos pelos geradores
+	Arguments []string
+	// Unused são os argumentos gerados que a função não lê
+	Unused []string
+}
+
+// PropertyTest é um teste marcado com "Feature: <spec>, Property N: <título>"
+type PropertyTest struct {
+	Name     string
+	File     string
+	Line     int
+	Property int
+	Title    string
+	Checks   []*PropertyCheck
+}
+
+// Vacuous indica que alguma verificação do teste não recebe ou ignora argumentos gerados
+func (t *PropertyTest) Vacuous() bool {
+	for _, check := range t.Checks {
+		if len(check.Arguments) == 0 || len(check.Unused) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	designProperty = regexp.MustCompile(`^\*\*Propriedade (\d+):\s*(.+?)\*\*$`)
+	featureTag     = regexp.MustCompile(`Feature:\s*[^,]+,\s*Property (\d+):\s*(.+)$`)
+)
+
+// GetDesignPath retorna o caminho do documento de design do spec
+func GetDesignPath() string {
+	return GetSpecPath("design.md")
+}
+
+// ParseDesignProperties extrai as propriedades "**Propriedade N: título**" do design
+func ParseDesignProperties(path string) ([]*SpecProperty, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var properties []*SpecProperty
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		m := designProperty.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(m[1])
+		properties = append(properties, &SpecProperty{Number: number, Title: m[2], Line: line})
+	}
+	return properties, scanner.Err()
+}
+
+// PropertyTests lê os testes de propriedade de um diretório, com as chamadas
+// prop.ForAll de cada um e os argumentos gerados que não são usados
+func PropertyTests(dir string) ([]*PropertyTest, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var tests []*PropertyTest
+	fset := token.NewFileSet()
+	for _, path := range files {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+				continue
+			}
+			test := &PropertyTest{Name: fn.Name.Name, File: path, Line: fset.Position(fn.Pos()).Line}
+
+			// O tag pode estar no comentário da função ou no corpo (modelo do README)
+			for _, group := range file.Comments {
+				inBody := fn.Body != nil && group.Pos() > fn.Body.Lbrace && group.End() < fn.Body.Rbrace
+				if group != fn.Doc && !inBody {
+					continue
+				}
+				for _, comment := range group.List {
+					if m := featureTag.FindStringSubmatch(comment.Text); m != nil && test.Property == 0 {
+						test.Property, _ = strconv.Atoi(m[1])
+						test.Title = strings.TrimSpace(m[2])
+					}
+				}
+			}
+			if test.Property == 0 {
+				continue
+			}
+
+			test.Checks = propertyChecks(fset, fn.Body)
+			tests = append(tests, test)
+		}
+	}
+	return tests, nil
+}
+
+// propertyChecks encontra as chamadas prop.ForAll e verifica o uso dos argumentos
+func propertyChecks(fset *token.FileSet, body *ast.BlockStmt) []*PropertyCheck {
+	var checks []*PropertyCheck
+	ast.Inspect(body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		// properties.Property("descrição", prop.ForAll(...))
+		description := ""
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Property" && len(call.Args) == 2 {
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				description, _ = strconv.Unquote(lit.Value)
+			}
+			call, ok = call.Args[1].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "ForAll" && sel.Sel.Name != "ForAllNoShrink") || len(call.Args) == 0 {
+			return true
+		}
+		fn, ok := call.Args[0].(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+
+		check := &PropertyCheck{Description: description, Line: fset.Position(call.Pos()).Line}
+		for _, field := range fn.Type.Params.List {
+			for _, name := range field.Names {
+				check.Arguments = append(check.Arguments, name.Name)
+				if name.Name == "_" || !identUsed(fn.Body, name.Name) {
+					check.Unused = append(check.Unused, name.Name)
+				}
+			}
+		}
+		checks = append(checks, check)
+		return false
+	})
+	return checks
+}
+
+// identUsed indica se o identificador é referenciado no bloco
+func identUsed(body *ast.BlockStmt, name string) bool {
+	used := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+// PropertyLinkage relaciona as propriedades do design aos testes de propriedade
+type PropertyLinkage struct {
+	Properties []*SpecProperty
+	Tests      map[int][]*PropertyTest
+	// Unknown são testes que citam propriedades inexistentes no design
+	Unknown []*PropertyTest
+}
+
+// LinkProperties associa cada propriedade do design aos testes que a citam
+func LinkProperties(properties []*SpecProperty, tests []*PropertyTest) *PropertyLinkage {
+	link := &PropertyLinkage{Properties: properties, Tests: make(map[int][]*PropertyTest)}
+	known := make(map[int]bool, len(properties))
+	for _, property := range properties {
+		known[property.Number] = true
+	}
+	for _, test := range tests {
+		if !known[test.Property] {
+			link.Unknown = append(link.Unknown, test)
+			continue
+		}
+		link.Tests[test.Property] = append(link.Tests[test.Property], test)
+	}
+	return link
+}
+
+// Missing retorna as propriedades do design sem teste
+func (l *PropertyLinkage) Missing() []*SpecProperty {
+	var missing []*SpecProperty
+	for _, property := range l.Properties {
+		if len(l.Tests[property.Number]) == 0 {
+			missing = append(missing, property)
+		}
+	}
+	return missing
+}
+
+// Duplicated retorna as propriedades do design com mais de um teste
+func (l *PropertyLinkage) Duplicated() []*SpecProperty {
+	var duplicated []*SpecProperty
+	for _, property := range l.Properties {
+		if len(l.Tests[property.Number]) > 1 {
+			duplicated = append(duplicated, property)
+		}
+	}
+	return duplicated
+}
+
+// Vacuous retorna os testes cujas verificações ignoram os argumentos gerados
+func (l *PropertyLinkage) Vacuous() []*PropertyTest {
+	var vacuous []*PropertyTest
+	for _, property := range l.Properties {
+		for _, test := range l.Tests[property.Number] {
+			if test.Vacuous() {
+				vacuous = append(vacuous, test)
+			}
+		}
+	}
+	return vacuous
+}
+
+// AddPropertyLinkage registra no relatório as falhas de ligação entre design e
+// testes (erros) e as propriedades vazias (avisos)
+func (r *Report) AddPropertyLinkage(checkID string, link *PropertyLinkage) {
+	design := GetDesignPath()
+	for _, property := range link.Missing() {
+		r.Add(&Finding{
+			CheckID:  checkID,
+			Severity: SeverityError,
+			File:     design,
+			Line:     property.Line,
+			Message:  fmt.Sprintf("Propriedade %d (%s) não possui teste de propriedade", property.Number, property.Title),
+		})
+	}
+	for _, property := range link.Duplicated() {
+		names := make([]string, 0)
+		for _, test := range link.Tests[property.Number] {
+			names = append(names, test.Name)
+		}
+		r.Add(&Finding{
+			CheckID:  checkID,
+			Severity: SeverityError,
+			File:     design,
+			Line:     property.Line,
+			Message: fmt.Sprintf("Propriedade %d (%s) possui mais de um teste: %s",
+				property.Number, property.Title, strings.Join(names, ", ")),
+		})
+	}
+	for _, test := range link.Unknown {
+		r.Add(&Finding{
+			CheckID:  checkID,
+			Severity: SeverityError,
+			File:     test.File,
+			Line:     test.Line,
+			Message:  fmt.Sprintf("%s cita a Propriedade %d, inexistente no design", test.Name, test.Property),
+		})
+	}
+	for _, test := range link.Vacuous() {
+		for _, check := range test.Checks {
+			message := ""
+			switch {
+			case len(check.Arguments) == 0:
+				message = "não recebe argumentos gerados"
+			case len(check.Unused) > 0:
+				message = "não usa os argumentos gerados " + strings.Join(check.Unused, ", ")
+			default:
+				continue
+			}
+			r.Add(&Finding{
+				CheckID:  checkID,
+				Severity: SeverityWarning,
+				File:     test.File,
+				Line:     check.Line,
+				Message:  fmt.Sprintf("%s (Propriedade %d) %q %s", test.Name, test.Property, check.Description, message),
+			})
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package helpers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Severity é a severidade de um achado, com os mesmos níveis do SARIF
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// severityRank ordena as severidades da menos para a mais grave
+var severityRank = map[Severity]int{
+	SeverityNote:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// ParseSeverity converte o nome de uma severidade, aceitando "info" como sinônimo de "note"
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "error":
+		return SeverityError, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "note", "info":
+		return SeverityNote, nil
+	}
+	return "", fmt.Errorf("severidade desconhecida %q (use error, warning ou note)", name)
+}
+
+// AtLeast indica se a severidade é igual ou mais grave que outra
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRank[s] >= severityRank[other]
+}
+
+// Check descreve uma verificação executada sobre o repositório
+type Check struct {
+	ID          string `json:"id"`
+	Requirement string `json:"requirement,omitempty"`
+	Description string `json:"description"`
+}
+
+// Finding é o resultado de uma verificação que falhou, localizado em um arquivo
+type Finding struct {
+	CheckID     string   `json:"check_id"`
+	Requirement string   `json:"requirement,omitempty"`
+	Severity    Severity `json:"severity"`
+	File        string   `json:"file,omitempty"`
+	Line        int      `json:"line,omitempty"`
+	Message     string   `json:"message"`
+	// Suppression é a supressão que aceitou o achado; achados suprimidos não
+	// reprovam a execução
+	Suppression *Suppression `json:"suppression,omitempty"`
+}
+
+func (f *Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	if location == "" {
+		return fmt.Sprintf("[%s] %s: %s", f.Severity, f.CheckID, f.Message)
+	}
+	return fmt.Sprintf("%s: [%s] %s: %s", location, f.Severity, f.CheckID, f.Message)
+}
+
+// Report agrupa as verificações executadas e os achados de uma execução
+type Report struct {
+	// Root é usado para gravar os caminhos relativos à raiz do repositório
+	Root     string     `json:"-"`
+	Checks   []*Check   `json:"checks"`
+	Findings []*Finding `json:"findings"`
+	// Inputs são os arquivos e diretórios examinados, onde são procuradas as
+	// supressões inline
+	Inputs []string `json:"-"`
+}
+
+// NewReport cria um relatório com caminhos relativos à raiz informada
+func NewReport(root string) *Report {
+	return &Report{Root: root}
+}
+
+// AddCheck registra uma verificação executada, mesmo que não produza achados
+func (r *Report) AddCheck(check *Check) {
+	for _, existing := range r.Checks {
+		if existing.ID == check.ID {
+			return
+		}
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// AddInputs registra arquivos ou diretórios examinados pelas verificações
+func (r *Report) AddInputs(paths ...string) {
+	for _, path := range paths {
+		known := false
+		for _, existing := range r.Inputs {
+			known = known || existing == path
+		}
+		if !known {
+			r.Inputs = append(r.Inputs, path)
+		}
+	}
+}
+
+// Add registra um achado; a verificação correspondente deve ter sido registrada
+func (r *Report) Add(finding *Finding) {
+	if finding.Requirement == "" {
+		if check := r.check(finding.CheckID); check != nil {
+			finding.Requirement = check.Requirement
+		}
+	}
+	r.Findings = append(r.Findings, finding)
+}
+
+// AddDiagnostics converte diagnósticos HCL em achados da verificação informada
+func (r *Report) AddDiagnostics(checkID string, diags hcl.Diagnostics) {
+	for _, diag := range diags {
+		finding := &Finding{CheckID: checkID, Severity: SeverityError, Message: diag.Summary}
+		if diag.Severity == hcl.DiagWarning {
+			finding.Severity = SeverityWarning
+		}
+		if diag.Detail != "" {
+			finding.Message += ": " + diag.Detail
+		}
+		if diag.Subject != nil {
+			finding.File = diag.Subject.Filename
+			finding.Line = diag.Subject.Start.Line
+		}
+		r.Add(finding)
+	}
+}
+
+// AddAPIFindings converte APIs removidas encontradas nos manifestos em achados
+func (r *Report) AddAPIFindings(checkID string, findings []*APIFinding) {
+	for _, finding := range findings {
+		r.Add(&Finding{
+			CheckID:  checkID,
+			Severity: SeverityError,
+			File:     finding.Manifest.File,
+			Line:     finding.Manifest.Line,
+			Message: fmt.Sprintf("%s %s/%s removido em %s, migrar para %s",
+				finding.Manifest.Kind, finding.Manifest.APIVersion, finding.Manifest.Name,
+				finding.Removal.RemovedIn, finding.Removal.Replacement),
+		})
+	}
+}
+
+// check retorna a verificação registrada com o ID informado
+func (r *Report) check(id string) *Check {
+	for _, check := range r.Checks {
+		if check.ID == id {
+			return check
+		}
+	}
+	return nil
+}
+
+// Failed indica se algum achado não suprimido tem severidade igual ou mais grave
+// que o limite
+func (r *Report) Failed(threshold Severity) bool {
+	for _, finding := range r.Findings {
+		if finding.Suppression == nil && finding.Severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativePath converte o caminho para relativo à raiz, com separador "/"
+func (r *Report) relativePath(path string) string {
+	if path == "" || r.Root == "" {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(r.Root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sorted retorna as verificações e os achados ordenados por ID, arquivo e linha,
+// com caminhos relativos à raiz
+func (r *Report) sorted() ([]*Check, []*Finding) {
+	checks := append([]*Check(nil), r.Checks...)
+	known := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		known[check.ID] = true
+	}
+	// Achados de verificações não registradas ganham uma verificação sem descrição
+	for _, finding := range r.Findings {
+		if !known[finding.CheckID] {
+			known[finding.CheckID] = true
+			checks = append(checks, &Check{ID: finding.CheckID, Requirement: finding.Requirement})
+		}
+	}
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].ID < checks[j].ID })
+
+	findings := make([]*Finding, 0, len(r.Findings))
+	for _, finding := range r.Findings {
+		copied := *finding
+		copied.File = r.relativePath(finding.File)
+		findings = append(findings, &copied)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.CheckID != b.CheckID {
+			return a.CheckID < b.CheckID
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return checks, findings
+}
+
+// WriteJSON grava o relatório como JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	checks, findings := r.sorted()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Checks   []*Check   `json:"checks"`
+		Findings []*Finding `json:"findings"`
+	}{checks, findings})
+}
+
+// WriteText grava um achado não suprimido por linha, seguido de um resumo por severidade
+func (r *Report) WriteText(w io.Writer) error {
+	checks, findings := r.sorted()
+	counts := make(map[Severity]int)
+	suppressed := 0
+	for _, finding := range findings {
+		if finding.Suppression != nil {
+			suppressed++
+			continue
+		}
+		counts[finding.Severity]++
+		if _, err := fmt.Fprintln(w, finding.String()); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d verificações, %d achados (%d error, %d warning, %d note), %d suprimidos\n",
+		len(checks), len(findings)-suppressed, counts[SeverityError], counts[SeverityWarning], counts[SeverityNote], suppressed)
+	return err
+}
+
+// junitTestSuites é a raiz de um relatório JUnit XML
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit grava o relatório como JUnit XML: uma suíte por verificação, um caso
+// por achado (ignorado, se suprimido) e um caso aprovado para verificações sem achados
+func (r *Report) WriteJUnit(w io.Writer) error {
+	checks, findings := r.sorted()
+
+	byCheck := make(map[string][]*Finding)
+	for _, finding := range findings {
+		byCheck[finding.CheckID] = append(byCheck[finding.CheckID], finding)
+	}
+
+	root := junitTestSuites{}
+	for _, check := range checks {
+		suite := junitTestSuite{Name: check.ID}
+		for _, finding := range byCheck[check.ID] {
+			name := finding.File
+			if finding.Line > 0 {
+				name = fmt.Sprintf("%s:%d", finding.File, finding.Line)
+			}
+			if name == "" {
+				name = check.ID
+			}
+			testCase := junitTestCase{Name: name, ClassName: check.ID, File: finding.File, Line: finding.Line}
+			if finding.Suppression != nil {
+				testCase.Skipped = &junitSkipped{Message: "suprimido: " + finding.Suppression.Reason}
+			} else {
+				testCase.Failure = &junitFailure{
+					Message: finding.Message,
+					Type:    string(finding.Severity),
+					Text:    finding.String(),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: check.ID, ClassName: check.ID})
+		}
+		suite.Tests = len(suite.Cases)
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Suites = append(root.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// sarifVersion e sarifSchema identificam o formato SARIF 2.1.0
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string            `json:"id"`
+	ShortDescription sarifMessage      `json:"shortDescription"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	Properties   map[string]string  `json:"properties,omitempty"`
+}
+
+// sarifSuppression marca o resultado como aceito: inSource para comentários
+// inline e external para o baseline
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF grava o relatório como SARIF 2.1.0, formato usado pelo GitHub code
+// scanning para anotar a linha do achado no PR
+func (r *Report) WriteSARIF(w io.Writer, tool string) error {
+	checks, findings := r.sorted()
+
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: tool}}, Results: []sarifResult{}}
+	ruleIndex := make(map[string]int, len(checks))
+	for i, check := range checks {
+		rule := sarifRule{ID: check.ID, ShortDescription: sarifMessage{Text: check.Description}}
+		if rule.ShortDescription.Text == "" {
+			rule.ShortDescription.Text = check.ID
+		}
+		if check.Requirement != "" {
+			rule.Properties = map[string]string{"requirement": check.Requirement}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		ruleIndex[check.ID] = i
+	}
+
+	for _, finding := range findings {
+		result := sarifResult{
+			RuleID:    finding.CheckID,
+			RuleIndex: ruleIndex[finding.CheckID],
+			Level:     string(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+		}
+		if finding.Requirement != "" {
+			result.Properties = map[string]string{"requirement": finding.Requirement}
+		}
+		if s := finding.Suppression; s != nil {
+			kind := "external"
+			if s.Inline {
+				kind = "inSource"
+			}
+			result.Suppressions = []sarifSuppression{{Kind: kind, Justification: s.Reason}}
+		}
+		if finding.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: finding.File},
+			}}
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
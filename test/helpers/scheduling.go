@@ -0,0 +1,390 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Toleration representa uma toleration de pod (como renderizada nos values dos charts)
+type Toleration struct {
+	Key      string
+	Operator string
+	Value    string
+	Effect   string
+}
+
+// NodeGroup representa um node group com os labels e taints aplicados aos nodes
+type NodeGroup struct {
+	Name   string
+	Labels map[string]string
+	Taints []Taint
+}
+
+// PodPlacement representa as regras de agendamento de um workload de um chart
+type PodPlacement struct {
+	Release      string
+	Chart        string
+	Component    string
+	DaemonSet    bool
+	NodeSelector map[string]string
+	Tolerations  []Toleration
+	// Replicas é a quantidade de pods (por node, em DaemonSets)
+	Replicas int
+	// Requests são os resources.requests de cada pod; nil quando os values não declaram
+	Requests *Resources
+	// Job indica um hook executado uma vez, que não ocupa capacidade permanente
+	Job bool
+}
+
+// Name retorna o identificador do workload (ex: module.argocd.helm_release.argocd:controller)
+func (p *PodPlacement) Name() string {
+	if p.Component == "" {
+		return p.Release
+	}
+	return p.Release + ":" + p.Component
+}
+
+// ChartWorkload descreve onde um chart lê nodeSelector/tolerations de um workload
+type ChartWorkload struct {
+	// Path é o caminho nos values do bloco do workload ("" para a raiz)
+	Path string
+	// Enabled é o caminho da flag que habilita o workload, quando diferente de Path.enabled
+	Enabled string
+	// Fallback é o bloco usado quando o workload não define nodeSelector/tolerations
+	Fallback  string
+	DaemonSet bool
+	// Replicas é o padrão do chart quando os values não definem replicas/replicaCount (0 = 1)
+	Replicas int
+	Job      bool
+}
+
+// chartWorkloads lista os workloads de cada chart instalado pelos módulos de plataforma
+var chartWorkloads = map[string][]ChartWorkload{
+	"argo-cd": {
+		{Path: "controller", Fallback: "global"},
+		{Path: "server", Fallback: "global"},
+		{Path: "repoServer", Fallback: "global"},
+		{Path: "redis", Fallback: "global"},
+		{Path: "dex", Fallback: "global"},
+		{Path: "applicationSet", Fallback: "global"},
+		{Path: "notifications", Fallback: "global"},
+	},
+	"external-secrets": {
+		{Path: ""},
+		{Path: "webhook"},
+		{Path: "certController"},
+	},
+	"aws-load-balancer-controller": {
+		{Path: "", Replicas: 2},
+	},
+	"cert-manager": {
+		{Path: ""},
+		{Path: "webhook"},
+		{Path: "cainjector"},
+		{Path: "startupapicheck", Job: true},
+	},
+	"external-dns": {
+		{Path: ""},
+	},
+	"kube-prometheus-stack": {
+		{Path: "prometheus.prometheusSpec", Enabled: "prometheus.enabled"},
+		{Path: "alertmanager.alertmanagerSpec", Enabled: "alertmanager.enabled"},
+		{Path: "grafana"},
+		{Path: "prometheusOperator"},
+		{Path: "kube-state-metrics", Enabled: "kubeStateMetrics.enabled"},
+		{Path: "prometheus-node-exporter", Enabled: "nodeExporter.enabled", DaemonSet: true},
+	},
+	"loki": {
+		{Path: "singleBinary"},
+		{Path: "read"},
+		{Path: "write"},
+		{Path: "backend"},
+		{Path: "gateway"},
+	},
+	"promtail": {
+		{Path: "", DaemonSet: true},
+	},
+	"opentelemetry-collector": {
+		{Path: ""},
+	},
+	"kyverno": {
+		{Path: "admissionController"},
+		{Path: "backgroundController"},
+		{Path: "cleanupController"},
+		{Path: "reportsController"},
+	},
+	"gatekeeper": {
+		{Path: "controllerManager"},
+		{Path: "audit"},
+	},
+	"ingress-nginx": {
+		{Path: "controller"},
+		{Path: "defaultBackend"},
+	},
+	"velero": {
+		{Path: ""},
+	},
+}
+
+// HelmPlacements retorna as regras de agendamento dos workloads habilitados de um release
+func HelmPlacements(release *HelmRelease) ([]*PodPlacement, error) {
+	workloads, ok := chartWorkloads[release.Chart]
+	if !ok {
+		return nil, fmt.Errorf("%s: workloads do chart %s não catalogados", release.Address, release.Chart)
+	}
+
+	var placements []*PodPlacement
+	for _, workload := range workloads {
+		block, _ := lookupHelmValue(release.Values, workload.Path).(map[string]interface{})
+		if !workloadEnabled(release.Values, workload, block) {
+			continue
+		}
+
+		placement := &PodPlacement{
+			Release:   release.Address,
+			Chart:     release.Chart,
+			Component: workload.Path,
+			DaemonSet: workload.DaemonSet,
+			Replicas:  workloadReplicas(block, workload),
+			Job:       workload.Job,
+		}
+		var fallback map[string]interface{}
+		if workload.Fallback != "" {
+			fallback, _ = lookupHelmValue(release.Values, workload.Fallback).(map[string]interface{})
+		}
+
+		selector, ok := block["nodeSelector"]
+		if !ok {
+			selector = fallback["nodeSelector"]
+		}
+		nodeSelector, err := parseNodeSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", placement.Name(), err)
+		}
+		placement.NodeSelector = nodeSelector
+
+		tolerations, ok := block["tolerations"]
+		if !ok {
+			tolerations = fallback["tolerations"]
+		}
+		parsed, err := parseTolerations(tolerations)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", placement.Name(), err)
+		}
+		placement.Tolerations = parsed
+
+		requests, err := parseRequests(lookupHelmValue(block, "resources.requests"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", placement.Name(), err)
+		}
+		placement.Requests = requests
+
+		placements = append(placements, placement)
+	}
+	return placements, nil
+}
+
+// workloadEnabled considera desabilitados os workloads com enabled = false ou zero réplicas
+func workloadEnabled(values map[string]interface{}, workload ChartWorkload, block map[string]interface{}) bool {
+	if workload.Enabled != "" {
+		if enabled, ok := lookupHelmValue(values, workload.Enabled).(bool); ok && !enabled {
+			return false
+		}
+	}
+	if enabled, ok := block["enabled"].(bool); ok && !enabled {
+		return false
+	}
+	for _, key := range []string{"replicas", "replicaCount"} {
+		if replicas, ok := toFloat(block[key]); ok && replicas == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// workloadReplicas retorna replicas/replicaCount dos values ou o padrão do chart
+func workloadReplicas(block map[string]interface{}, workload ChartWorkload) int {
+	for _, key := range []string{"replicas", "replicaCount"} {
+		if replicas, ok := toFloat(block[key]); ok {
+			return int(replicas)
+		}
+	}
+	if workload.Replicas > 0 {
+		return workload.Replicas
+	}
+	return 1
+}
+
+// lookupHelmValue retorna o valor em um caminho separado por pontos ("" retorna a raiz)
+func lookupHelmValue(values map[string]interface{}, path string) interface{} {
+	if path == "" {
+		return values
+	}
+	var current interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+func parseNodeSelector(value interface{}) (map[string]string, error) {
+	selector := make(map[string]string)
+	if value == nil {
+		return selector, nil
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("nodeSelector deve ser um mapa")
+	}
+	for key, v := range m {
+		selector[key] = fmt.Sprint(v)
+	}
+	return selector, nil
+}
+
+func parseTolerations(value interface{}) ([]Toleration, error) {
+	if value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tolerations deve ser uma lista")
+	}
+	tolerations := make([]Toleration, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tolerations[%d] deve ser um objeto", i)
+		}
+		toleration := Toleration{}
+		toleration.Key, _ = m["key"].(string)
+		toleration.Operator, _ = m["operator"].(string)
+		toleration.Value, _ = m["value"].(string)
+		toleration.Effect, _ = m["effect"].(string)
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
+// NodeGroupsFromInstances extrai os node groups (aws_eks_node_group) de instâncias avaliadas,
+// incluindo os labels adicionados automaticamente pelo EKS
+func NodeGroupsFromInstances(instances []*ResourceInstance) []*NodeGroup {
+	var groups []*NodeGroup
+	for _, inst := range instances {
+		if inst.Resource.Mode != "managed" || inst.Resource.Type != "aws_eks_node_group" {
+			continue
+		}
+		groups = append(groups, nodeGroupFromInstance(inst))
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// nodeGroupFromInstance monta o NodeGroup de uma instância aws_eks_node_group
+func nodeGroupFromInstance(inst *ResourceInstance) *NodeGroup {
+	attrs := inst.Values()
+	group := &NodeGroup{
+		Name: instanceName(inst),
+		Labels: map[string]string{
+			"kubernetes.io/os":            "linux",
+			"eks.amazonaws.com/nodegroup": fmt.Sprint(attrs["node_group_name"]),
+		},
+	}
+	if labels, ok := attrs["labels"].(map[string]interface{}); ok {
+		for key, v := range labels {
+			group.Labels[key] = fmt.Sprint(v)
+		}
+	}
+	taints, _ := attrs["taint"].([]interface{})
+	for _, item := range taints {
+		m, _ := item.(map[string]interface{})
+		taint := Taint{}
+		taint.Key, _ = m["key"].(string)
+		taint.Value, _ = m["value"].(string)
+		taint.Effect, _ = m["effect"].(string)
+		group.Taints = append(group.Taints, taint)
+	}
+	return group
+}
+
+// instanceName retorna a chave de for_each da instância ou o nome do recurso
+func instanceName(inst *ResourceInstance) string {
+	if inst.Key != cty.NilVal && inst.Key.Type() == cty.String {
+		return inst.Key.AsString()
+	}
+	return inst.Resource.Name
+}
+
+// normalizeTaintEffect aceita tanto o formato do Kubernetes (NoSchedule) quanto
+// o da API do EKS (NO_SCHEDULE)
+func normalizeTaintEffect(effect string) string {
+	switch strings.ToUpper(strings.ReplaceAll(effect, "_", "")) {
+	case "NOSCHEDULE":
+		return "NoSchedule"
+	case "PREFERNOSCHEDULE":
+		return "PreferNoSchedule"
+	case "NOEXECUTE":
+		return "NoExecute"
+	}
+	return effect
+}
+
+// Tolerates indica se a toleration cobre o taint, com a semântica do scheduler do Kubernetes
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && normalizeTaintEffect(t.Effect) != normalizeTaintEffect(taint.Effect) {
+		return false
+	}
+	if t.Key == "" {
+		return t.Operator == "Exists"
+	}
+	if t.Key != taint.Key {
+		return false
+	}
+	if t.Operator == "Exists" {
+		return true
+	}
+	return t.Value == taint.Value
+}
+
+// Accepts indica se um pod com as regras de agendamento informadas pode rodar no node group
+func (g *NodeGroup) Accepts(p *PodPlacement) bool {
+	for key, value := range p.NodeSelector {
+		if g.Labels[key] != value {
+			return false
+		}
+	}
+	for _, taint := range g.Taints {
+		if normalizeTaintEffect(taint.Effect) == "PreferNoSchedule" {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range p.Tolerations {
+			if toleration.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// EligibleNodeGroups retorna os node groups em que o workload pode ser agendado
+func EligibleNodeGroups(p *PodPlacement, groups []*NodeGroup) []*NodeGroup {
+	var eligible []*NodeGroup
+	for _, group := range groups {
+		if group.Accepts(p) {
+			eligible = append(eligible, group)
+		}
+	}
+	return eligible
+}
@@ -0,0 +1,321 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema é o subconjunto de JSON Schema usado pelos values.schema.json dos charts:
+// type, properties, additionalProperties, items, required, enum, limites numéricos,
+// minLength, pattern e referências locais ($ref para $defs/definitions)
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*Schema `json:"$defs"`
+	Definitions          map[string]*Schema `json:"definitions"`
+	Type                 schemaTypes        `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Required             []string           `json:"required"`
+	Enum                 []interface{}      `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinItems             *int               `json:"minItems"`
+	MinLength            *int               `json:"minLength"`
+	Pattern              string             `json:"pattern"`
+
+	// allow guarda o valor de schemas booleanos (true aceita tudo, false nada)
+	allow *bool
+}
+
+// SchemaError descreve uma violação de schema em um caminho do documento
+type SchemaError struct {
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// schemaTypes aceita "type" como string ou lista de strings
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type deve ser string ou lista de strings")
+	}
+	*t = list
+	return nil
+}
+
+// UnmarshalJSON aceita schemas booleanos além de objetos
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var allow bool
+	if err := json.Unmarshal(data, &allow); err == nil {
+		s.allow = &allow
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// LoadSchema carrega um JSON Schema de um arquivo
+func LoadSchema(path string) (*Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema %s: %w", path, err)
+	}
+	var schema Schema
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("erro ao parsear schema %s: %w", path, err)
+	}
+	return &schema, nil
+}
+
+// GetChartSchemaPath retorna o caminho do values.schema.json vendorizado de um chart
+func GetChartSchemaPath(chart, version string) string {
+	return GetTestPath("testdata", "charts", chart, version, "values.schema.json")
+}
+
+// LoadChartSchema carrega o values.schema.json vendorizado de um chart em uma versão
+func LoadChartSchema(chart, version string) (*Schema, error) {
+	path := GetChartSchemaPath(chart, version)
+	if !FileExists(path) {
+		return nil, fmt.Errorf("schema não encontrado para o chart %s %s (esperado em %s)", chart, version, path)
+	}
+	return LoadSchema(path)
+}
+
+// Validate valida um documento (tipos nativos Go, como os retornados por yaml.Unmarshal)
+// e retorna todas as violações encontradas, ordenadas por caminho
+func (s *Schema) Validate(doc interface{}) []error {
+	var errs []*SchemaError
+	s.validate(s, doc, "", &errs)
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	result := make([]error, len(errs))
+	for i, err := range errs {
+		result[i] = err
+	}
+	return result
+}
+
+func (s *Schema) validate(root *Schema, value interface{}, path string, errs *[]*SchemaError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, &SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.allow != nil {
+		if !*s.allow {
+			fail("valor não permitido pelo schema")
+		}
+		return
+	}
+
+	if s.Ref != "" {
+		target, err := root.resolve(s.Ref)
+		if err != nil {
+			fail("%v", err)
+			return
+		}
+		target.validate(root, value, path, errs)
+		return
+	}
+
+	if len(s.Type) > 0 && !matchesAnyType(value, s.Type) {
+		fail("esperado %s, encontrado %s", strings.Join(s.Type, " ou "), jsonTypeName(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		fail("valor %v não está entre os permitidos %v", value, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("propriedade obrigatória %q ausente", name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinSchemaPath(path, key)
+			if prop, ok := s.Properties[key]; ok {
+				prop.validate(root, v[key], child, errs)
+				continue
+			}
+			if s.AdditionalProperties != nil {
+				if s.AdditionalProperties.allow != nil && !*s.AdditionalProperties.allow {
+					*errs = append(*errs, &SchemaError{Path: child, Message: "propriedade não reconhecida pelo schema"})
+					continue
+				}
+				s.AdditionalProperties.validate(root, v[key], child, errs)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("esperado ao menos %d itens, encontrado %d", *s.MinItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			fail("esperado ao menos %d caracteres", *s.MinLength)
+		}
+		if s.Pattern != "" && !IsComputedPlaceholder(v) {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				fail("pattern inválido no schema: %v", err)
+			} else if !re.MatchString(v) {
+				fail("valor %q não corresponde ao padrão %s", v, s.Pattern)
+			}
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				fail("valor %v menor que o mínimo %v", value, *s.Minimum)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				fail("valor %v maior que o máximo %v", value, *s.Maximum)
+			}
+		}
+	}
+}
+
+// resolve resolve referências locais no formato #/$defs/nome ou #/definitions/nome
+func (s *Schema) resolve(ref string) (*Schema, error) {
+	var defs map[string]*Schema
+	var name string
+	switch {
+	case strings.HasPrefix(ref, "#/$defs/"):
+		defs, name = s.Defs, strings.TrimPrefix(ref, "#/$defs/")
+	case strings.HasPrefix(ref, "#/definitions/"):
+		defs, name = s.Definitions, strings.TrimPrefix(ref, "#/definitions/")
+	default:
+		return nil, fmt.Errorf("referência não suportada: %s", ref)
+	}
+	target, ok := defs[name]
+	if !ok {
+		return nil, fmt.Errorf("referência não encontrada: %s", ref)
+	}
+	return target, nil
+}
+
+func joinSchemaPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return path + fmt.Sprintf("[%q]", key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	for _, t := range types {
+		if matchesType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(value interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	}
+	return false
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		a, aNum := toFloat(candidate)
+		b, bNum := toFloat(value)
+		if aNum && bNum {
+			if a == b {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
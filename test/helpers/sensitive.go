@@ -0,0 +1,368 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// SensitiveException é um nome que casa com o padrão de nomes sensíveis mas não guarda segredo
+type SensitiveException struct {
+	Name   string `yaml:"name"`
+	Reason string `yaml:"reason"`
+}
+
+// SensitiveConfig são as regras do rastreamento de valores sensíveis
+// (testdata/sensitive.yaml)
+type SensitiveConfig struct {
+	Names       string               `yaml:"names"`
+	Exceptions  []SensitiveException `yaml:"exceptions"`
+	Placeholder string               `yaml:"placeholder"`
+	// Attributes são os atributos que carregam segredos, por tipo de recurso
+	Attributes map[string][]string `yaml:"attributes"`
+
+	names       *regexp.Regexp
+	placeholder *regexp.Regexp
+}
+
+// GetSensitiveConfigPath retorna o caminho das regras de valores sensíveis
+func GetSensitiveConfigPath() string {
+	return GetTestPath("testdata", "sensitive.yaml")
+}
+
+// LoadSensitiveConfig lê as regras de valores sensíveis
+func LoadSensitiveConfig(path string) (*SensitiveConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &SensitiveConfig{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if config.names, err = regexp.Compile(config.Names); err != nil {
+		return nil, fmt.Errorf("%s: names: %w", path, err)
+	}
+	if config.placeholder, err = regexp.Compile(config.Placeholder); err != nil {
+		return nil, fmt.Errorf("%s: placeholder: %w", path, err)
+	}
+	for _, exception := range config.Exceptions {
+		if strings.TrimSpace(exception.Reason) == "" {
+			return nil, fmt.Errorf("%s: exceção %s sem reason", path, exception.Name)
+		}
+	}
+	return config, nil
+}
+
+// ShouldBeSensitive indica se uma variável ou output com esse nome deve ser sensitive
+func (c *SensitiveConfig) ShouldBeSensitive(name string) bool {
+	for _, exception := range c.Exceptions {
+		if exception.Name == name {
+			return false
+		}
+	}
+	return c.names.MatchString(name)
+}
+
+// IsPlaceholder indica se o valor de exemplo de uma variável sensitive é um placeholder
+func (c *SensitiveConfig) IsPlaceholder(value string) bool {
+	return c.placeholder.MatchString(value)
+}
+
+// SensitiveLeak é um valor sensível que chega a um destino em texto plano, ou
+// uma variável ou output que deveria ser sensitive
+type SensitiveLeak struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (l *SensitiveLeak) String() string {
+	return fmt.Sprintf("%s:%d: %s", l.File, l.Line, l.Message)
+}
+
+// FindSensitiveLeaks segue os valores sensíveis a partir da raiz dir: variáveis
+// sensitive, outputs sensitive de módulos e atributos secretos de recursos são
+// propagados por locals, argumentos de módulos e outputs. São vazamentos um valor
+// sensível em output sem sensitive, em tags, em `values` ou `set` de helm_release
+// (use set_sensitive), em variável de módulo sem sensitive, variáveis e outputs
+// com nome sensível sem sensitive e valores reais no terraform.tfvars.example
+func FindSensitiveLeaks(dir string, config *SensitiveConfig) ([]*SensitiveLeak, error) {
+	analysis := &sensitiveAnalysis{config: config, modules: make(map[string]*Module), seen: make(map[string]bool)}
+	mod, err := analysis.load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := analysis.module(mod, nil, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	if err := analysis.tfvars(mod, filepath.Join(dir, "terraform.tfvars.example")); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(analysis.leaks, func(i, j int) bool {
+		if analysis.leaks[i].File != analysis.leaks[j].File {
+			return analysis.leaks[i].File < analysis.leaks[j].File
+		}
+		return analysis.leaks[i].Line < analysis.leaks[j].Line
+	})
+	return analysis.leaks, nil
+}
+
+// sensitiveAnalysis guarda os módulos carregados e os vazamentos encontrados
+type sensitiveAnalysis struct {
+	config  *SensitiveConfig
+	modules map[string]*Module
+	leaks   []*SensitiveLeak
+	seen    map[string]bool
+}
+
+func (a *sensitiveAnalysis) load(dir string) (*Module, error) {
+	dir = filepath.Clean(dir)
+	if mod, ok := a.modules[dir]; ok {
+		return mod, nil
+	}
+	mod, err := LoadModule(dir)
+	if err != nil {
+		return nil, err
+	}
+	a.modules[dir] = mod
+	return mod, nil
+}
+
+func (a *sensitiveAnalysis) add(rng hcl.Range, format string, args ...interface{}) {
+	leak := &SensitiveLeak{File: rng.Filename, Line: rng.Start.Line, Message: fmt.Sprintf(format, args...)}
+	if key := leak.String(); !a.seen[key] {
+		a.seen[key] = true
+		a.leaks = append(a.leaks, leak)
+	}
+}
+
+// sensitiveScope é a origem de cada valor sensível visível em um módulo
+type sensitiveScope struct {
+	vars    map[string]string
+	locals  map[string]string
+	outputs map[string]map[string]string
+}
+
+// origin retorna a origem do primeiro valor sensível referenciado pela expressão, ou ""
+func (a *sensitiveAnalysis) origin(expr hcl.Expression, scope *sensitiveScope) string {
+	if expr == nil {
+		return ""
+	}
+	for _, traversal := range expr.Variables() {
+		names := traversalNames(traversal)
+		if len(names) < 2 {
+			continue
+		}
+		switch names[0] {
+		case "var":
+			if origin := scope.vars[names[1]]; origin != "" {
+				return origin
+			}
+		case "local":
+			if origin := scope.locals[names[1]]; origin != "" {
+				return origin
+			}
+		case "module":
+			// Sem o nome do output (ex: module.x[0].y), qualquer output sensível conta
+			for output, origin := range scope.outputs[names[1]] {
+				if len(names) == 2 || names[2] == output {
+					return origin
+				}
+			}
+		case "data", "count", "each", "path", "terraform", "self":
+		default:
+			for _, attr := range a.config.Attributes[names[0]] {
+				if len(names) == 2 || names[2] == attr {
+					return fmt.Sprintf("%s.%s.%s", names[0], names[1], attr)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// module analisa um módulo com as variáveis sensíveis recebidas do módulo pai e
+// retorna a origem de cada output sensível; stack evita recursão em ciclos
+func (a *sensitiveAnalysis) module(mod *Module, inbound map[string]string, stack map[string]bool) (map[string]string, error) {
+	if stack[mod.Dir] {
+		return nil, nil
+	}
+	stack[mod.Dir] = true
+	defer delete(stack, mod.Dir)
+
+	scope := &sensitiveScope{vars: make(map[string]string), locals: make(map[string]string), outputs: make(map[string]map[string]string)}
+	for name, v := range mod.Variables {
+		switch {
+		case inbound[name] != "":
+			scope.vars[name] = inbound[name]
+		case v.Sensitive:
+			scope.vars[name] = "var." + name
+		}
+		if !v.Sensitive && a.config.ShouldBeSensitive(name) {
+			a.add(v.Range, "variável %s deve ser sensitive", name)
+		}
+	}
+
+	// Locals até o ponto fixo, pois podem referenciar uns aos outros e outputs de módulos
+	calls := make([]string, 0, len(mod.ModuleCalls))
+	for name := range mod.ModuleCalls {
+		calls = append(calls, name)
+	}
+	sort.Strings(calls)
+	for changed := true; changed; {
+		changed = false
+		for name, local := range mod.Locals {
+			if scope.locals[name] == "" {
+				if origin := a.origin(local.Expr, scope); origin != "" {
+					scope.locals[name] = origin
+					changed = true
+				}
+			}
+		}
+		for _, name := range calls {
+			outputs, err := a.call(mod, mod.ModuleCalls[name], scope, stack, false)
+			if err != nil {
+				return nil, err
+			}
+			for output, origin := range outputs {
+				if scope.outputs[name] == nil {
+					scope.outputs[name] = make(map[string]string)
+				}
+				if scope.outputs[name][output] == "" {
+					scope.outputs[name][output] = origin
+					changed = true
+				}
+			}
+		}
+	}
+	for _, name := range calls {
+		if _, err := a.call(mod, mod.ModuleCalls[name], scope, stack, true); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range mod.SortedResources() {
+		a.resource(r, scope)
+	}
+
+	outputs := make(map[string]string)
+	for name, output := range mod.Outputs {
+		origin := a.origin(output.Expr, scope)
+		if origin != "" && !output.Sensitive {
+			a.add(output.Range, "output %s expõe %s e deve ser sensitive", name, origin)
+		}
+		if origin == "" && !output.Sensitive && a.config.ShouldBeSensitive(name) {
+			a.add(output.Range, "output %s deve ser sensitive", name)
+		}
+		switch {
+		case origin != "":
+			outputs[name] = origin
+		case output.Sensitive:
+			outputs[name] = fmt.Sprintf("output %s de %s", name, filepath.Base(mod.Dir))
+		}
+	}
+	return outputs, nil
+}
+
+// call propaga os argumentos sensíveis de um bloco module para o módulo filho;
+// com report, registra os argumentos sensíveis que chegam a variáveis sem sensitive
+func (a *sensitiveAnalysis) call(mod *Module, call *ModuleCall, scope *sensitiveScope, stack map[string]bool, report bool) (map[string]string, error) {
+	if !strings.HasPrefix(call.Source, "./") && !strings.HasPrefix(call.Source, "../") {
+		return nil, nil
+	}
+	child, err := a.load(filepath.Join(mod.Dir, call.Source))
+	if err != nil {
+		return nil, err
+	}
+	inbound := make(map[string]string)
+	for name, attr := range call.Body.Attributes {
+		if moduleMetaArguments[name] {
+			continue
+		}
+		origin := a.origin(attr.Expr, scope)
+		if origin == "" {
+			continue
+		}
+		inbound[name] = origin
+		if !report {
+			continue
+		}
+		if name == "tags" {
+			a.add(attr.SrcRange, "module.%s: %s chega às tags", call.Name, origin)
+		}
+		if v, ok := child.Variables[name]; ok && !v.Sensitive {
+			a.add(v.Range, "variável %s recebe %s (module.%s) e deve ser sensitive", name, origin, call.Name)
+		}
+	}
+	if report {
+		return nil, nil
+	}
+	return a.module(child, inbound, stack)
+}
+
+// resource registra valores sensíveis em tags e nos values de helm_release
+func (a *sensitiveAnalysis) resource(r *Resource, scope *sensitiveScope) {
+	var walk func(body *hclsyntax.Body, path string)
+	walk = func(body *hclsyntax.Body, path string) {
+		for name, attr := range body.Attributes {
+			origin := a.origin(attr.Expr, scope)
+			if origin == "" {
+				continue
+			}
+			switch {
+			case name == "tags" || name == "tags_all" || (name == "value" && path == "tag"):
+				a.add(attr.SrcRange, "%s: %s chega às tags", r.Address(), origin)
+			case r.Type == "helm_release" && path == "" && name == "values":
+				a.add(attr.SrcRange, "%s: %s em values fica em texto plano; use set_sensitive", r.Address(), origin)
+			case r.Type == "helm_release" && path == "set" && name == "value":
+				a.add(attr.SrcRange, "%s: %s em set fica em texto plano; use set_sensitive", r.Address(), origin)
+			}
+		}
+		for _, block := range body.Blocks {
+			blockPath := block.Type
+			if block.Type == "dynamic" && len(block.Labels) > 0 {
+				blockPath = block.Labels[0]
+			}
+			walk(block.Body, blockPath)
+		}
+	}
+	walk(r.Body, "")
+}
+
+// tfvars registra valores que não são placeholders para variáveis sensíveis no exemplo de tfvars
+func (a *sensitiveAnalysis) tfvars(mod *Module, path string) error {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	file, diags := hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("erro ao parsear HCL: %s", diags.Error())
+	}
+	for name, attr := range file.Body.(*hclsyntax.Body).Attributes {
+		v, declared := mod.Variables[name]
+		if !(declared && v.Sensitive) && !a.config.ShouldBeSensitive(name) {
+			continue
+		}
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.IsNull() {
+			continue
+		}
+		if val.Type() != cty.String || !a.config.IsPlaceholder(val.AsString()) {
+			a.add(attr.SrcRange, "%s é sensitive e deve ter um placeholder no exemplo, não um valor", name)
+		}
+	}
+	return nil
+}
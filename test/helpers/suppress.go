@@ -0,0 +1,283 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionDirective é o comentário que suprime um achado na mesma linha ou
+// na linha seguinte (ex: "# templatecheck:ignore eks-public-endpoint motivo")
+const SuppressionDirective = "templatecheck:ignore"
+
+// suppressionDateLayout é o formato de Expires (AAAA-MM-DD)
+const suppressionDateLayout = "2006-01-02"
+
+var suppressionComment = regexp.MustCompile(`(?:#|//)\s*` + SuppressionDirective + `(?:\s+(\S+))?(?:\s+(.*))?$`)
+
+// suppressibleExtensions são os arquivos procurados por supressões inline
+var suppressibleExtensions = map[string]bool{
+	".tf": true, ".tfvars": true, ".example": true, ".yaml": true, ".yml": true,
+}
+
+// Suppression aceita achados conhecidos de uma verificação. Supressões inline
+// valem para o arquivo e a linha do comentário; as do baseline, para os achados
+// cujo arquivo e mensagem correspondem a File e Match.
+type Suppression struct {
+	CheckID string `yaml:"check" json:"check"`
+	// File é um glob relativo à raiz; sem "/", é comparado ao nome do arquivo
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+	// Match é um trecho da mensagem do achado (ex: endereço do recurso)
+	Match  string `yaml:"match,omitempty" json:"match,omitempty"`
+	Reason string `yaml:"reason" json:"reason"`
+	// Expires é a data (AAAA-MM-DD) a partir da qual a supressão reprova a execução
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty"`
+
+	// Source e Line localizam a supressão (comentário inline ou arquivo de baseline)
+	Source string `yaml:"-" json:"source"`
+	Line   int    `yaml:"-" json:"line,omitempty"`
+	Inline bool   `yaml:"-" json:"inline"`
+
+	used bool
+}
+
+// matches indica se a supressão se aplica ao achado; relPath é o arquivo do
+// achado relativo à raiz
+func (s *Suppression) matches(finding *Finding, relPath string) bool {
+	if s.CheckID != finding.CheckID {
+		return false
+	}
+	if s.Inline {
+		return filepath.Clean(finding.File) == filepath.Clean(s.Source) && (finding.Line == s.Line || finding.Line == s.Line+1)
+	}
+	if s.File != "" && !globMatch(s.File, relPath) {
+		return false
+	}
+	return s.Match == "" || strings.Contains(finding.Message, s.Match)
+}
+
+// globMatch compara um glob com um caminho relativo com separador "/"; padrões
+// sem "/" são comparados apenas ao nome do arquivo
+func globMatch(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		relPath = path.Base(relPath)
+	}
+	ok, _ := path.Match(pattern, relPath)
+	return ok
+}
+
+// Baseline é o arquivo de achados aceitos, cada um com motivo e data de expiração
+type Baseline struct {
+	Accepted []*Suppression `yaml:"accepted"`
+}
+
+// LoadBaseline lê o arquivo de baseline; arquivo inexistente equivale a um baseline vazio
+func LoadBaseline(path string) ([]*Suppression, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	baseline := &Baseline{}
+	if err := node.Decode(baseline); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// A linha de cada entrada localiza os achados de supressões inválidas ou sem uso
+	var lines []int
+	if len(node.Content) > 0 {
+		for i := 0; i+1 < len(node.Content[0].Content); i += 2 {
+			if node.Content[0].Content[i].Value == "accepted" {
+				for _, item := range node.Content[0].Content[i+1].Content {
+					lines = append(lines, item.Line)
+				}
+			}
+		}
+	}
+	for i, s := range baseline.Accepted {
+		s.Source = path
+		if i < len(lines) {
+			s.Line = lines[i]
+		}
+	}
+	return baseline.Accepted, nil
+}
+
+// InlineSuppressions procura comentários templatecheck:ignore nos arquivos .tf,
+// .tfvars e YAML dos caminhos informados (arquivos ou diretórios)
+func InlineSuppressions(paths ...string) ([]*Suppression, error) {
+	var suppressions []*Suppression
+	seen := make(map[string]bool)
+	for _, root := range paths {
+		err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if strings.HasPrefix(info.Name(), ".") && file != root {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if seen[file] || !suppressibleExtensions[filepath.Ext(file)] {
+				return nil
+			}
+			seen[file] = true
+			found, err := fileSuppressions(file)
+			suppressions = append(suppressions, found...)
+			return err
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return suppressions, nil
+}
+
+// fileSuppressions retorna as supressões inline de um arquivo
+func fileSuppressions(file string) ([]*Suppression, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var suppressions []*Suppression
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		match := suppressionComment.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		suppressions = append(suppressions, &Suppression{
+			CheckID: match[1],
+			Reason:  strings.TrimSpace(match[2]),
+			Source:  file,
+			Line:    line,
+			Inline:  true,
+		})
+	}
+	return suppressions, scanner.Err()
+}
+
+// ApplySuppressions marca os achados aceitos por supressões válidas e reporta
+// como erro as supressões sem motivo, de verificações desconhecidas, expiradas
+// ou que não suprimiram nenhum achado. Supressões de verificações que não
+// rodaram, ou de arquivos fora das entradas do relatório, são ignoradas.
+func (r *Report) ApplySuppressions(suppressions []*Suppression, today time.Time) {
+	r.addChecks(CheckSuppression)
+
+	var active []*Suppression
+	for _, s := range suppressions {
+		if problem := s.problem(today); problem != "" {
+			r.Add(&Finding{
+				CheckID: CheckSuppression, Severity: SeverityError, File: s.Source, Line: s.Line,
+				Message: fmt.Sprintf("supressão de %s %s", s.CheckID, problem),
+			})
+			continue
+		}
+		if r.check(s.CheckID) != nil && r.inScope(s) {
+			active = append(active, s)
+		}
+	}
+
+	for _, finding := range r.Findings {
+		if finding.CheckID == CheckSuppression || finding.Suppression != nil {
+			continue
+		}
+		relPath := r.relativePath(finding.File)
+		for _, s := range active {
+			if s.matches(finding, relPath) {
+				s.used = true
+				finding.Suppression = s
+				break
+			}
+		}
+	}
+
+	for _, s := range active {
+		if !s.used {
+			r.Add(&Finding{
+				CheckID: CheckSuppression, Severity: SeverityError, File: s.Source, Line: s.Line,
+				Message: fmt.Sprintf("supressão de %s não corresponde a nenhum achado; remova-a", s.CheckID),
+			})
+		}
+	}
+}
+
+// problem descreve por que a supressão é inválida, ou retorna vazio
+func (s *Suppression) problem(today time.Time) string {
+	if s.CheckID == "" {
+		return "sem ID da verificação"
+	}
+	if _, ok := checkCatalog[s.CheckID]; !ok {
+		return "cita verificação desconhecida"
+	}
+	if s.Reason == "" {
+		return "sem motivo"
+	}
+	if s.Inline {
+		return ""
+	}
+	if s.Expires == "" {
+		return "sem data de expiração (expires)"
+	}
+	expires, err := time.Parse(suppressionDateLayout, s.Expires)
+	if err != nil {
+		return fmt.Sprintf("com expires inválido %q (use AAAA-MM-DD)", s.Expires)
+	}
+	if today.After(expires) {
+		return fmt.Sprintf("expirou em %s: %s", s.Expires, s.Reason)
+	}
+	return ""
+}
+
+// inScope indica se a supressão cita um arquivo examinado por este relatório
+func (r *Report) inScope(s *Suppression) bool {
+	if !s.Inline && s.File == "" {
+		return true
+	}
+	for _, input := range r.Inputs {
+		rel := r.relativePath(input)
+		if s.Inline {
+			source := r.relativePath(s.Source)
+			if source == rel || strings.HasPrefix(source, rel+"/") {
+				return true
+			}
+			continue
+		}
+		if globMatch(s.File, rel) || strings.HasPrefix(s.File, rel+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Suppress aplica as supressões inline das entradas do relatório e as do
+// baseline informado
+func (r *Report) Suppress(baselinePath string, today time.Time) error {
+	suppressions, err := InlineSuppressions(r.Inputs...)
+	if err != nil {
+		return err
+	}
+	baseline, err := LoadBaseline(baselinePath)
+	if err != nil {
+		return err
+	}
+	r.ApplySuppressions(append(suppressions, baseline...), today)
+	return nil
+}
@@ -3,7 +3,6 @@ package helpers
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -87,35 +86,6 @@ func CountOccurrences(filePath, searchString string) (int, error) {
 	return strings.Count(string(content), searchString), nil
 }
 
-// GetProjectRoot retorna o diretório raiz do projeto
-func GetProjectRoot() string {
-	// Encontra o diretório raiz procurando por go.mod ou .git
-	dir, _ := os.Getwd()
-	
-	// Se estamos em test/unit ou test/property, sobe dois níveis
-	if filepath.Base(dir) == "unit" || filepath.Base(dir) == "property" {
-		return filepath.Join(dir, "..", "..")
-	}
-	
-	// Se estamos em test/, sobe um nível
-	if filepath.Base(dir) == "test" {
-		return filepath.Join(dir, "..")
-	}
-	
-	// Caso contrário, assume que já estamos na raiz
-	return dir
-}
-
-// GetModulePath retorna o caminho para um módulo
-func GetModulePath(moduleName string) string {
-	return filepath.Join(GetProjectRoot(), "modules", moduleName)
-}
-
-// GetEnvironmentPath retorna o caminho para um ambiente
-func GetEnvironmentPath(env string) string {
-	return filepath.Join(GetProjectRoot(), "live", "aws", env)
-}
-
 // ExtractVariableValidation extrai validações de variáveis de um arquivo
 func ExtractVariableValidation(filePath, varName string) (bool, error) {
 	content, err := os.ReadFile(filePath)
@@ -0,0 +1,352 @@
+package helpers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Criterion é um critério de aceitação numerado de um requisito do spec
+type Criterion struct {
+	// ID no formato <requisito>.<critério> (ex: "8.6")
+	ID          string
+	Requirement int
+	// Title é o título do requisito (ex: "Cluster EKS Seguro")
+	Title string
+	Text  string
+	Line  int
+}
+
+// TestReference é um teste que declara os critérios que valida com "Valida: Requisitos"
+type TestReference struct {
+	// Package é o diretório do teste (ex: "unit", "property")
+	Package  string
+	Name     string
+	File     string
+	Line     int
+	Criteria []string
+}
+
+// Key identifica o teste no formato <pacote>.<nome>
+func (r *TestReference) Key() string {
+	return r.Package + "." + r.Name
+}
+
+// TestStatus é o resultado de um teste em uma execução de go test
+type TestStatus string
+
+const (
+	TestPassed  TestStatus = "pass"
+	TestFailed  TestStatus = "fail"
+	TestSkipped TestStatus = "skip"
+)
+
+var (
+	requirementHeading = regexp.MustCompile(`^### Requisito (\d+):\s*(.+)$`)
+	criterionLine      = regexp.MustCompile(`^(\d+)\.\s+(.+)$`)
+	validaComment      = regexp.MustCompile(`Valida:\s*Requisitos?\s+(.+)`)
+	criterionID        = regexp.MustCompile(`^\d+\.\d+$`)
+)
+
+// GetRequirementsPath retorna o caminho do documento de requisitos do spec
+func GetRequirementsPath() string {
+	return GetSpecPath("requirements.md")
+}
+
+// ParseRequirements extrai os critérios de aceitação numerados de cada requisito
+func ParseRequirements(path string) ([]*Criterion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var criteria []*Criterion
+	requirement, title := 0, ""
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if m := requirementHeading.FindStringSubmatch(text); m != nil {
+			requirement, _ = strconv.Atoi(m[1])
+			title = strings.TrimSpace(m[2])
+			continue
+		}
+		if strings.HasPrefix(text, "## ") {
+			requirement = 0
+			continue
+		}
+		if requirement == 0 {
+			continue
+		}
+		if m := criterionLine.FindStringSubmatch(text); m != nil {
+			criteria = append(criteria, &Criterion{
+				ID:          fmt.Sprintf("%d.%s", requirement, m[1]),
+				Requirement: requirement,
+				Title:       title,
+				Text:        m[2],
+				Line:        line,
+			})
+		}
+	}
+	return criteria, scanner.Err()
+}
+
+// ParseCriteriaList extrai os IDs de critérios de uma lista como "8.2, 8.3 e 9.1"
+func ParseCriteriaList(list string) []string {
+	var ids []string
+	for _, field := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == ';'
+	}) {
+		field = strings.Trim(field, "*.")
+		if criterionID.MatchString(field) {
+			ids = append(ids, field)
+		}
+	}
+	return ids
+}
+
+// TestReferences lê os arquivos _test.go dos diretórios e retorna os testes com
+// comentário "Valida: Requisitos", na ordem dos arquivos
+func TestReferences(dirs ...string) ([]*TestReference, error) {
+	var refs []*TestReference
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+
+		for _, path := range files {
+			file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test") || fn.Doc == nil {
+					continue
+				}
+				ref := &TestReference{
+					Package: filepath.Base(dir),
+					Name:    fn.Name.Name,
+					File:    path,
+					Line:    fset.Position(fn.Pos()).Line,
+				}
+				for _, comment := range fn.Doc.List {
+					if m := validaComment.FindStringSubmatch(comment.Text); m != nil {
+						ref.Criteria = append(ref.Criteria, ParseCriteriaList(m[1])...)
+					}
+				}
+				if len(ref.Criteria) > 0 {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// ParseGoTestJSON lê a saída de "go test -json" e retorna o resultado de cada teste
+// de nível superior, indexado por <pacote>.<nome>
+func ParseGoTestJSON(r io.Reader) (map[string]TestStatus, error) {
+	results := make(map[string]TestStatus)
+	dec := json.NewDecoder(r)
+	for {
+		var event struct {
+			Action  string
+			Package string
+			Test    string
+		}
+		if err := dec.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("saída de go test -json inválida: %w", err)
+		}
+		// Subtestes (Test/sub) são agregados pelo resultado do teste pai
+		if event.Test == "" || strings.Contains(event.Test, "/") {
+			continue
+		}
+		switch TestStatus(event.Action) {
+		case TestPassed, TestFailed, TestSkipped:
+			results[filepath.Base(event.Package)+"."+event.Test] = TestStatus(event.Action)
+		}
+	}
+	return results, nil
+}
+
+// CriterionCoverage associa um critério aos testes que o citam
+type CriterionCoverage struct {
+	Criterion *Criterion
+	Tests     []*TestReference
+	// Passing são os testes aprovados na execução informada
+	Passing []*TestReference
+}
+
+// Covered indica se o critério tem testes; com resultados de execução, exige teste aprovado
+func (c *CriterionCoverage) Covered(withResults bool) bool {
+	if withResults {
+		return len(c.Passing) > 0
+	}
+	return len(c.Tests) > 0
+}
+
+// UnknownReference é uma citação a um critério que não existe no spec
+type UnknownReference struct {
+	Test      *TestReference
+	Criterion string
+}
+
+// TraceabilityMatrix relaciona os critérios de aceitação do spec aos testes
+type TraceabilityMatrix struct {
+	Criteria []*CriterionCoverage
+	Unknown  []*UnknownReference
+	// HasResults indica que a matriz considera o resultado de uma execução de go test
+	HasResults bool
+	// Results guarda o resultado de cada teste quando HasResults é verdadeiro
+	Results map[string]TestStatus
+}
+
+// BuildTraceabilityMatrix monta a matriz de rastreabilidade; results pode ser nil
+// quando não há execução de go test disponível
+func BuildTraceabilityMatrix(criteria []*Criterion, refs []*TestReference, results map[string]TestStatus) *TraceabilityMatrix {
+	m := &TraceabilityMatrix{HasResults: results != nil, Results: results}
+
+	index := make(map[string]*CriterionCoverage, len(criteria))
+	for _, criterion := range criteria {
+		coverage := &CriterionCoverage{Criterion: criterion}
+		index[criterion.ID] = coverage
+		m.Criteria = append(m.Criteria, coverage)
+	}
+
+	for _, ref := range refs {
+		for _, id := range ref.Criteria {
+			coverage, ok := index[id]
+			if !ok {
+				m.Unknown = append(m.Unknown, &UnknownReference{Test: ref, Criterion: id})
+				continue
+			}
+			coverage.Tests = append(coverage.Tests, ref)
+			if results[ref.Key()] == TestPassed {
+				coverage.Passing = append(coverage.Passing, ref)
+			}
+		}
+	}
+	return m
+}
+
+// Uncovered retorna os critérios sem testes (ou sem testes aprovados, com resultados)
+func (m *TraceabilityMatrix) Uncovered() []*Criterion {
+	var uncovered []*Criterion
+	for _, coverage := range m.Criteria {
+		if !coverage.Covered(m.HasResults) {
+			uncovered = append(uncovered, coverage.Criterion)
+		}
+	}
+	return uncovered
+}
+
+// MissingRequired retorna os critérios obrigatórios sem cobertura. Cada item de
+// required é um requisito inteiro ("12") ou um critério ("8.6").
+func (m *TraceabilityMatrix) MissingRequired(required []string) []*Criterion {
+	var missing []*Criterion
+	for _, coverage := range m.Criteria {
+		if !matchesRequired(coverage.Criterion, required) || coverage.Covered(m.HasResults) {
+			continue
+		}
+		missing = append(missing, coverage.Criterion)
+	}
+	return missing
+}
+
+// matchesRequired indica se o critério pertence ao conjunto obrigatório
+func matchesRequired(criterion *Criterion, required []string) bool {
+	for _, item := range required {
+		if item == criterion.ID || item == strconv.Itoa(criterion.Requirement) {
+			return true
+		}
+	}
+	return false
+}
+
+// Markdown formata a matriz como tabela Markdown, seguida das citações inválidas
+func (m *TraceabilityMatrix) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Critério | Requisito | Testes | Status |\n")
+	b.WriteString("|----------|-----------|--------|--------|\n")
+	for _, coverage := range m.Criteria {
+		names := make([]string, 0, len(coverage.Tests))
+		for _, ref := range coverage.Tests {
+			name := ref.Key()
+			if m.HasResults {
+				status := m.Results[ref.Key()]
+				if status == "" {
+					status = "não executado"
+				}
+				name += " (" + string(status) + ")"
+			}
+			names = append(names, name)
+		}
+
+		status := "coberto"
+		switch {
+		case len(coverage.Tests) == 0:
+			status = "sem testes"
+		case m.HasResults && len(coverage.Passing) == 0:
+			status = "sem testes aprovados"
+		case m.HasResults:
+			status = "aprovado"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+			coverage.Criterion.ID, coverage.Criterion.Title, strings.Join(names, ", "), status)
+	}
+
+	if len(m.Unknown) > 0 {
+		b.WriteString("\nCitações de critérios inexistentes:\n\n")
+		for _, unknown := range m.Unknown {
+			fmt.Fprintf(&b, "- %s:%d %s cita %s\n",
+				unknown.Test.File, unknown.Test.Line, unknown.Test.Key(), unknown.Criterion)
+		}
+	}
+	return b.String()
+}
+
+// TraceabilityConfig define os critérios que devem manter cobertura de testes
+type TraceabilityConfig struct {
+	Required []string `yaml:"required"`
+}
+
+// GetTraceabilityConfigPath retorna o caminho da configuração de critérios obrigatórios
+func GetTraceabilityConfigPath() string {
+	return GetTestPath("testdata", "traceability.yaml")
+}
+
+// LoadTraceabilityConfig lê a configuração de critérios obrigatórios
+func LoadTraceabilityConfig(path string) (*TraceabilityConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &TraceabilityConfig{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// GetTestDirs retorna os diretórios de testes que citam requisitos
+func GetTestDirs() []string {
+	return []string{GetTestPath("unit"), GetTestPath("property")}
+}
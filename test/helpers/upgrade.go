@@ -0,0 +1,367 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AddonCompatibility indica as versões Kubernetes suportadas por uma linha de versões de um chart
+type AddonCompatibility struct {
+	Chart string
+	// ChartVersion é o prefixo major.minor das versões do chart (ex: "1.6" cobre 1.6.x)
+	ChartVersion  string
+	AppVersion    string
+	MinKubernetes string
+	MaxKubernetes string
+}
+
+// addonCompatibility é a matriz de compatibilidade dos add-ons com o Kubernetes,
+// conforme as tabelas de suporte publicadas por cada projeto
+var addonCompatibility = []AddonCompatibility{
+	{"aws-load-balancer-controller", "1.6", "v2.6", "1.22", "1.28"},
+	{"aws-load-balancer-controller", "1.7", "v2.7", "1.22", "1.29"},
+	{"aws-load-balancer-controller", "1.8", "v2.8", "1.22", "1.30"},
+	{"kyverno", "3.1", "v1.11", "1.25", "1.28"},
+	{"kyverno", "3.2", "v1.12", "1.26", "1.29"},
+	{"kyverno", "3.3", "v1.13", "1.28", "1.31"},
+	{"gatekeeper", "3.14", "v3.14", "1.26", "1.28"},
+	{"gatekeeper", "3.15", "v3.15", "1.27", "1.29"},
+	{"gatekeeper", "3.16", "v3.16", "1.28", "1.30"},
+	{"velero", "5.2", "v1.12", "1.18", "1.28"},
+	{"velero", "6.0", "v1.13", "1.18", "1.29"},
+	{"velero", "7.0", "v1.14", "1.18", "1.30"},
+	{"cert-manager", "1.6", "v1.6", "1.17", "1.22"},
+	{"cert-manager", "1.13", "v1.13", "1.23", "1.28"},
+	{"cert-manager", "1.14", "v1.14", "1.24", "1.29"},
+	{"cert-manager", "1.15", "v1.15", "1.25", "1.30"},
+}
+
+// AddonCompatibilityMatrix retorna a matriz de compatibilidade dos add-ons
+func AddonCompatibilityMatrix() []AddonCompatibility {
+	return append([]AddonCompatibility(nil), addonCompatibility...)
+}
+
+// Supports indica se a linha de versões do chart suporta a versão Kubernetes informada
+func (c AddonCompatibility) Supports(version string) bool {
+	minor, err := ParseMinorVersion(version)
+	if err != nil {
+		return false
+	}
+	minMinor, _ := ParseMinorVersion(c.MinKubernetes)
+	maxMinor, _ := ParseMinorVersion(c.MaxKubernetes)
+	return minor >= minMinor && minor <= maxMinor
+}
+
+// lookupAddonCompatibility retorna a entrada da matriz para uma versão de chart
+func lookupAddonCompatibility(chart, version string) (AddonCompatibility, bool) {
+	for _, entry := range addonCompatibility {
+		if entry.Chart == chart && (version == entry.ChartVersion || strings.HasPrefix(version, entry.ChartVersion+".")) {
+			return entry, true
+		}
+	}
+	return AddonCompatibility{}, false
+}
+
+// suggestAddonVersion retorna a menor linha de versões do chart acima da instalada
+// que suporta a versão Kubernetes
+func suggestAddonVersion(chart, installed, version string) (AddonCompatibility, bool) {
+	for _, entry := range addonCompatibility {
+		if entry.Chart == chart && compareChartVersions(entry.ChartVersion, installed) > 0 && entry.Supports(version) {
+			return entry, true
+		}
+	}
+	return AddonCompatibility{}, false
+}
+
+// trackedAddon indica se o chart faz parte da matriz de compatibilidade
+func trackedAddon(chart string) bool {
+	for _, entry := range addonCompatibility {
+		if entry.Chart == chart {
+			return true
+		}
+	}
+	return false
+}
+
+// UpgradeSteps retorna as versões intermediárias de um upgrade, um minor por vez
+// (o EKS não permite pular versões do control plane)
+func UpgradeSteps(current, target string) ([]string, error) {
+	currentMinor, err := ParseMinorVersion(current)
+	if err != nil {
+		return nil, err
+	}
+	targetMinor, err := ParseMinorVersion(target)
+	if err != nil {
+		return nil, err
+	}
+	if targetMinor < currentMinor {
+		return nil, fmt.Errorf("downgrade de %s para %s não é suportado pelo EKS", current, target)
+	}
+
+	steps := make([]string, 0, targetMinor-currentMinor)
+	for minor := currentMinor + 1; minor <= targetMinor; minor++ {
+		steps = append(steps, fmt.Sprintf("1.%d", minor))
+	}
+	return steps, nil
+}
+
+// maxKubeletSkew retorna quantos minors o kubelet pode estar atrás do API server
+func maxKubeletSkew(version string) int {
+	minor, _ := ParseMinorVersion(version)
+	if minor >= 28 {
+		return 3
+	}
+	return 2
+}
+
+// UpgradeItem é um passo do checklist de upgrade
+type UpgradeItem struct {
+	// Version é a versão do passo de upgrade a que o item pertence
+	Version string
+	Target  string
+	Action  string
+	// Blocking indica que o item precisa ser resolvido antes do upgrade do control plane
+	Blocking bool
+	// Resolvable indica que existe uma ação conhecida para o item
+	Resolvable bool
+	// File e Line localizam o recurso ou manifesto do item
+	File string
+	Line int
+}
+
+// UpgradePlan é o checklist ordenado de upgrade de um ambiente
+type UpgradePlan struct {
+	Current string
+	Target  string
+	Steps   []string
+	Items   []*UpgradeItem
+}
+
+// Checklist retorna os itens do plano formatados, na ordem de execução
+func (p *UpgradePlan) Checklist() []string {
+	lines := make([]string, 0, len(p.Items))
+	for i, item := range p.Items {
+		marker := ""
+		if item.Blocking {
+			marker = " [bloqueante]"
+		}
+		lines = append(lines, fmt.Sprintf("%d. [%s]%s %s: %s", i+1, item.Version, marker, item.Target, item.Action))
+	}
+	return lines
+}
+
+// Blocking retorna os itens que impedem o upgrade do control plane
+func (p *UpgradePlan) Blocking() []*UpgradeItem {
+	var blocking []*UpgradeItem
+	for _, item := range p.Items {
+		if item.Blocking {
+			blocking = append(blocking, item)
+		}
+	}
+	return blocking
+}
+
+// Feasible indica se todos os itens bloqueantes possuem ação conhecida
+func (p *UpgradePlan) Feasible() bool {
+	for _, item := range p.Blocking() {
+		if !item.Resolvable {
+			return false
+		}
+	}
+	return true
+}
+
+// PlanUpgrade monta o checklist de upgrade de um ambiente até a versão alvo.
+// Para cada minor: add-ons incompatíveis, manifestos com APIs removidas e node
+// groups fora do skew do kubelet são tratados antes do control plane; node groups
+// e launch templates são atualizados depois. O kubelet de cada node group
+// acompanha as atualizações dos passos anteriores.
+func PlanUpgrade(ev *Evaluator, target string, manifests []*KubernetesManifest) (*UpgradePlan, error) {
+	versionVal := ev.Var("cluster_version")
+	if versionVal.IsNull() || !versionVal.IsKnown() {
+		return nil, fmt.Errorf("%s: cluster_version não definido", ev.describe())
+	}
+	current := versionVal.AsString()
+
+	steps, err := UpgradeSteps(current, target)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := ev.Expand()
+	if err != nil {
+		return nil, err
+	}
+	releases, err := HelmReleases(instances)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &UpgradePlan{Current: current, Target: target, Steps: steps}
+	currentMinor, _ := ParseMinorVersion(current)
+
+	// Versão instalada de cada add-on, atualizada conforme o plano sugere upgrades
+	installed := make(map[string]string, len(releases))
+	for _, release := range releases {
+		installed[release.Address] = release.Version
+	}
+
+	// Minor do kubelet de cada node group, atualizado conforme o plano atualiza o
+	// node group; sem version, o EKS mantém a versão do cluster na criação
+	kubelets := make(map[string]int)
+	var clusterFile string
+	var clusterLine int
+	for _, inst := range instances {
+		if inst.Resource.Type == "aws_eks_cluster" {
+			clusterFile, clusterLine = inst.Resource.Range.Filename, inst.Resource.Range.Start.Line
+		}
+		if inst.Resource.Type != "aws_eks_node_group" {
+			continue
+		}
+		kubelets[inst.Address()] = currentMinor
+		if version := inst.Attr("version"); isLiteralString(version) {
+			if minor, err := ParseMinorVersion(version.AsString()); err == nil {
+				kubelets[inst.Address()] = minor
+			}
+		}
+	}
+
+	for _, step := range steps {
+		stepMinor, _ := ParseMinorVersion(step)
+
+		for _, release := range releases {
+			if !trackedAddon(release.Chart) {
+				continue
+			}
+			version := installed[release.Address]
+			entry, ok := lookupAddonCompatibility(release.Chart, version)
+			if ok && entry.Supports(step) {
+				continue
+			}
+
+			item := &UpgradeItem{Version: step, Target: release.Address, Blocking: true, File: release.File, Line: release.Line}
+			suggestion, found := suggestAddonVersion(release.Chart, version, step)
+			switch {
+			case !ok:
+				item.Action = fmt.Sprintf("compatibilidade do chart %s %s com Kubernetes %s desconhecida", release.Chart, version, step)
+			case found:
+				// Manifestos com APIs de CRDs removidas pela nova versão migram antes do chart
+				for _, finding := range FindCRDRemovals(manifests, release.Chart, version, suggestion.ChartVersion) {
+					if finding.AlreadyRemoved {
+						continue
+					}
+					plan.Items = append(plan.Items, &UpgradeItem{
+						Version:    step,
+						Target:     fmt.Sprintf("%s:%d", finding.Manifest.File, finding.Manifest.Line),
+						Action:     fmt.Sprintf("migrar %s %s para %s antes de atualizar o chart %s", finding.Manifest.Kind, finding.Manifest.APIVersion, finding.Removal.Replacement, release.Chart),
+						Blocking:   true,
+						Resolvable: true,
+						File:       finding.Manifest.File,
+						Line:       finding.Manifest.Line,
+					})
+				}
+				item.Resolvable = true
+				item.Action = fmt.Sprintf("atualizar chart %s de %s para %s.x (%s, suporta %s-%s)",
+					release.Chart, version, suggestion.ChartVersion, suggestion.AppVersion,
+					suggestion.MinKubernetes, suggestion.MaxKubernetes)
+				installed[release.Address] = suggestion.ChartVersion
+			default:
+				item.Action = fmt.Sprintf("nenhuma versão conhecida do chart %s suporta Kubernetes %s", release.Chart, step)
+			}
+			plan.Items = append(plan.Items, item)
+		}
+
+		findings, err := FindAPIRemovals(manifests, fmt.Sprintf("1.%d", stepMinor-1), step)
+		if err != nil {
+			return nil, err
+		}
+		for _, finding := range findings {
+			if finding.AlreadyRemoved {
+				continue
+			}
+			plan.Items = append(plan.Items, &UpgradeItem{
+				Version:    step,
+				Target:     fmt.Sprintf("%s:%d", finding.Manifest.File, finding.Manifest.Line),
+				Action:     fmt.Sprintf("migrar %s %s para %s", finding.Manifest.Kind, finding.Manifest.APIVersion, finding.Removal.Replacement),
+				Blocking:   true,
+				Resolvable: true,
+				File:       finding.Manifest.File,
+				Line:       finding.Manifest.Line,
+			})
+		}
+
+		// Node groups fora do skew com o novo control plane são atualizados antes dele
+		for _, inst := range instances {
+			if inst.Resource.Type != "aws_eks_node_group" {
+				continue
+			}
+			kubeletMinor := kubelets[inst.Address()]
+			if skew := stepMinor - kubeletMinor; skew > maxKubeletSkew(step) {
+				plan.Items = append(plan.Items, &UpgradeItem{
+					Version: step, Target: inst.Address(), Blocking: true, Resolvable: true,
+					File: inst.Resource.Range.Filename, Line: inst.Resource.Range.Start.Line,
+					Action: fmt.Sprintf("kubelet 1.%d excede o skew permitido com o control plane %s (%d minors); atualizar o node group para 1.%d antes",
+						kubeletMinor, step, skew, stepMinor-1),
+				})
+				kubelets[inst.Address()] = stepMinor - 1
+			}
+		}
+
+		plan.Items = append(plan.Items, &UpgradeItem{
+			Version:    step,
+			Target:     "aws_eks_cluster",
+			Action:     fmt.Sprintf("atualizar cluster_version para %s e aplicar o control plane", step),
+			Resolvable: true,
+			File:       clusterFile,
+			Line:       clusterLine,
+		})
+
+		for _, inst := range instances {
+			switch inst.Resource.Type {
+			case "aws_eks_node_group":
+				plan.Items = append(plan.Items, nodeGroupUpgradeItem(inst, step, kubelets[inst.Address()]))
+				kubelets[inst.Address()] = stepMinor
+			case "aws_launch_template":
+				imageID := inst.Attr("image_id")
+				if !isLiteralString(imageID) {
+					continue
+				}
+				plan.Items = append(plan.Items, &UpgradeItem{
+					Version:    step,
+					Target:     inst.Address(),
+					Action:     fmt.Sprintf("atualizar image_id para a AMI EKS otimizada %s (AMI fixa não acompanha o cluster)", step),
+					Resolvable: true,
+					File:       inst.Resource.Range.Filename,
+					Line:       inst.Resource.Range.Start.Line,
+				})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// nodeGroupUpgradeItem atualiza o kubelet de um node group após o upgrade do control plane
+func nodeGroupUpgradeItem(inst *ResourceInstance, step string, kubeletMinor int) *UpgradeItem {
+	item := &UpgradeItem{
+		Version: step, Target: inst.Address(), Resolvable: true,
+		File: inst.Resource.Range.Filename, Line: inst.Resource.Range.Start.Line,
+	}
+	if version := inst.Attr("version"); isLiteralString(version) {
+		item.Action = fmt.Sprintf("atualizar version do node group de 1.%d para %s após o control plane", kubeletMinor, step)
+	} else {
+		// Sem version, o EKS mantém a versão do cluster no momento da criação
+		item.Action = fmt.Sprintf("node group não define version e não acompanha cluster_version; atualizar de 1.%d para %s após o control plane", kubeletMinor, step)
+	}
+	return item
+}
+
+// isLiteralString indica se o valor é uma string conhecida e não é um placeholder de atributo calculado
+func isLiteralString(val cty.Value) bool {
+	if val == cty.NilVal || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return false
+	}
+	return !IsComputedPlaceholder(val.AsString())
+}
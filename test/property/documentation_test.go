@@ -6,6 +6,7 @@ import (
 	"github.com/example/terraform-eks-aws-template/test/helpers"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/prop"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // TestPropertyVariablesDocumentation valida Propriedade 15: Documentação de Variáveis e Outputs
@@ -102,39 +103,57 @@ func TestPropertyMandatoryTags(t *testing.T) {
 
 // TestPropertyBucketPoliciesProtection valida Propriedade 17: Bucket Policies de Proteção
 // Feature: terraform-eks-aws-template, Property 17: Bucket Policies de Proteção
-// Para qualquer bucket S3 usado para logs ou backups, deve existir bucket policy com statement
-// que nega ações s3:DeleteBucket e s3:DeleteObject.
+// Para qualquer ambiente e qualquer nome do bucket de backups, os buckets de logs e
+// de backups renderizados devem ter bucket policy que exige TLS no bucket e em
+// <arn>/* e nega s3:DeleteBucket (e a deleção de objetos no bucket de auditoria).
 // Valida: Requisitos 18.5
 func TestPropertyBucketPoliciesProtection(t *testing.T) {
 	t.Parallel()
 
+	config, err := helpers.LoadBucketPostureConfig(helpers.GetBucketPostureConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("S3 buckets have protection policies", prop.ForAll(
-		func() bool {
-			// Verifica compliance module (audit logs)
-			complianceMain := helpers.GetModulePath("compliance") + "/main.tf"
-			if helpers.FileExists(complianceMain) {
-				hasBucketPolicy, _ := helpers.ContainsString(complianceMain, "aws_s3_bucket_policy")
-				hasDenyDelete, _ := helpers.ContainsString(complianceMain, "DeleteBucket")
-
-				if !hasBucketPolicy || !hasDenyDelete {
-					return false
-				}
+		func(env, name string) bool {
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			if err == nil {
+				err = ev.OverrideModuleArgument("velero", "backup_bucket_name", cty.StringVal(name))
+			}
+			var instances []*helpers.ResourceInstance
+			if err == nil {
+				instances, err = ev.Expand()
+			}
+			var buckets []*helpers.BucketPosture
+			if err == nil {
+				buckets, err = helpers.BucketPostures(env, instances, config)
+			}
+			if err != nil {
+				t.Logf("%s: %v", env, err)
+				return false
 			}
 
-			// Verifica velero module (backups)
-			veleroMain := helpers.GetModulePath("platform/velero") + "/main.tf"
-			if helpers.FileExists(veleroMain) {
-				// Velero pode ter bucket policy ou lifecycle rules
-				hasBucketPolicy, _ := helpers.ContainsString(veleroMain, "aws_s3_bucket")
-				if !hasBucketPolicy {
+			byPurpose := make(map[string]*helpers.BucketPosture)
+			for _, bucket := range buckets {
+				byPurpose[bucket.Purpose] = bucket
+			}
+			audit, velero := byPurpose["audit-logs"], byPurpose["velero-backups"]
+			if audit == nil || velero == nil || velero.Name != name {
+				t.Logf("%s: buckets de auditoria e de backups %q não encontrados", env, name)
+				return false
+			}
+			for _, bucket := range []*helpers.BucketPosture{audit, velero} {
+				if !bucket.DenyInsecureTransport || len(bucket.Problems()) > 0 {
+					t.Logf("%s: %s: %v", env, bucket.Address, bucket.Problems())
 					return false
 				}
 			}
-
-			return true
+			return len(audit.DeniedDeletes) == 3 && len(velero.DeniedDeletes) == 1 && velero.DeniedDeletes[0] == "s3:DeleteBucket"
 		},
+		helpers.GenEnvironment(),
+		helpers.GenBucketName(),
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -0,0 +1,168 @@
+package property
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// environmentTests é o número de configurações avaliadas por cada execução de
+// TestPropertyGeneratedEnvironments. O CI (make ci e make test-property) roda o
+// pacote com -count 100, ou seja 2000 configurações com sementes diferentes; uma
+// rodada local longa usa a flag (ex: go test ./property -run GeneratedEnvironments -environments 2000)
+var environmentTests = flag.Int("environments", 20, "configurações de ambiente geradas por TestPropertyGeneratedEnvironments")
+
+// TestPropertyGeneratedEnvironments complementa a Propriedade 2: Subnets Multi-AZ,
+// verificada por TestPropertySubnetsMultiAZ
+// Para qualquer configuração completa de ambiente gerada, a árvore deve ser avaliada
+// sem erros de validação, agendamento ou políticas; subnets e NAT Gateways devem
+// seguir as AZs e o modo de enforcement diferente do esperado no ambiente e o
+// schedule de backup com RPO acima do esperado devem ser os únicos erros reportados.
+// Valida: Requisitos 4.1, 4.2, 4.3, 8.1, 8.6, 8.7, 12.3
+func TestPropertyGeneratedEnvironments(t *testing.T) {
+	t.Parallel()
+
+	expectedMode := map[string]string{"staging": "audit", "prod": "enforce"}
+	expectedRPO := map[string]time.Duration{"staging": 24 * time.Hour, "prod": 6 * time.Hour}
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = *environmentTests
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("generated environments pass evaluation and checks", prop.ForAll(
+		func(env string, config *helpers.EnvironmentConfig) bool {
+			report := helpers.NewReport(helpers.GetProjectRoot())
+			instances, err := helpers.RunEnvironmentConfigChecks(report, env, config)
+			if err == nil {
+				err = report.Suppress(helpers.GetBaselinePath(), time.Now())
+			}
+			if err != nil {
+				t.Logf("%s: %v", env, err)
+				return false
+			}
+
+			modeErrors, rpoErrors := 0, 0
+			for _, finding := range report.Findings {
+				if finding.Severity != helpers.SeverityError || finding.Suppression != nil {
+					continue
+				}
+				switch finding.CheckID {
+				case helpers.CheckPolicyEnforcementMode:
+					modeErrors++
+				case helpers.CheckBackupSchedule:
+					rpoErrors++
+				default:
+					t.Logf("%s", finding)
+					return false
+				}
+			}
+			if (modeErrors > 0) != (config.EnforcementMode != expectedMode[env]) {
+				t.Logf("%s: modo %s com %d erros de enforcement", env, config.EnforcementMode, modeErrors)
+				return false
+			}
+			cron, err := helpers.ParseCron(config.BackupSchedule)
+			if err != nil {
+				t.Logf("%s: %v", env, err)
+				return false
+			}
+			if (rpoErrors > 0) != (cron.RPO > expectedRPO[env]) {
+				t.Logf("%s: schedule %q com RPO %s e %d erros de RPO", env, config.BackupSchedule, cron.RPO, rpoErrors)
+				return false
+			}
+
+			counts := make(map[string]int)
+			for _, inst := range instances {
+				if inst.Diagnostics.HasErrors() {
+					t.Logf("%s: %s", inst.Address(), inst.Diagnostics.Error())
+					return false
+				}
+				counts[inst.Resource.Address()]++
+			}
+			azs, nats := len(config.AvailabilityZones), len(config.AvailabilityZones)
+			if config.SingleNATGateway {
+				nats = 1
+			}
+			return counts["aws_subnet.private"] == azs &&
+				counts["aws_subnet.public"] == azs &&
+				counts["aws_nat_gateway.main"] == nats &&
+				counts["aws_eks_node_group.main"] == len(config.NodeGroups)
+		},
+		helpers.GenEnvironment(),
+		helpers.GenEnvironmentConfig(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// TestPropertyEnvironmentConfigShrinks verifica o shrinker de
+// helpers.GenEnvironmentConfig usado por TestPropertyGeneratedEnvironments; testa a
+// infraestrutura de testes e não uma propriedade do design.
+// Para qualquer configuração gerada, os candidatos do shrinker devem manter as
+// restrições do gerador (≥ 2 AZs, node group system com max_size - min_size ≤ 3 e
+// node group apps sem taints),
+// e uma propriedade falsa deve ser reduzida ao contraexemplo mínimo.
+// Valida: Requisitos 16.5
+func TestPropertyEnvironmentConfigShrinks(t *testing.T) {
+	t.Parallel()
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("shrink candidates respect generator constraints", prop.ForAll(
+		func(config *helpers.EnvironmentConfig) bool {
+			shrink := helpers.ShrinkEnvironmentConfig(config)
+			for value, ok := shrink(); ok; value, ok = shrink() {
+				candidate := value.(*helpers.EnvironmentConfig)
+				system, apps := candidate.NodeGroups["system"], candidate.NodeGroups["apps"]
+				if len(candidate.AvailabilityZones) < 2 || system == nil || apps == nil || len(apps.Taints) > 0 ||
+					system.MaxSize-system.MinSize > 3 || system.Labels["role"] != "system" || len(system.Taints) != 1 {
+					t.Logf("candidato inválido: %s", candidate)
+					return false
+				}
+				for name, group := range candidate.NodeGroups {
+					if group.MinSize > group.DesiredSize || group.DesiredSize > group.MaxSize ||
+						len(group.InstanceTypes) == 0 || group.Labels["role"] != name {
+						t.Logf("candidato inválido: %s", candidate)
+						return false
+					}
+				}
+			}
+			return true
+		},
+		helpers.GenEnvironmentConfig(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// Uma propriedade falsa para ingress nginx deve reduzir todo o resto ao mínimo
+	result := prop.ForAll(
+		func(config *helpers.EnvironmentConfig) bool {
+			return config.IngressType != "nginx"
+		},
+		helpers.GenEnvironmentConfig(),
+	).Check(gopter.DefaultTestParameters())
+	require.Equal(t, gopter.TestFailed, result.Status, "Propriedade deve ser falsificada")
+	require.Len(t, result.Args, 1)
+
+	minimal := result.Args[0].Arg.(*helpers.EnvironmentConfig)
+	assert.Equal(t, "nginx", minimal.IngressType)
+	assert.Equal(t, []string{"us-east-1a", "us-east-1b"}, minimal.AvailabilityZones)
+	assert.Equal(t, "10.0.0.0/16", minimal.VPCCIDR)
+	assert.Equal(t, "kyverno", minimal.PolicyEngine)
+	assert.Equal(t, "0 2 * * *", minimal.BackupSchedule)
+	require.Len(t, minimal.NodeGroups, 2, "Só os node groups system e apps devem restar: %s", minimal)
+	for _, name := range []string{"system", "apps"} {
+		group := minimal.NodeGroups[name]
+		require.NotNil(t, group, "%s: node group obrigatório", name)
+		assert.Len(t, group.InstanceTypes, 1, "%s", name)
+		assert.Equal(t, 1, group.MaxSize, "%s", name)
+		assert.Equal(t, 20, group.DiskSize, "%s", name)
+		assert.Equal(t, map[string]string{"role": name}, group.Labels, "%s", name)
+	}
+	assert.Empty(t, minimal.NodeGroups["apps"].Taints)
+	assert.Contains(t, result.Args[0].ArgFormatted, "ingress nginx", "Contraexemplo deve ser legível")
+}
@@ -22,8 +22,8 @@ func TestPropertyEnvironmentIsolation(t *testing.T) {
 				return true // Skip mesmo ambiente
 			}
 
-			backend1 := filepath.Join(helpers.GetProjectRoot(), "live", "aws", env1, "backend.tf")
-			backend2 := filepath.Join(helpers.GetProjectRoot(), "live", "aws", env2, "backend.tf")
+			backend1 := filepath.Join(helpers.GetEnvironmentPath(env1), "backend.tf")
+			backend2 := filepath.Join(helpers.GetEnvironmentPath(env2), "backend.tf")
 
 			content1, err1 := helpers.ReadFileContent(backend1)
 			content2, err2 := helpers.ReadFileContent(backend2)
@@ -46,8 +46,8 @@ func TestPropertyEnvironmentIsolation(t *testing.T) {
 				return true
 			}
 
-			example1 := filepath.Join(helpers.GetProjectRoot(), "live", "aws", env1, "terraform.tfvars.example")
-			example2 := filepath.Join(helpers.GetProjectRoot(), "live", "aws", env2, "terraform.tfvars.example")
+			example1 := filepath.Join(helpers.GetEnvironmentPath(env1), "terraform.tfvars.example")
+			example2 := filepath.Join(helpers.GetEnvironmentPath(env2), "terraform.tfvars.example")
 
 			content1, err1 := helpers.ReadFileContent(example1)
 			content2, err2 := helpers.ReadFileContent(example2)
@@ -1,6 +1,7 @@
 package property
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/example/terraform-eks-aws-template/test/helpers"
@@ -75,7 +76,7 @@ func TestPropertyAutoscalingConservative(t *testing.T) {
 					continue
 				}
 
-				// Para staging: min=2, max=3 (diferença=1)
+				// Para staging: min=3, max=4 (diferença=1)
 				// Para prod: min=3, max=5 (diferença=2)
 				// Ambos ≤ 3, então é conservador
 			}
@@ -87,8 +88,8 @@ func TestPropertyAutoscalingConservative(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
-// TestPropertyNodeGroupsEnvironmentIsolation valida Propriedade 1: Isolamento de Ambientes
-// Feature: terraform-eks-aws-template, Property 1: Isolamento de Ambientes
+// TestPropertyNodeGroupsEnvironmentIsolation complementa a Propriedade 1: Isolamento de Ambientes,
+// verificada por TestPropertyEnvironmentIsolation
 // Para qualquer par de ambientes distintos (staging, prod), cada ambiente deve ter
 // state file S3 em path único, VPC com CIDR único e cluster EKS com nome único.
 // Valida: Requisitos 1.1, 1.2, 1.3, 1.4
@@ -123,3 +124,76 @@ func TestPropertyNodeGroupsEnvironmentIsolation(t *testing.T) {
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
+
+// TestPropertyGeneratedNodeGroupsThroughModule complementa a Propriedade 9: Node Groups Completos,
+// verificada por TestPropertyNodeGroupsComplete
+// Para quaisquer node groups gerados, o módulo clusters/eks deve aceitar a configuração
+// e propagar taints, labels, tamanhos e disco para aws_eks_node_group e
+// aws_launch_template; desired_size fora de [min_size, max_size] deve ser rejeitado.
+// Valida: Requisitos 6.3, 6.4, 6.5, 6.6
+func TestPropertyGeneratedNodeGroupsThroughModule(t *testing.T) {
+	t.Parallel()
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("generated node groups expand through the eks module", prop.ForAll(
+		func(system, apps *helpers.NodeGroupConfig) bool {
+			input := map[string]*helpers.NodeGroupConfig{"system": system, "apps": apps}
+			expanded, validation, err := helpers.ExpandEKSNodeGroups(input)
+			if err != nil || len(validation) > 0 || len(expanded) != len(input) {
+				t.Logf("expansão falhou: %v %v", err, validation)
+				return false
+			}
+
+			for _, group := range expanded {
+				if group.NodeGroup == nil || group.LaunchTemplate == nil {
+					t.Logf("%s: node group ou launch template ausente", group.Name)
+					return false
+				}
+				want, got := input[group.Name], helpers.NodeGroupConfigFromInstances(group)
+				if !reflect.DeepEqual(want.InstanceTypes, got.InstanceTypes) ||
+					!equalLabels(want.Labels, got.Labels) ||
+					!equalTaints(want.Taints, got.Taints) ||
+					want.DiskSize != got.DiskSize ||
+					want.MinSize != got.MinSize || want.DesiredSize != got.DesiredSize || want.MaxSize != got.MaxSize {
+					t.Logf("%s: entrada %+v, expandido %+v", group.Name, want, got)
+					return false
+				}
+				if got.MinSize > got.DesiredSize || got.DesiredSize > got.MaxSize {
+					return false
+				}
+			}
+			return true
+		},
+		helpers.GenNodeGroup(),
+		helpers.GenNodeGroup(),
+	))
+
+	properties.Property("desired size outside the scaling range is rejected", prop.ForAll(
+		func(group *helpers.NodeGroupConfig) bool {
+			invalid := *group
+			invalid.DesiredSize = group.MaxSize + 1
+			_, validation, err := helpers.ExpandEKSNodeGroups(map[string]*helpers.NodeGroupConfig{"apps": &invalid})
+			return err == nil && len(validation) > 0
+		},
+		helpers.GenNodeGroup(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// equalLabels compara labels tratando mapa nulo como vazio
+func equalLabels(a, b map[string]string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// equalTaints compara taints em ordem, tratando lista nula como vazia
+func equalTaints(a, b []helpers.Taint) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -1,11 +1,14 @@
 package property
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/example/terraform-eks-aws-template/test/helpers"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/prop"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // TestPropertySecurityPoliciesEnabled valida Propriedade 11: Políticas de Segurança Habilitadas
@@ -100,32 +103,57 @@ func TestPropertyIRSACorrect(t *testing.T) {
 // Feature: terraform-eks-aws-template, Property 13: Retenção por Ambiente
 // Para qualquer configuração de observabilidade, se environment="staging" então
 // prometheus_retention_days=7 e loki_retention_days=3; se environment="prod" então
-// prometheus_retention_days=30 e loki_retention_days=15.
+// prometheus_retention_days=30 e loki_retention_days=15. Para qualquer retenção
+// gerada, os values renderizados de kube-prometheus-stack e loki devem repeti-la.
 // Valida: Requisitos 10.4, 10.5
 func TestPropertyRetentionByEnvironment(t *testing.T) {
 	t.Parallel()
 
+	config, err := helpers.LoadObservabilityConfig(helpers.GetObservabilityConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][2]int{"staging": {7, 3}, "prod": {30, 15}}
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("retention matches environment", prop.ForAll(
-		func() bool {
-			stagingMain := helpers.GetEnvironmentPath("staging") + "/main.tf"
-			prodMain := helpers.GetEnvironmentPath("prod") + "/main.tf"
-
-			if !helpers.FileExists(stagingMain) || !helpers.FileExists(prodMain) {
+		func(env string, prometheusDays, lokiDays int) bool {
+			result, err := helpers.EstimateEnvironmentObservability(env, config)
+			if err != nil {
+				t.Logf("%s: %v", env, err)
+				return false
+			}
+			if result.Prometheus.RetentionDays != expected[env][0] || result.Loki.RetentionDays != expected[env][1] {
 				return false
 			}
 
-			// Staging: 7 dias prometheus, 3 dias loki
-			hasStagingPrometheus, _ := helpers.ContainsRegex(stagingMain, `prometheus_retention_days\s*=\s*7`)
-			hasStagingLoki, _ := helpers.ContainsRegex(stagingMain, `loki_retention_days\s*=\s*3`)
-
-			// Prod: 30 dias prometheus, 15 dias loki
-			hasProdPrometheus, _ := helpers.ContainsRegex(prodMain, `prometheus_retention_days\s*=\s*30`)
-			hasProdLoki, _ := helpers.ContainsRegex(prodMain, `loki_retention_days\s*=\s*15`)
-
-			return hasStagingPrometheus && hasStagingLoki && hasProdPrometheus && hasProdLoki
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			if err == nil {
+				err = ev.OverrideModuleArgument("observability", "prometheus_retention_days", cty.NumberIntVal(int64(prometheusDays)))
+			}
+			if err == nil {
+				err = ev.OverrideModuleArgument("observability", "loki_retention_days", cty.NumberIntVal(int64(lokiDays)))
+			}
+			if err != nil {
+				t.Logf("%s: %v", env, err)
+				return false
+			}
+			generated, err := helpers.EstimateObservability(ev, env, config)
+			if err != nil {
+				t.Logf("%s: %v", env, err)
+				return false
+			}
+			for _, sizing := range []*helpers.StorageSizing{result.Prometheus, result.Loki, generated.Prometheus, generated.Loki} {
+				if len(sizing.RetentionProblems) > 0 {
+					t.Logf("%s: %s: %v", env, sizing.Component, sizing.RetentionProblems)
+					return false
+				}
+			}
+			return generated.Prometheus.RetentionDays == prometheusDays && generated.Loki.RetentionDays == lokiDays
 		},
+		helpers.GenEnvironment(),
+		helpers.GenRetentionDays(),
+		helpers.GenRetentionDays(),
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
@@ -133,34 +161,72 @@ func TestPropertyRetentionByEnvironment(t *testing.T) {
 
 // TestPropertyBackupScheduleByEnvironment valida Propriedade 14: Backup Schedule por Ambiente
 // Feature: terraform-eks-aws-template, Property 14: Backup Schedule por Ambiente
-// Para qualquer configuração de Velero, se environment="staging" então backup_schedule="0 2 * * *" (diário)
-// e backup_retention_days=7; se environment="prod" então backup_schedule="0 */6 * * *" (6h)
-// e backup_retention_days=30.
+// Para qualquer ambiente, o schedule do Velero renderizado deve ter RPO de 24h em
+// staging e 6h em prod, com TTL igual à expiração do bucket (7 e 30 dias); para
+// qualquer schedule e retenção gerados, o RPO deve ser o da expressão cron, o erro
+// de RPO deve aparecer apenas acima do esperado no ambiente e o TTL deve
+// acompanhar a expiração do bucket.
 // Valida: Requisitos 12.3, 12.4
 func TestPropertyBackupScheduleByEnvironment(t *testing.T) {
 	t.Parallel()
 
+	expectedRPO := map[string]time.Duration{"staging": 24 * time.Hour, "prod": 6 * time.Hour}
+	expectedDays := map[string]int{"staging": 7, "prod": 30}
+	for env := range expectedRPO {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schedules, err := helpers.BackupSchedules(env, ev)
+		if err != nil || len(schedules) != 1 {
+			t.Fatalf("%s: %d schedules: %v", env, len(schedules), err)
+		}
+		current := schedules[0]
+		if current.CronError != nil || current.Cron.RPO != expectedRPO[env] ||
+			current.ExpirationDays != expectedDays[env] || len(current.RetentionProblems()) > 0 {
+			t.Fatalf("%s: schedule %q com expiração de %d dias fora do esperado: %v %v",
+				env, current.Schedule, current.ExpirationDays, current.CronError, current.RetentionProblems())
+		}
+	}
 	properties := gopter.NewProperties(nil)
 
 	properties.Property("backup schedule matches environment", prop.ForAll(
-		func() bool {
-			stagingMain := helpers.GetEnvironmentPath("staging") + "/main.tf"
-			prodMain := helpers.GetEnvironmentPath("prod") + "/main.tf"
-
-			if !helpers.FileExists(stagingMain) || !helpers.FileExists(prodMain) {
+		func(env, expression string, days int) bool {
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			if err == nil {
+				err = ev.OverrideModuleArgument("velero", "backup_schedule", cty.StringVal(expression))
+			}
+			if err == nil {
+				err = ev.OverrideModuleArgument("velero", "backup_retention_days", cty.NumberIntVal(int64(days)))
+			}
+			if err != nil {
+				t.Logf("%s: %v", env, err)
 				return false
 			}
-
-			// Staging: diário (0 2 * * *), 7 dias retenção
-			hasStagingSchedule, _ := helpers.ContainsString(stagingMain, "0 2 * * *")
-			hasStagingRetention, _ := helpers.ContainsRegex(stagingMain, `backup_retention_days\s*=\s*7`)
-
-			// Prod: 6h (0 */6 * * *), 30 dias retenção
-			hasProdSchedule, _ := helpers.ContainsString(prodMain, "0 */6 * * *")
-			hasProdRetention, _ := helpers.ContainsRegex(prodMain, `backup_retention_days\s*=\s*30`)
-
-			return hasStagingSchedule && hasStagingRetention && hasProdSchedule && hasProdRetention
+			schedules, err := helpers.BackupSchedules(env, ev)
+			if err != nil || len(schedules) != 1 {
+				t.Logf("%s: %d schedules: %v", env, len(schedules), err)
+				return false
+			}
+			generated := schedules[0]
+			cron, err := helpers.ParseCron(expression)
+			if err != nil || generated.CronError != nil || generated.Cron.RPO != cron.RPO {
+				t.Logf("%s: schedule %q: %v %v", env, expression, err, generated.CronError)
+				return false
+			}
+			rpoError := false
+			for _, problem := range generated.RPOProblems() {
+				rpoError = rpoError || strings.Contains(problem, "esperado no máximo")
+			}
+			if rpoError != (cron.RPO > expectedRPO[env]) {
+				t.Logf("%s: schedule %q com RPO %s: %v", env, expression, cron.RPO, generated.RPOProblems())
+				return false
+			}
+			return generated.ExpirationDays == days && len(generated.RetentionProblems()) == 0
 		},
+		helpers.GenEnvironment(),
+		helpers.GenBackupSchedule(),
+		helpers.GenRetentionDays(),
 	))
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
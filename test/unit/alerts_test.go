@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Alert Rule Tests
+// ============================================================================
+
+// TestAlertCatalogCoversPlatform valida que o catálogo de PrometheusRules do
+// módulo observability cobre os componentes instalados em cada ambiente com
+// expressões PromQL válidas e métricas das versões fixadas dos charts
+// Valida: Requisitos 10.1
+func TestAlertCatalogCoversPlatform(t *testing.T) {
+	t.Parallel()
+
+	for _, env := range []string{"staging", "prod"} {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		require.NoError(t, err)
+		instances, err := ev.Expand()
+		require.NoError(t, err)
+		releases, err := helpers.HelmReleases(instances)
+		require.NoError(t, err)
+		rules, err := helpers.AlertRules(instances)
+		require.NoError(t, err)
+
+		alerts := make(map[string]*helpers.AlertRule)
+		for _, rule := range rules {
+			alerts[rule.Alert] = rule
+			metrics, err := helpers.ExprMetrics(rule.Expr)
+			require.NoError(t, err, "%s: %s", env, rule.Alert)
+			assert.NotEmpty(t, metrics, "%s: %s deve selecionar alguma métrica", env, rule.Alert)
+		}
+		for _, alert := range []string{"VeleroBackupFailed", "VeleroBackupTooOld", "CertManagerCertificateExpiringSoon",
+			"ExternalSecretSyncError", "PolicyWebhookFailing", "ArgoCDAppOutOfSync"} {
+			assert.Contains(t, alerts, alert, "%s: alerta obrigatório", env)
+		}
+		assert.Empty(t, helpers.ValidateAlertRules(rules, releases), "%s", env)
+	}
+
+	// O atraso tolerado do backup segue o schedule do ambiente
+	ev, err := helpers.NewEnvironmentEvaluator("prod")
+	require.NoError(t, err)
+	instances, err := ev.Expand()
+	require.NoError(t, err)
+	rules, err := helpers.AlertRules(instances)
+	require.NoError(t, err)
+	for _, rule := range rules {
+		if rule.Alert == "VeleroBackupTooOld" {
+			assert.Contains(t, rule.Expr, "> 25200", "Prod faz backup a cada 6h: alerta após 7h")
+		}
+	}
+}
+
+// TestAlertRuleValidation valida a leitura das métricas das expressões e os
+// erros de regras com métricas ausentes, chart errado ou metadados incompletos
+// Valida: Requisitos 10.1
+func TestAlertRuleValidation(t *testing.T) {
+	t.Parallel()
+
+	metrics, err := helpers.ExprMetrics(`histogram_quantile(0.99, sum by (le) (rate(foo_seconds_bucket[5m]))) > on() group_left {__name__="bar"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar", "foo_seconds_bucket"}, metrics)
+	_, err = helpers.ExprMetrics(`rate(foo[5m]`)
+	assert.Error(t, err, "Expressão inválida deve ser rejeitada pelo parser")
+
+	catalog, err := helpers.LoadChartMetrics("argo-cd", "5.51.0")
+	require.NoError(t, err)
+	assert.True(t, catalog["argocd_app_reconcile_bucket"], "Histograma inclui _bucket")
+	assert.True(t, catalog["argocd_app_reconcile_count"], "Histograma inclui _count")
+	assert.False(t, catalog["argocd_app_info_count"], "Gauge não inclui _count")
+
+	releases := []*helpers.HelmRelease{
+		{Chart: "kube-prometheus-stack", Version: "55.5.0"},
+		{Chart: "velero", Version: "5.2.0", File: "velero.tf", Line: 3},
+	}
+	valid := func(alert, expr string) *helpers.AlertRule {
+		return &helpers.AlertRule{
+			Source: "kubernetes_manifest.test", Chart: "velero", Alert: alert, Expr: expr, For: "15m",
+			File: "alerts.tf", Line: 10,
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: map[string]string{"summary": "s", "description": "d"},
+		}
+	}
+	rules := []*helpers.AlertRule{
+		valid("VeleroBackupFailed", `increase(velero_backup_failure_total[1h]) > 0 and on() up == 1`),
+		valid("VeleroBackupTooOld", `time() - velero_backup_last_successful_timestamp > 90000`),
+	}
+	assert.Empty(t, helpers.ValidateAlertRules(rules, releases))
+
+	unknown := valid("VeleroUnknown", `velero_backup_unknown_total > 0`)
+	foreign := valid("ArgoOnVelero", `argocd_app_info == 1`)
+	wrongChart := valid("NotInstalled", `up == 0`)
+	wrongChart.Chart = "loki"
+	badFor := valid("BadFor", `up == 0`)
+	badFor.For = "15 minutes"
+	noSeverity := valid("NoSeverity", `up == 0`)
+	noSeverity.Labels = nil
+	errs := helpers.ValidateAlertRules(append(rules, unknown, foreign, wrongChart, badFor, noSeverity), releases)
+	require.Len(t, errs, 5, "%v", errs)
+	assert.Contains(t, errs[0].Error(), "métrica velero_backup_unknown_total não exportada por velero 5.2.0")
+	assert.Contains(t, errs[1].Error(), "métrica argocd_app_info não exportada", "Métrica de outro chart não é aceita")
+	assert.Contains(t, errs[2].Error(), `chart "loki"`)
+	assert.Contains(t, errs[3].Error(), "for:")
+	assert.Contains(t, errs[4].Error(), "severity")
+	assert.Equal(t, "alerts.tf", errs[0].File, "Erro de regra deve apontar o recurso da regra")
+	assert.Equal(t, 10, errs[0].Line)
+
+	errs = helpers.ValidateAlertRules(rules[:1], releases)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "velero instalado sem os alertas VeleroBackupTooOld")
+	assert.Equal(t, "velero.tf", errs[0].File, "Alerta exigido ausente deve apontar o helm_release do chart")
+	assert.Equal(t, 3, errs[0].Line)
+}
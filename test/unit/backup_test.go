@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Velero Backup Schedule Tests
+// ============================================================================
+
+// TestCronSchedule valida o cálculo do pior RPO e das execuções por dia de
+// expressões cron e a rejeição de expressões inválidas
+// Valida: Requisitos 12.3
+func TestCronSchedule(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		expression string
+		rpo        time.Duration
+		perDay     float64
+	}{
+		{"0 2 * * *", 24 * time.Hour, 1},
+		{"0 */6 * * *", 6 * time.Hour, 4},
+		{"15 0,6,12,18 * * *", 6 * time.Hour, 4},
+		{"0 1-23/2 * * *", 2 * time.Hour, 12},
+		{"30 1,13 * * mon-fri", 60 * time.Hour, 2 * 5.0 / 7},
+		{"0 3 * * 0", 7 * 24 * time.Hour, 1.0 / 7},
+		{"0 3 * * 7", 7 * 24 * time.Hour, 1.0 / 7},
+		{"0 0 1,15 * *", 17 * 24 * time.Hour, 24 / (365 + 7.0/28)},
+		{"@daily", 24 * time.Hour, 1},
+		{"@every 8h", 8 * time.Hour, 3},
+	}
+	for _, c := range cases {
+		cron, err := helpers.ParseCron(c.expression)
+		require.NoError(t, err, c.expression)
+		assert.Equal(t, c.rpo, cron.RPO, c.expression)
+		assert.InDelta(t, c.perDay, cron.BackupsPerDay, 0.001, c.expression)
+	}
+
+	for _, expression := range []string{"", "0 2 * *", "60 * * * *", "0 */0 * * *", "0 5-2 * * *", "0 0 30 feb *", "@every 1ms"} {
+		_, err := helpers.ParseCron(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+// TestBackupScheduleByEnvironment valida o schedule renderizado do Velero em cada
+// ambiente: RPO e execuções por dia, TTL igual à expiração do bucket e namespaces
+// da plataforma incluídos no backup
+// Valida: Requisitos 12.3, 12.4
+func TestBackupScheduleByEnvironment(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]struct {
+		rpo        time.Duration
+		perDay     float64
+		ttl        string
+		expiration int
+		alertHours int
+	}{
+		"staging": {24 * time.Hour, 1, "168h", 7, 25},
+		"prod":    {6 * time.Hour, 4, "720h", 30, 7},
+	}
+	for env, want := range expected {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		require.NoError(t, err)
+		schedules, err := helpers.BackupSchedules(env, ev)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1, env)
+
+		schedule := schedules[0]
+		assert.Equal(t, "module.velero.helm_release.velero", schedule.Release, env)
+		require.NoError(t, schedule.CronError, env)
+		assert.Equal(t, want.rpo, schedule.Cron.RPO, env)
+		assert.InDelta(t, want.perDay, schedule.Cron.BackupsPerDay, 0.001, env)
+		assert.Equal(t, want.ttl, schedule.TTL, env)
+		assert.Equal(t, want.expiration, schedule.ExpirationDays, env)
+		assert.Equal(t, want.alertHours, schedule.AlertHours, env)
+		assert.Subset(t, schedule.PlatformNamespaces, []string{"argocd", "external-secrets", "velero"}, env)
+		assert.Empty(t, schedule.RPOProblems(), env)
+		assert.Empty(t, schedule.RetentionProblems(), env)
+		assert.Empty(t, schedule.NamespaceProblems(), env)
+	}
+}
+
+// TestBackupScheduleDetection valida a detecção de RPO acima do esperado ou do
+// alerta, TTL maior que a expiração do bucket e namespaces fora do backup
+// Valida: Requisitos 12.3, 12.4
+func TestBackupScheduleDetection(t *testing.T) {
+	t.Parallel()
+
+	cron, err := helpers.ParseCron("0 2 * * *")
+	require.NoError(t, err)
+	schedule := &helpers.BackupSchedule{
+		Env: "aws/prod", Schedule: "0 2 * * *", Cron: cron, AlertHours: 7,
+		TTL: "720h", ExpirationDays: 7, Bucket: "eks-prod-velero-backups",
+		IncludedNamespaces: []string{"argocd"}, ExcludedNamespaces: []string{"kube-system"},
+		PlatformNamespaces: []string{"argocd", "kube-system", "observability"},
+	}
+	assert.Equal(t, []string{
+		`schedule "0 2 * * *" tem RPO de 24h; esperado no máximo 6h`,
+		`schedule "0 2 * * *" tem RPO de 24h; backup_max_age_hours = 7 dispara entre execuções normais`,
+	}, schedule.RPOProblems())
+	assert.Equal(t, []string{
+		`ttl 720h maior que a expiração de 7 dias do bucket "eks-prod-velero-backups"; o S3 apaga backups que o Velero ainda lista`,
+	}, schedule.RetentionProblems())
+	assert.Equal(t, []string{
+		"namespace observability não está em includedNamespaces nem em excludedNamespaces",
+	}, schedule.NamespaceProblems())
+
+	schedule.ExpirationDays = 0
+	assert.Equal(t, []string{`bucket "eks-prod-velero-backups" sem regra de expiração no lifecycle`}, schedule.RetentionProblems())
+	schedule.TTL = "30d"
+	assert.Equal(t, []string{`ttl "30d" inválido`}, schedule.RetentionProblems())
+
+	_, err = helpers.ParseCron("0 25 * * *")
+	schedule.Cron, schedule.CronError = nil, err
+	assert.Equal(t, []string{err.Error()}, schedule.RPOProblems())
+}
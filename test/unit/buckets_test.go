@@ -0,0 +1,185 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// S3 Bucket Posture Tests
+// ============================================================================
+
+// TestBucketPostureByEnvironment valida que os buckets de auditoria e de backups
+// de cada ambiente bloqueiam acesso público, exigem TLS, são criptografados e
+// versionados, expiram versões antigas e negam deleção conforme a regra do Purpose
+// Valida: Requisitos 12.1, 18.4, 18.5
+func TestBucketPostureByEnvironment(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadBucketPostureConfig(helpers.GetBucketPostureConfigPath())
+	require.NoError(t, err)
+
+	for _, env := range []string{"staging", "prod"} {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		require.NoError(t, err)
+		instances, err := ev.Expand()
+		require.NoError(t, err)
+		buckets, err := helpers.BucketPostures(env, instances, config)
+		require.NoError(t, err)
+
+		byPurpose := make(map[string]*helpers.BucketPosture)
+		for _, bucket := range buckets {
+			byPurpose[bucket.Purpose] = bucket
+			assert.Empty(t, bucket.Problems(), "%s: %s", env, bucket.Address)
+			assert.True(t, bucket.DenyInsecureTransport, "%s: %s", env, bucket.Address)
+			assert.Equal(t, "Enabled", bucket.Versioning, "%s: %s", env, bucket.Address)
+		}
+		require.Contains(t, byPurpose, "audit-logs", env)
+		require.Contains(t, byPurpose, "velero-backups", env)
+
+		audit := byPurpose["audit-logs"]
+		assert.Equal(t, "module.compliance.aws_s3_bucket.audit_logs", audit.Address)
+		assert.Equal(t, []string{"s3:DeleteBucket", "s3:DeleteObject", "s3:DeleteObjectVersion"}, audit.DeniedDeletes, env)
+		assert.Equal(t, 30, audit.NoncurrentDays, env)
+
+		// O Velero precisa apagar os backups expirados
+		velero := byPurpose["velero-backups"]
+		assert.Equal(t, "module.velero.aws_s3_bucket.velero_backups", velero.Address)
+		assert.Equal(t, []string{"s3:DeleteBucket"}, velero.DeniedDeletes, env)
+		assert.False(t, velero.Rule.ProtectObjects, env)
+	}
+}
+
+// TestBucketPostureDetection valida a detecção de bloqueio de acesso público
+// incompleto, criptografia abaixo da regra, versionamento suspenso, versões
+// antigas sem expiração, ausência do Deny sem TLS e deleções permitidas
+// Valida: Requisitos 18.5
+func TestBucketPostureDetection(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadBucketPostureConfig(helpers.GetBucketPostureConfigPath())
+	require.NoError(t, err)
+	assert.Equal(t, "aws:kms", config.Rule("data-lake").Encryption)
+	assert.True(t, config.Rule("data-lake").ProtectObjects)
+
+	bucket := &helpers.BucketPosture{
+		Address: "aws_s3_bucket.data", Purpose: "data-lake", Rule: config.Rule("data-lake"),
+		PublicAccessBlock: map[string]bool{
+			"block_public_acls": true, "block_public_policy": true, "ignore_public_acls": true,
+		},
+		SSEAlgorithm: "AES256", Versioning: "Suspended",
+		DeniedDeletes: []string{"s3:DeleteBucket"},
+	}
+	assert.Equal(t, []string{
+		"aws_s3_bucket_public_access_block.restrict_public_buckets deve ser true",
+		"criptografia AES256; a regra de Purpose=data-lake exige aws:kms com kms_master_key_id",
+		`versionamento "Suspended"; esperado Enabled`,
+		"lifecycle sem noncurrent_version_expiration; versões antigas ficam para sempre",
+		"bucket policy sem Deny de s3:* com aws:SecureTransport = false no bucket e em <arn>/*",
+		"bucket policy não nega s3:DeleteObject a todos os principals",
+		"bucket policy não nega s3:DeleteObjectVersion a todos os principals",
+	}, bucket.Problems())
+
+	bucket.PublicAccessBlock["restrict_public_buckets"] = true
+	bucket.SSEAlgorithm, bucket.KMSKeyID = "aws:kms", "arn:aws:kms:us-east-1:123456789012:key/abc"
+	bucket.Versioning, bucket.NoncurrentDays = "Enabled", 30
+	bucket.DenyInsecureTransport = true
+	bucket.DeniedDeletes = []string{"s3:DeleteBucket", "s3:DeleteObject", "s3:DeleteObjectVersion"}
+	assert.Empty(t, bucket.Problems())
+
+	bucket.KMSKeyID = ""
+	assert.Equal(t, []string{"criptografia aws:kms; a regra de Purpose=data-lake exige aws:kms com kms_master_key_id"}, bucket.Problems())
+	bucket.KMSKeyID = "alias/data"
+	bucket.PolicyError = errors.New("bucket policy inválida")
+	assert.Equal(t, []string{"bucket policy inválida"}, bucket.Problems())
+}
+
+// TestBucketPolicyResources valida que o Deny sem TLS só conta quando cobre o ARN
+// do bucket e <arn>/*, e que cada deleção só conta no ARN em que atua
+// Valida: Requisitos 18.5
+func TestBucketPolicyResources(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadBucketPostureConfig(helpers.GetBucketPostureConfigPath())
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		tls     string
+		deletes string
+		secure  bool
+		denied  []string
+	}{
+		{
+			name: "ambos os ARNs", tls: `[aws_s3_bucket.data.arn, "${aws_s3_bucket.data.arn}/*"]`,
+			deletes: `[aws_s3_bucket.data.arn, "${aws_s3_bucket.data.arn}/*"]`, secure: true,
+			denied: []string{"s3:DeleteBucket", "s3:DeleteObject", "s3:DeleteObjectVersion"},
+		},
+		{
+			name: "apenas o bucket", tls: `[aws_s3_bucket.data.arn]`,
+			deletes: `[aws_s3_bucket.data.arn]`, denied: []string{"s3:DeleteBucket"},
+		},
+		{
+			name: "apenas os objetos", tls: `["${aws_s3_bucket.data.arn}/*"]`,
+			deletes: `["${aws_s3_bucket.data.arn}/*"]`, denied: []string{"s3:DeleteObject", "s3:DeleteObjectVersion"},
+		},
+		{
+			name: "curinga", tls: `["arn:aws:s3:::data-*"]`, deletes: `["arn:aws:s3:::other", "arn:aws:s3:::other/*"]`, secure: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			main := `
+resource "aws_s3_bucket" "data" {
+  bucket = "data-bucket"
+}
+
+resource "aws_s3_bucket_policy" "data" {
+  bucket = aws_s3_bucket.data.id
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Sid       = "DenyInsecureTransport"
+        Effect    = "Deny"
+        Principal = "*"
+        Action    = "s3:*"
+        Resource  = ` + c.tls + `
+        Condition = { Bool = { "aws:SecureTransport" = "false" } }
+      },
+      {
+        Sid       = "DenyDeletes"
+        Effect    = "Deny"
+        Principal = { AWS = "*" }
+        Action    = ["s3:DeleteBucket", "s3:DeleteObject*"]
+        Resource  = ` + c.deletes + `
+      },
+    ]
+  })
+}
+`
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(main), 0o644))
+			mod, err := helpers.LoadModule(dir)
+			require.NoError(t, err)
+			ev, err := helpers.NewEvaluator(mod, nil)
+			require.NoError(t, err)
+			instances, err := ev.Expand()
+			require.NoError(t, err)
+			buckets, err := helpers.BucketPostures("test", instances, config)
+			require.NoError(t, err)
+			require.Len(t, buckets, 1)
+
+			bucket := buckets[0]
+			require.NoError(t, bucket.PolicyError)
+			assert.Equal(t, c.secure, bucket.DenyInsecureTransport)
+			assert.Equal(t, c.denied, bucket.DeniedDeletes)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Node Capacity Tests
+// ============================================================================
+
+// TestNodeCapacityFormulas valida o máximo de pods do VPC CNI e os recursos
+// alocáveis após as reservas do kubelet
+// Valida: Requisitos 6.4
+func TestNodeCapacityFormulas(t *testing.T) {
+	t.Parallel()
+
+	table, err := helpers.LoadInstanceTable(helpers.GetInstanceTablePath())
+	require.NoError(t, err)
+
+	for instanceType, pods := range map[string]int{"t3.medium": 17, "t3.large": 35, "m5.large": 29, "m5.xlarge": 58} {
+		node, err := table.Node(instanceType)
+		require.NoError(t, err)
+		assert.Equal(t, pods, node.Allocatable.Pods, "%s: máximo de pods do VPC CNI", instanceType)
+		assert.Less(t, node.Allocatable.CPU, node.Capacity.CPU, "%s: CPU reservada pelo kubelet", instanceType)
+		assert.Less(t, node.Allocatable.Memory, node.Capacity.Memory, "%s: memória reservada pelo kubelet", instanceType)
+	}
+
+	node, err := table.Node("t3.medium")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1930), node.Allocatable.CPU, "2 vCPUs reservam 60m + 10m")
+	assert.Equal(t, int64(4096-(11*17+255)-100), node.Allocatable.Memory, "Memória reservada: 11Mi por pod + 255Mi + despejo")
+
+	assert.Equal(t, 110, helpers.MaxPods(&helpers.InstanceSpec{VCPU: 16, ENIs: 8, IPv4PerENI: 30}), "Instâncias com menos de 30 vCPUs têm teto de 110 pods")
+	assert.Equal(t, 250, helpers.MaxPods(&helpers.InstanceSpec{VCPU: 96, ENIs: 15, IPv4PerENI: 50}), "Instâncias maiores têm teto de 250 pods")
+
+	_, err = table.Node("x9.huge")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instances.x9.huge", "Tipo fora da tabela deve ser erro")
+}
+
+// TestSystemNodeGroupFitsAddons valida que os requests declarados dos add-ons e
+// os pods do EKS cabem no node group system com min_size nodes em cada ambiente
+// Valida: Requisitos 6.3, 6.7
+func TestSystemNodeGroupFitsAddons(t *testing.T) {
+	t.Parallel()
+
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	capacities, err := helpers.RunCapacityChecks(report, helpers.GetInstanceTablePath(), "staging", "prod")
+	require.NoError(t, err)
+	for _, finding := range report.Findings {
+		assert.NotEqual(t, helpers.SeverityError, finding.Severity, "%s", finding)
+	}
+	require.Len(t, capacities, 2)
+
+	for _, capacity := range capacities {
+		system := capacity.Cluster.Group("system")
+		require.NotNil(t, system, "%s: node group system", capacity.Environment)
+		assert.Equal(t, system.MinSize, capacity.System.Nodes, "%s: demanda deve ser comparada no min_size", capacity.Environment)
+		assert.True(t, capacity.System.Fits(), "%s: %v", capacity.Environment, capacity.System.Problems)
+		assert.NotContains(t, capacity.System.Undeclared, "module.argocd.helm_release.argocd:controller",
+			"%s: requests declarados do ArgoCD devem entrar no cálculo", capacity.Environment)
+		assert.GreaterOrEqual(t, capacity.System.Demand.CPU, int64(450), "%s: requests do ArgoCD (250m + 100m + 100m)", capacity.Environment)
+
+		atMin, atMax := capacity.Cluster.Min(), capacity.Cluster.Max()
+		assert.LessOrEqual(t, atMin.Pods, atMax.Pods)
+		assert.LessOrEqual(t, atMin.CPU, atMax.CPU)
+	}
+
+	// Com 2 nodes t3.medium, os pods dos add-ons não cabem no system de staging
+	staging := capacities[0]
+	require.Equal(t, "staging", staging.Environment)
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	instances, err := ev.Expand()
+	require.NoError(t, err)
+	var placements []*helpers.PodPlacement
+	releases, err := helpers.HelmReleases(instances)
+	require.NoError(t, err)
+	for _, release := range releases {
+		found, err := helpers.HelmPlacements(release)
+		require.NoError(t, err)
+		placements = append(placements, found...)
+	}
+	table, err := helpers.LoadInstanceTable(helpers.GetInstanceTablePath())
+	require.NoError(t, err)
+	fit, err := staging.Cluster.Group("system").Fit(placements, table.SystemPods, 2)
+	require.NoError(t, err)
+	assert.False(t, fit.Fits(), "Demanda de pods acima do máximo do VPC CNI deve ser detectada")
+	require.NotEmpty(t, fit.Problems)
+	assert.Contains(t, fit.Problems[0], "pods acima do alocável de 34")
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ============================================================================
+// ClusterIssuer Tests
+// ============================================================================
+
+// TestClusterIssuerMatchesEnvironment valida que o ClusterIssuer usa o Let's
+// Encrypt de staging no staging e o de produção no prod, com solver HTTP-01 na
+// IngressClass do controller instalado
+// Valida: Requisitos 11.3
+func TestClusterIssuerMatchesEnvironment(t *testing.T) {
+	t.Parallel()
+
+	servers := map[string]string{
+		"staging": helpers.LetsEncryptStagingURL,
+		"prod":    helpers.LetsEncryptProductionURL,
+	}
+	for env, server := range servers {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		require.NoError(t, err)
+		issuers, err := helpers.ClusterIssuers(env, ev)
+		require.NoError(t, err)
+		require.Len(t, issuers, 1, env)
+
+		issuer := issuers[0]
+		assert.Equal(t, "module.ingress.kubernetes_manifest.letsencrypt_issuer", issuer.Address)
+		assert.Equal(t, server, issuer.Server, env)
+		assert.Equal(t, "alb", issuer.IngressClass, env)
+		assert.Empty(t, issuer.Problems(), env)
+	}
+
+	// Com ingress-nginx o solver acompanha a IngressClass do controller
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	require.NoError(t, ev.OverrideModuleArgument("ingress", "ingress_type", cty.StringVal("nginx")))
+	issuers, err := helpers.ClusterIssuers("staging", ev)
+	require.NoError(t, err)
+	require.Len(t, issuers, 1)
+	assert.Equal(t, "nginx", issuers[0].IngressClass)
+	assert.Empty(t, issuers[0].Problems())
+}
+
+// TestClusterIssuerDetection valida a detecção de servidor ACME trocado entre
+// ambientes, email de exemplo em produção e solvers incompatíveis com o ingress
+// ou com a zona Route53
+// Valida: Requisitos 11.3, 11.5
+func TestClusterIssuerDetection(t *testing.T) {
+	t.Parallel()
+
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	require.NoError(t, ev.OverrideModuleArgument("ingress", "letsencrypt_environment", cty.StringVal("production")))
+	issuers, err := helpers.ClusterIssuers("staging", ev)
+	require.NoError(t, err)
+	require.Len(t, issuers, 1)
+	assert.Equal(t, []string{
+		`servidor ACME "` + helpers.LetsEncryptProductionURL + `"; esperado "` + helpers.LetsEncryptStagingURL + `"`,
+		`email "admin@example.com" é de exemplo; o Let's Encrypt de produção envia avisos de expiração para ele`,
+	}, issuers[0].Problems())
+
+	issuer := &helpers.ClusterIssuer{
+		Env: "aws/prod", Server: helpers.LetsEncryptProductionURL, Email: "ops@corp.io",
+		IngressType: "alb", IngressClass: "alb", ZoneID: "Z1",
+		Solvers: []map[string]interface{}{
+			{"http01": map[string]interface{}{"ingress": map[string]interface{}{"class": "nginx"}}},
+			{"dns01": map[string]interface{}{"route53": map[string]interface{}{"hostedZoneID": "Z2"}}},
+			{"dns01": map[string]interface{}{"cloudflare": map[string]interface{}{}}},
+			{"dns01": map[string]interface{}{"route53": map[string]interface{}{"hostedZoneID": "Z1"}}},
+		},
+	}
+	assert.Equal(t, []string{
+		`solvers[0]: http01 usa a IngressClass "nginx"; o controller alb atende "alb"`,
+		"solvers[0]: http01 no ALB precisa da anotação alb.ingress.kubernetes.io/scheme=internet-facing",
+		`solvers[1]: dns01 usa a zona "Z2"; a zona do external-dns é "Z1"`,
+		"solvers[2]: dns01 deve usar o Route53",
+	}, issuer.Problems())
+
+	// A validação do prod rejeita emails de exemplo antes do plan
+	dir := helpers.GetEnvironmentPath("prod")
+	mod, err := helpers.LoadModule(dir)
+	require.NoError(t, err)
+	inputs, err := helpers.ReadTFVars(filepath.Join(dir, "terraform.tfvars.example"))
+	require.NoError(t, err)
+	for email, valid := range map[string]bool{
+		"platform-team@your-company.com": true,
+		"admin@example.com":              false,
+		"ops@mail.Example.org":           false,
+		"not-an-email":                   false,
+	} {
+		inputs["cert_manager_email"] = cty.StringVal(email)
+		prod, err := helpers.NewEvaluator(mod, inputs)
+		require.NoError(t, err)
+		errs := prod.ValidateVariables()
+		if valid {
+			assert.Empty(t, errs, email)
+		} else {
+			require.Len(t, errs, 1, email)
+			assert.Contains(t, errs[0].Error(), "cert_manager_email", email)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// templatecheck Checks Tests
+// ============================================================================
+
+// findingsByCheck agrupa as mensagens dos achados por ID de verificação
+func findingsByCheck(report *helpers.Report) map[string][]*helpers.Finding {
+	grouped := make(map[string][]*helpers.Finding)
+	for _, finding := range report.Findings {
+		grouped[finding.CheckID] = append(grouped[finding.CheckID], finding)
+	}
+	return grouped
+}
+
+// TestLintChecksPass valida que o repositório não tem erros nas verificações estáticas
+// Valida: Requisitos 3.5, 15.3, 15.4
+func TestLintChecksPass(t *testing.T) {
+	t.Parallel()
+
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	require.NoError(t, helpers.RunLintChecks(report))
+
+	assert.Len(t, report.Checks, 6, "Todas as verificações de lint devem ser registradas")
+	for _, finding := range report.Findings {
+		assert.NotEqual(t, helpers.SeverityError, finding.Severity, "%s", finding)
+	}
+}
+
+// TestEnvironmentAndPolicyChecksPass valida as verificações de ambiente e de políticas
+// usadas pelo templatecheck em cada ambiente, com as supressões do repositório
+// Valida: Requisitos 5.6, 8.2, 8.3, 8.4, 8.5, 8.6, 8.7
+func TestEnvironmentAndPolicyChecksPass(t *testing.T) {
+	t.Parallel()
+
+	for _, env := range []string{"staging", "prod"} {
+		env := env
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			report := helpers.NewReport(helpers.GetProjectRoot())
+			require.NoError(t, helpers.RunEnvironmentChecks(report, env))
+			require.NoError(t, helpers.RunPolicyChecks(report, env))
+			require.NoError(t, report.Suppress(helpers.GetBaselinePath(), time.Now()))
+
+			// Apenas staging aceita o endpoint público aberto, com supressão inline
+			var publicEndpoint []*helpers.Finding
+			for _, finding := range findingsByCheck(report)[helpers.CheckEKSPublicEndpoint] {
+				require.NotNil(t, finding.Suppression, "Endpoint público aberto deve estar suprimido: %s", finding)
+				publicEndpoint = append(publicEndpoint, finding)
+			}
+			if env == "staging" {
+				assert.Len(t, publicEndpoint, 1, "Staging usa 0.0.0.0/0 no endpoint público")
+			} else {
+				assert.Empty(t, publicEndpoint, "Prod deve restringir o endpoint público por CIDR")
+			}
+
+			// Achados localizados aparecem no SARIF e aceitam supressão inline
+			for _, finding := range findingsByCheck(report)[helpers.CheckUpgradePlan] {
+				assert.NotEmpty(t, finding.File, "Item do plano de upgrade sem arquivo: %s", finding)
+				assert.Positive(t, finding.Line, "Item do plano de upgrade sem linha: %s", finding)
+			}
+
+			assert.False(t, report.Failed(helpers.SeverityError), "Ambiente %s não deve ter achados com severidade error", env)
+			for _, finding := range report.Findings {
+				if finding.Severity == helpers.SeverityError && finding.Suppression == nil {
+					t.Logf("%s", finding)
+				}
+			}
+		})
+	}
+}
+
+// TestPlanChecksDetectRiskyChanges valida as verificações sobre a saída de
+// terraform show -json
+// Valida: Requisitos 5.6, 17.1
+func TestPlanChecksDetectRiskyChanges(t *testing.T) {
+	t.Parallel()
+
+	path := helpers.GetTestPath("testdata", "plans", "staging-replace-cluster.json")
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	require.NoError(t, helpers.RunPlanChecks(report, path))
+
+	grouped := findingsByCheck(report)
+
+	destructive := grouped[helpers.CheckPlanDestructiveChange]
+	require.Len(t, destructive, 1, "Apenas a substituição do cluster é destrutiva; helm_release não é protegido")
+	assert.Contains(t, destructive[0].Message, "substitui module.eks_cluster.aws_eks_cluster.main")
+
+	tags := grouped[helpers.CheckPlanRequiredTags]
+	require.Len(t, tags, 1, "Tags conhecidas apenas após o apply não devem ser reportadas")
+	assert.Contains(t, tags[0].Message, "module.velero.aws_s3_bucket.velero sem as tags ManagedBy, Project")
+	assert.Equal(t, "17.1", tags[0].Requirement)
+
+	open := grouped[helpers.CheckPlanOpenIngress]
+	require.Len(t, open, 1, "Regra de ingress restrita à VPC não deve ser reportada")
+	assert.Equal(t, helpers.SeverityWarning, open[0].Severity)
+	assert.Contains(t, open[0].Message, "aws_eks_cluster.main permite acesso de 0.0.0.0/0")
+
+	_, err := helpers.LoadTerraformPlan(helpers.GetTestPath("go.mod"))
+	assert.Error(t, err, "Arquivos que não são saída de terraform show -json devem ser rejeitados")
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Cost Estimation Tests
+// ============================================================================
+
+// TestEnvironmentCostWithinBudget valida a estimativa de custo mensal de cada
+// ambiente contra o orçamento e que staging usa a configuração econômica
+// Valida: Requisitos 17.2, 17.3
+func TestEnvironmentCostWithinBudget(t *testing.T) {
+	t.Parallel()
+
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	estimates, err := helpers.RunCostChecks(report, helpers.GetCostConfigPath(), "staging", "prod")
+	require.NoError(t, err)
+	for _, finding := range report.Findings {
+		assert.NotEqual(t, helpers.SeverityError, finding.Severity, "%s", finding)
+	}
+	require.Len(t, estimates, 2)
+
+	components := make(map[string]map[string]int)
+	for _, estimate := range estimates {
+		assert.NotEmpty(t, estimate.PriceVersion, "Estimativa deve citar a versão da tabela de preços")
+		components[estimate.Environment] = make(map[string]int)
+		for _, item := range estimate.Items {
+			components[estimate.Environment][item.Component]++
+			assert.LessOrEqual(t, item.Monthly.Min, item.Monthly.Expected, "%s: mínimo acima do esperado", item.Address)
+			assert.LessOrEqual(t, item.Monthly.Expected, item.Monthly.Max, "%s: esperado acima do máximo", item.Address)
+		}
+		total := estimate.Total()
+		assert.Greater(t, total.Expected, 0.0)
+		t.Logf("%s: mínimo %.2f, esperado %.2f, máximo %.2f", estimate.Environment, total.Min, total.Expected, total.Max)
+	}
+
+	staging, prod := estimates[0], estimates[1]
+	assert.Less(t, staging.Total().Expected, prod.Total().Expected, "Staging deve custar menos que prod")
+	assert.Equal(t, 1, components["staging"]["NAT Gateway"], "Staging deve usar single NAT Gateway")
+	assert.Equal(t, 3, components["prod"]["NAT Gateway"], "Prod deve usar um NAT Gateway por AZ")
+	for _, env := range []string{"staging", "prod"} {
+		for _, component := range []string{"EKS control plane", "EC2", "EBS", "VPC endpoint", "CloudWatch Logs", "S3"} {
+			assert.NotZero(t, components[env][component], "%s: estimativa deve incluir %s", env, component)
+		}
+	}
+}
+
+// TestCostBudgetGuard valida que o custo acima do orçamento reprova a execução e
+// que preços ausentes na tabela são erro, não custo zero
+// Valida: Requisitos 17.2
+func TestCostBudgetGuard(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	prices := helpers.GetTestPath("testdata", "prices", "aws-us-east-1.yaml")
+	config := filepath.Join(dir, "costs.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(fmt.Sprintf(`prices: %s
+environments:
+  staging:
+    budget: 100
+    max_budget: 200
+    usage:
+      log_ingestion_gb: {min: 1, expected: 1, max: 1}
+      s3_storage_gb: {min: 1, expected: 1, max: 1}
+`, filepath.ToSlash(prices))), 0o644))
+
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	_, err := helpers.RunCostChecks(report, config, "staging")
+	require.NoError(t, err)
+	assert.True(t, report.Failed(helpers.SeverityError), "Custo acima do orçamento deve reprovar a execução")
+
+	var failures []string
+	for _, finding := range report.Findings {
+		if finding.Severity == helpers.SeverityError {
+			failures = append(failures, finding.Message)
+		}
+	}
+	require.Len(t, failures, 2, "Orçamento esperado e max_budget devem ser verificados")
+	assert.Contains(t, failures[0], "excede o orçamento de $100.00")
+	assert.Contains(t, failures[1], "excede o max_budget de $200.00")
+
+	_, err = helpers.RunCostChecks(helpers.NewReport(helpers.GetProjectRoot()), config, "prod")
+	assert.Error(t, err, "Ambiente sem orçamento deve ser erro")
+
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	instances, err := ev.Expand()
+	require.NoError(t, err)
+	table, err := helpers.LoadPriceTable(prices)
+	require.NoError(t, err)
+	delete(table.Instances, "t3.large")
+	_, err = helpers.EstimateCost(instances, table, helpers.CostUsage{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "instances.t3.large", "Erro deve citar o preço ausente")
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Cost Diff Tests
+// ============================================================================
+
+// TestCostDiffAttributesDelta valida que a diferença de custo entre staging e
+// prod é atribuída às variáveis que a causam e que a soma das origens é a
+// diferença total
+// Valida: Requisitos 17.2, 17.3
+func TestCostDiffAttributesDelta(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadCostConfig(helpers.GetCostConfigPath())
+	require.NoError(t, err)
+	diff, err := helpers.DiffCost(helpers.EnvironmentCostSource("staging"), helpers.EnvironmentCostSource("prod"), config)
+	require.NoError(t, err)
+
+	delta := diff.Delta()
+	assert.Greater(t, delta.Expected, 0.0, "Prod deve custar mais que staging")
+
+	attributions := make(map[string]*helpers.CostAttribution)
+	sum := 0.0
+	for _, a := range diff.Attributions {
+		if a.Variable != "" {
+			attributions[a.Variable] = a
+		}
+		sum += a.Delta.Expected
+	}
+	assert.InDelta(t, delta.Expected, sum, 0.01, "A soma das origens deve ser a diferença total")
+
+	nat := attributions["single_nat_gateway"]
+	require.NotNil(t, nat, "single_nat_gateway deve explicar parte da diferença")
+	assert.Equal(t, "true → false", nat.Change)
+	assert.Contains(t, nat.Effects, "+2 NAT Gateway (1 → 3)")
+	assert.Greater(t, nat.Delta.Expected, 0.0)
+
+	nodeGroups := attributions["node_groups"]
+	require.NotNil(t, nodeGroups, "node_groups deve explicar parte da diferença")
+	assert.Contains(t, nodeGroups.Change, "apps.desired_size: 3 → 10")
+
+	assert.Contains(t, diff.Unchanged, "cluster_name", "Variáveis sem efeito no custo devem ser listadas à parte")
+	assert.Contains(t, diff.Unchanged, "grafana_admin_password")
+
+	added := 0
+	for _, r := range diff.Resources {
+		if r.Component == "NAT Gateway" {
+			assert.Equal(t, "adicionado", r.Change, "%s", r.Address)
+			added++
+		}
+	}
+	assert.Equal(t, 2, added, "Os NAT Gateways de prod devem aparecer como recursos adicionados")
+
+	markdown := diff.Markdown()
+	assert.Contains(t, markdown, "### Diferença de custo mensal: staging → prod")
+	assert.Contains(t, markdown, "| `single_nat_gateway` | true → false |")
+	assert.NotContains(t, markdown, "CHANGE_ME", "Valores de variáveis sensíveis não devem aparecer no comentário")
+}
+
+// TestCostDiffBetweenRevisions valida a comparação de revisões do git do mesmo
+// ambiente em um repositório com duas revisões de staging que mudam o
+// desired_size do node group apps
+// Valida: Requisitos 17.2
+func TestCostDiffBetweenRevisions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git indisponível")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=templatecheck",
+			"-c", "user.email=templatecheck@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, output)
+	}
+	rel := filepath.Join("live", "aws", "staging")
+	copyTree(t, helpers.GetModulesPath(), filepath.Join(repo, "modules"))
+	copyTree(t, helpers.GetEnvironmentPath("staging"), filepath.Join(repo, rel))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "staging")
+
+	tfvars := filepath.Join(repo, rel, "terraform.tfvars.example")
+	content, err := os.ReadFile(tfvars)
+	require.NoError(t, err)
+	apps := strings.Index(string(content), "  apps = {")
+	require.NotEqual(t, -1, apps, "tfvars de staging deve declarar o node group apps")
+	changed := string(content[:apps]) + strings.Replace(string(content[apps:]), "desired_size   = 3", "desired_size   = 5", 1)
+	require.NotEqual(t, string(content), changed)
+	require.NoError(t, os.WriteFile(tfvars, []byte(changed), 0o644))
+	git("commit", "-q", "-am", "apps desired_size 5")
+
+	config, err := helpers.LoadCostConfig(helpers.GetCostConfigPath())
+	require.NoError(t, err)
+	base, cleanup, err := helpers.RepositoryCostSource(repo, "HEAD~1", "staging", rel)
+	require.NoError(t, err)
+	defer cleanup()
+	head, cleanupHead, err := helpers.RepositoryCostSource(repo, "HEAD", "staging", rel)
+	require.NoError(t, err)
+	defer cleanupHead()
+
+	diff, err := helpers.DiffCost(base, head, config)
+	require.NoError(t, err)
+	assert.Equal(t, "staging@HEAD~1", diff.Base.Environment)
+	assert.Equal(t, "staging@HEAD", diff.Head.Environment)
+	delta := diff.Delta()
+	assert.Greater(t, delta.Expected, 0.0, "Dois nodes apps a mais devem aumentar o custo")
+
+	require.Len(t, diff.Attributions, 1, "Apenas node_groups mudou entre as revisões")
+	nodeGroups := diff.Attributions[0]
+	assert.Equal(t, "node_groups", nodeGroups.Variable)
+	assert.Equal(t, "apps.desired_size: 3 → 5", nodeGroups.Change)
+	assert.InDelta(t, delta.Expected, nodeGroups.Delta.Expected, 0.01, "node_groups deve explicar toda a diferença")
+	assert.Contains(t, diff.Markdown(), "| `node_groups` | apps.desired_size: 3 → 5 |")
+
+	same, cleanupSame, err := helpers.RepositoryCostSource(repo, "HEAD", "staging", rel)
+	require.NoError(t, err)
+	defer cleanupSame()
+	diff, err = helpers.DiffCost(head, same, config)
+	require.NoError(t, err)
+	assert.Less(t, math.Abs(diff.Delta().Expected), 0.01, "A mesma revisão não deve ter diferença de custo")
+	assert.Empty(t, diff.Attributions)
+	assert.Empty(t, diff.Resources)
+	assert.Contains(t, diff.Markdown(), "Sem diferença de custo.")
+
+	_, _, err = helpers.RepositoryCostSource(repo, "revisao-inexistente", "staging", rel)
+	assert.Error(t, err, "Revisão inexistente deve ser erro")
+}
+
+// copyTree copia os arquivos de src para dst
+func copyTree(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			if entry.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ============================================================================
+// External DNS Scope Tests
+// ============================================================================
+
+// TestExternalDNSScopedToZone valida que o external-dns de cada ambiente só tem
+// permissão de alterar a zona Route53 do ambiente, filtra o mesmo domínio e zona
+// nos values e usa um txtOwnerId próprio
+// Valida: Requisitos 11.4, 11.5
+func TestExternalDNSScopedToZone(t *testing.T) {
+	t.Parallel()
+
+	var all []*helpers.ExternalDNSScope
+	for _, env := range []string{"staging", "prod"} {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		require.NoError(t, err)
+		scopes, err := helpers.ExternalDNSScopes(env, ev)
+		require.NoError(t, err)
+		require.Len(t, scopes, 1, "%s: external-dns deve ser instalado pelo módulo ingress", env)
+
+		scope := scopes[0]
+		assert.Equal(t, "module.ingress.helm_release.external_dns", scope.Release)
+		assert.NotEmpty(t, scope.ZoneID, env)
+		assert.Equal(t, []string{scope.ZoneID}, scope.ZoneIDFilters, env)
+		assert.Equal(t, []string{scope.DomainName}, scope.DomainFilters, env)
+		assert.Empty(t, scope.Problems(), env)
+		for _, grant := range scope.Grants {
+			if grant.Action == "route53:ChangeResourceRecordSets" {
+				assert.Equal(t, scope.ZoneARN(), grant.Resource, "%s: alterações restritas à zona do ambiente", env)
+			}
+		}
+		all = append(all, scope)
+	}
+	assert.Empty(t, helpers.ExternalDNSOwnerConflicts(all), "txtOwnerId deve ser único por ambiente")
+
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	_, err := helpers.RunExternalDNSChecks(report, "staging", "prod")
+	require.NoError(t, err)
+	assert.Empty(t, report.Findings)
+}
+
+// TestExternalDNSScopeDetection valida a detecção de permissões além da zona,
+// filtros divergentes e txtOwnerId repetido entre ambientes
+// Valida: Requisitos 11.4, 11.5
+func TestExternalDNSScopeDetection(t *testing.T) {
+	t.Parallel()
+
+	zone := "arn:aws:route53:::hostedzone/Z1"
+	scope := &helpers.ExternalDNSScope{
+		Env: "staging", Release: "helm_release.external_dns", ZoneID: "Z1", DomainName: "staging.example.com",
+		DomainFilters: []string{"example.com"}, ZoneIDFilters: nil, TXTOwnerID: "",
+		Grants: []*helpers.IAMGrant{
+			{Action: "route53:ChangeResourceRecordSets", Resource: zone},
+			{Action: "route53:ChangeResourceRecordSets", Resource: "arn:aws:route53:::hostedzone/*"},
+			{Action: "route53:ListResourceRecordSets", Resource: "*"},
+			{Action: "route53:ListHostedZones", Resource: "*"},
+			{Action: "s3:GetObject", Resource: "*"},
+		},
+	}
+	assert.ElementsMatch(t, []string{
+		"permissão route53:ChangeResourceRecordSets em arn:aws:route53:::hostedzone/* deve ser restrita a " + zone,
+		"permissão route53:ListResourceRecordSets em * deve ser restrita a " + zone,
+		"permissão fora do Route53: s3:GetObject em *",
+		"domainFilters [example.com] deve ser [staging.example.com]",
+		"--zone-id-filter [] deve ser [Z1]",
+		"txtOwnerId deve ser definido",
+	}, scope.Problems())
+
+	// Staging com o mesmo cluster_name do prod compartilha o dono dos registros TXT
+	var all []*helpers.ExternalDNSScope
+	for _, env := range []string{"staging", "prod"} {
+		ev, err := helpers.NewEnvironmentEvaluator(env)
+		require.NoError(t, err)
+		require.NoError(t, ev.OverrideModuleArgument("ingress", "cluster_name", cty.StringVal("eks-shared")))
+		scopes, err := helpers.ExternalDNSScopes(env, ev)
+		require.NoError(t, err)
+		require.Len(t, scopes, 1)
+		assert.Empty(t, scopes[0].Problems(), "Escopo da zona não depende do dono")
+		all = append(all, scopes...)
+	}
+	conflicts := helpers.ExternalDNSOwnerConflicts(all)
+	require.Contains(t, conflicts, "eks-shared")
+	assert.Len(t, conflicts["eks-shared"], 2)
+
+	// Outra zona no mesmo ambiente muda policy e filtros juntos
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	require.NoError(t, ev.OverrideModuleArgument("ingress", "route53_zone_id", cty.StringVal("ZOTHER")))
+	scopes, err := helpers.ExternalDNSScopes("staging", ev)
+	require.NoError(t, err)
+	require.Len(t, scopes, 1)
+	assert.Equal(t, []string{"ZOTHER"}, scopes[0].ZoneIDFilters)
+	assert.Empty(t, scopes[0].Problems())
+}
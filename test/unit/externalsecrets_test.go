@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// ExternalSecret Example Tests
+// ============================================================================
+
+// TestExternalSecretExamplesMatchStores valida que os exemplos de ExternalSecret
+// usam os ClusterSecretStores do módulo e pedem apenas chaves que a role IRSA do
+// prod pode ler; no staging as chaves prod/* são negadas
+// Valida: Requisitos 9.1, 9.2
+func TestExternalSecretExamplesMatchStores(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(helpers.GetExternalSecretExamplesPath(), "external-secret-example.yaml")
+	objects, err := helpers.YAMLObjects(path)
+	require.NoError(t, err)
+
+	prod, err := helpers.NewExternalSecretContext("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "SecretsManager", prod.Stores["aws-secrets-manager"].Service)
+	assert.Equal(t, "ParameterStore", prod.Stores["aws-parameter-store"].Service)
+	staging, err := helpers.NewExternalSecretContext("staging")
+	require.NoError(t, err)
+
+	secrets := 0
+	for _, object := range objects {
+		if object.Kind() != "ExternalSecret" {
+			continue
+		}
+		secrets++
+		assert.Empty(t, helpers.ValidateExternalSecret(object, prod), "%s:%d %s", path, object.Line, object.Name())
+		assert.NotEmpty(t, helpers.ValidateExternalSecret(object, staging), "%s:%d %s", path, object.Line, object.Name())
+	}
+	assert.NotZero(t, secrets, "%s deve ter exemplos de ExternalSecret", path)
+}
+
+// TestExternalSecretValidation valida a detecção de store inexistente, SecretStore
+// no lugar de ClusterSecretStore e chaves fora dos ARNs liberados
+// Valida: Requisitos 9.1, 9.2
+func TestExternalSecretValidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := &helpers.ExternalSecretContext{
+		Env: "staging",
+		Stores: map[string]*helpers.SecretStore{
+			"aws-secrets-manager": {Name: "aws-secrets-manager", Service: "SecretsManager", Region: "us-east-1"},
+			"aws-parameter-store": {Name: "aws-parameter-store", Service: "ParameterStore", Region: "us-east-1"},
+		},
+		Grants: []*helpers.IAMGrant{
+			{Action: "secretsmanager:GetSecretValue", Resource: "arn:aws:secretsmanager:us-east-1:*:secret:staging/*"},
+			{Action: "ssm:GetParameter", Resource: "arn:aws:ssm:us-east-1:*:parameter/staging/*"},
+		},
+	}
+	secret := func(store, kind string, keys ...string) *helpers.KubernetesObject {
+		ref := map[string]interface{}{"name": store}
+		if kind != "" {
+			ref["kind"] = kind
+		}
+		var data []interface{}
+		for _, key := range keys {
+			data = append(data, map[string]interface{}{
+				"secretKey": "value",
+				"remoteRef": map[string]interface{}{"key": key},
+			})
+		}
+		return &helpers.KubernetesObject{Object: map[string]interface{}{
+			"kind":     "ExternalSecret",
+			"metadata": map[string]interface{}{"name": "app"},
+			"spec":     map[string]interface{}{"secretStoreRef": ref, "data": data},
+		}}
+	}
+
+	assert.Empty(t, helpers.ValidateExternalSecret(secret("aws-secrets-manager", "ClusterSecretStore", "staging/db", "staging/db"), ctx))
+	assert.Empty(t, helpers.ValidateExternalSecret(secret("aws-parameter-store", "ClusterSecretStore", "/staging/app/key"), ctx))
+	assert.Empty(t, helpers.ValidateExternalSecret(secret("aws-secrets-manager", "ClusterSecretStore",
+		"arn:aws:secretsmanager:us-east-1:123456789012:secret:staging/db-AbCdEf"), ctx))
+
+	assert.Equal(t, []string{
+		"secretStoreRef ClusterSecretStore/vault; o módulo cria os ClusterSecretStores aws-parameter-store, aws-secrets-manager",
+	}, helpers.ValidateExternalSecret(secret("vault", "ClusterSecretStore", "staging/db"), ctx))
+	assert.Equal(t, []string{
+		"secretStoreRef SecretStore/aws-secrets-manager; o módulo cria os ClusterSecretStores aws-parameter-store, aws-secrets-manager",
+	}, helpers.ValidateExternalSecret(secret("aws-secrets-manager", "", "staging/db"), ctx))
+	assert.Equal(t, []string{
+		"chave prod/db (aws-secrets-manager): secretsmanager:GetSecretValue em " +
+			"arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf não é permitido pela policy do IRSA no staging",
+	}, helpers.ValidateExternalSecret(secret("aws-secrets-manager", "ClusterSecretStore", "prod/db"), ctx))
+	assert.Equal(t, []string{
+		"chave /prod/app/key (aws-parameter-store): ssm:GetParameter em " +
+			"arn:aws:ssm:us-east-1:123456789012:parameter/prod/app/key não é permitido pela policy do IRSA no staging",
+	}, helpers.ValidateExternalSecret(secret("aws-parameter-store", "ClusterSecretStore", "/prod/app/key"), ctx))
+}
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ============================================================================
+// Helm Values Tests
+// ============================================================================
+
+// renderHelmReleases avalia o módulo e renderiza os values de todos os helm_release
+func renderHelmReleases(t *testing.T, ev *helpers.Evaluator) []*helpers.HelmRelease {
+	t.Helper()
+
+	instances, err := ev.Expand()
+	require.NoError(t, err, "Módulo deve ser avaliado sem erros")
+
+	releases, err := helpers.HelmReleases(instances)
+	require.NoError(t, err, "Values dos helm_release devem ser renderizados sem erros")
+	return releases
+}
+
+// assertHelmValuesMatchSchema valida os values renderizados contra o schema vendorizado do chart
+func assertHelmValuesMatchSchema(t *testing.T, release *helpers.HelmRelease) {
+	t.Helper()
+
+	require.NotEmpty(t, release.Chart, "%s deve declarar o chart", release.Address)
+	require.NotEmpty(t, release.Version, "%s deve fixar a versão do chart", release.Address)
+
+	schema, err := helpers.LoadChartSchema(release.Chart, release.Version)
+	require.NoError(t, err, "%s: schema do chart deve estar vendorizado em test/testdata/charts", release.Address)
+
+	for _, violation := range schema.Validate(release.Values) {
+		assert.Fail(t, "values incompatíveis com o chart",
+			"%s (%s %s): %v", release.Address, release.Chart, release.Version, violation)
+	}
+}
+
+// TestHelmValuesMatchChartSchemas valida os values finais de cada helm_release
+// de cada ambiente contra o values.schema.json do chart
+// Valida: Requisitos 7.1, 7.5, 8.1, 10.1, 10.2, 10.3, 11.1
+func TestHelmValuesMatchChartSchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, env := range []string{"staging", "prod"} {
+		env := env
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			require.NoError(t, err, "Ambiente %s deve ser carregado", env)
+
+			releases := renderHelmReleases(t, ev)
+			assert.NotEmpty(t, releases, "Ambiente %s deve instalar helm_releases", env)
+
+			for _, release := range releases {
+				assertHelmValuesMatchSchema(t, release)
+			}
+		})
+	}
+}
+
+// TestHelmValuesAlternativeOptions valida os charts instalados apenas por opções
+// não usadas nos ambientes (Gatekeeper e NGINX Ingress)
+// Valida: Requisitos 8.1, 11.1
+func TestHelmValuesAlternativeOptions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		module string
+		inputs map[string]cty.Value
+		chart  string
+	}{
+		{
+			module: "platform/policy-engine",
+			inputs: map[string]cty.Value{"engine": cty.StringVal("gatekeeper")},
+			chart:  "gatekeeper",
+		},
+		{
+			module: "platform/ingress",
+			inputs: map[string]cty.Value{
+				"ingress_type":       cty.StringVal("nginx"),
+				"cluster_name":       cty.StringVal("eks-test"),
+				"oidc_provider_arn":  cty.StringVal("arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-1.amazonaws.com/id/TEST"),
+				"oidc_issuer_url":    cty.StringVal("https://oidc.eks.us-east-1.amazonaws.com/id/TEST"),
+				"vpc_id":             cty.StringVal("vpc-0123456789abcdef0"),
+				"aws_region":         cty.StringVal("us-east-1"),
+				"route53_zone_id":    cty.StringVal("Z1234567890ABC"),
+				"domain_name":        cty.StringVal("test.example.com"),
+				"cert_manager_email": cty.StringVal("admin@example.com"),
+			},
+			chart: "ingress-nginx",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.chart, func(t *testing.T) {
+			t.Parallel()
+
+			mod, err := helpers.LoadModule(helpers.GetModulePath(tc.module))
+			require.NoError(t, err)
+
+			ev, err := helpers.NewEvaluator(mod, tc.inputs)
+			require.NoError(t, err)
+
+			found := false
+			for _, release := range renderHelmReleases(t, ev) {
+				found = found || release.Chart == tc.chart
+				assertHelmValuesMatchSchema(t, release)
+			}
+			assert.True(t, found, "Módulo %s deve instalar o chart %s", tc.module, tc.chart)
+		})
+	}
+}
+
+// TestHelmValuesSchemaRejectsUnknownKeys garante que chaves com erro de digitação,
+// ignoradas silenciosamente pelo Helm, são reportadas pela validação
+// Valida: Requisitos 16.5
+func TestHelmValuesSchemaRejectsUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+
+	var argocd *helpers.HelmRelease
+	for _, release := range renderHelmReleases(t, ev) {
+		if release.Chart == "argo-cd" {
+			argocd = release
+		}
+	}
+	require.NotNil(t, argocd, "Staging deve instalar o ArgoCD")
+
+	schema, err := helpers.LoadChartSchema(argocd.Chart, argocd.Version)
+	require.NoError(t, err)
+
+	controller, ok := argocd.Values["controller"].(map[string]interface{})
+	require.True(t, ok, "Values do ArgoCD devem configurar o controller")
+	controller["nodeSelecter"] = controller["nodeSelector"]
+
+	violations := schema.Validate(argocd.Values)
+	require.Len(t, violations, 1, "Apenas a chave com erro de digitação deve ser reportada")
+	assert.Contains(t, violations[0].Error(), "controller.nodeSelecter")
+}
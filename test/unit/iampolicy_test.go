@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ============================================================================
+// IAM Policy Drift Tests
+// ============================================================================
+
+// TestALBControllerPolicyMatchesUpstream valida que a policy IAM do AWS Load
+// Balancer Controller concede exatamente as permissões da policy de referência da
+// versão fixada do chart
+// Valida: Requisitos 11.2
+func TestALBControllerPolicyMatchesUpstream(t *testing.T) {
+	t.Parallel()
+
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	instances, err := ev.Expand()
+	require.NoError(t, err)
+	comparisons, err := helpers.CompareChartIAMPolicies(instances)
+	require.NoError(t, err)
+	require.Len(t, comparisons, 1, "Staging usa ingress_type = \"alb\"")
+
+	comparison := comparisons[0]
+	assert.Equal(t, "aws-load-balancer-controller", comparison.Chart)
+	assert.Equal(t, "module.ingress.data.aws_iam_policy_document.alb_controller[0]", comparison.Document)
+	assert.Empty(t, comparison.Drift.Missing, "Permissões da referência ausentes no módulo")
+	assert.Empty(t, comparison.Drift.Added, "Permissões que a referência não concede")
+	assert.Empty(t, comparison.Drift.Loosened, "Permissões mais amplas que as da referência")
+}
+
+// TestIAMPolicyReferenceNotVendored valida que uma versão do chart sem policy de
+// referência vendorizada é reportada em vez de ignorada
+// Valida: Requisitos 11.2
+func TestIAMPolicyReferenceNotVendored(t *testing.T) {
+	t.Parallel()
+
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	require.NoError(t, ev.OverrideModuleArgument("ingress", "chart_version_alb", cty.StringVal("1.7.0")))
+	instances, err := ev.Expand()
+	require.NoError(t, err)
+	comparisons, err := helpers.CompareChartIAMPolicies(instances)
+	require.NoError(t, err)
+	require.Len(t, comparisons, 1)
+
+	comparison := comparisons[0]
+	assert.Equal(t, "module.ingress.data.aws_iam_policy_document.alb_controller[0]", comparison.Document)
+	assert.EqualError(t, comparison.ReferenceError, "policy de referência de aws-load-balancer-controller 1.7.0 não vendorizada")
+	assert.Contains(t, comparison.File, "alb_controller.tf")
+	assert.Positive(t, comparison.Line)
+}
+
+// TestIAMPolicyDriftDetection valida a classificação das diferenças entre a
+// policy do módulo e a de referência
+// Valida: Requisitos 11.2
+func TestIAMPolicyDriftDetection(t *testing.T) {
+	t.Parallel()
+
+	reference, err := helpers.LoadIAMPolicyJSON(helpers.GetChartIAMPolicyPath("aws-load-balancer-controller", "1.6.2"))
+	require.NoError(t, err)
+	require.NotEmpty(t, reference)
+
+	statement := func(actions, resources []interface{}, conditions ...interface{}) interface{} {
+		return map[string]interface{}{"effect": "Allow", "actions": actions, "resources": resources, "condition": conditions}
+	}
+	condition := func(test, variable string, values ...interface{}) interface{} {
+		return map[string]interface{}{"test": test, "variable": variable, "values": values}
+	}
+
+	tagged := condition("Null", "aws:ResourceTag/elbv2.k8s.aws/cluster", "false")
+	local, err := helpers.PolicyDocumentGrants(map[string]interface{}{"statement": []interface{}{
+		// Igual à referência
+		statement([]interface{}{"iam:CreateServiceLinkedRole"}, []interface{}{"*"},
+			condition("StringEquals", "iam:AWSServiceName", "elasticloadbalancing.amazonaws.com")),
+		// Igual à referência, com os valores da condição em outra ordem
+		statement([]interface{}{"elasticloadbalancing:AddTags"}, []interface{}{"arn:aws:elasticloadbalancing:*:*:targetgroup/*/*"},
+			condition("StringEquals", "elasticloadbalancing:CreateAction", "CreateLoadBalancer", "CreateTargetGroup"),
+			condition("Null", "aws:RequestTag/elbv2.k8s.aws/cluster", "false")),
+		// Sem a condição de tag do cluster
+		statement([]interface{}{"elasticloadbalancing:DeleteLoadBalancer"}, []interface{}{"*"}),
+		// Recurso mais amplo que o da referência
+		statement([]interface{}{"elasticloadbalancing:RegisterTargets"}, []interface{}{"*"}),
+		// Com condição extra: mais restrito, não é afrouxamento
+		statement([]interface{}{"elasticloadbalancing:DeleteTargetGroup"}, []interface{}{"*"},
+			tagged, condition("StringEquals", "aws:RequestedRegion", "us-east-1")),
+		// Ação que a referência não usa
+		statement([]interface{}{"ec2:TerminateInstances"}, []interface{}{"*"}),
+	}})
+	require.NoError(t, err)
+
+	drift := helpers.CompareIAMPolicy(local, reference)
+	require.Len(t, drift.Added, 1)
+	assert.Equal(t, "ec2:TerminateInstances em *", drift.Added[0].String())
+	loosened := make([]string, len(drift.Loosened))
+	for i, grant := range drift.Loosened {
+		loosened[i] = grant.String()
+	}
+	assert.ElementsMatch(t, []string{
+		"elasticloadbalancing:DeleteLoadBalancer em *",
+		"elasticloadbalancing:RegisterTargets em *",
+	}, loosened)
+	for _, grant := range drift.Missing {
+		assert.NotEqual(t, "iam:CreateServiceLinkedRole", grant.Action, "Permissão presente não é ausente")
+		if grant.Action == "elasticloadbalancing:AddTags" && grant.Resource == "arn:aws:elasticloadbalancing:*:*:targetgroup/*/*" {
+			for _, condition := range grant.Conditions {
+				assert.NotContains(t, condition, "CreateAction", "A ordem dos valores da condição não importa")
+			}
+		}
+		if grant.Action == "elasticloadbalancing:DeleteLoadBalancer" || grant.Action == "elasticloadbalancing:RegisterTargets" {
+			t.Errorf("%s: permissão mais ampla cobre a da referência", grant)
+		}
+	}
+	missing := make(map[string]bool)
+	for _, grant := range drift.Missing {
+		missing[grant.Action] = true
+	}
+	assert.True(t, missing["elasticloadbalancing:DeleteTargetGroup"], "Permissão com condição extra não cobre a da referência")
+	assert.True(t, missing["elasticloadbalancing:AddTags"])
+
+	_, err = helpers.PolicyDocumentGrants(map[string]interface{}{"statement": []interface{}{
+		map[string]interface{}{"effect": "Deny", "actions": []interface{}{"*"}},
+	}})
+	assert.Error(t, err, "Statements Deny não são comparados")
+}
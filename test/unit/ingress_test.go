@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Ingress Example Tests
+// ============================================================================
+
+// TestIngressExamplesMatchController valida os exemplos de Ingress contra o schema
+// networking.k8s.io/v1 e contra o que o módulo ingress cria no prod com o
+// controller do exemplo: IngressClass, ClusterIssuer e domínio
+// Valida: Requisitos 11.1, 11.3, 11.6
+func TestIngressExamplesMatchController(t *testing.T) {
+	t.Parallel()
+
+	schema, err := helpers.LoadSchema(helpers.GetKubernetesSchemaPath("networking.k8s.io/v1", "Ingress"))
+	require.NoError(t, err)
+
+	for _, ingressType := range []string{"alb", "nginx"} {
+		ctx, err := helpers.NewIngressContext("prod", ingressType)
+		require.NoError(t, err)
+		assert.Equal(t, ingressType, ctx.IngressClass)
+
+		path := filepath.Join(helpers.GetIngressExamplesPath(), "ingress-"+ingressType+"-example.yaml")
+		objects, err := helpers.YAMLObjects(path)
+		require.NoError(t, err)
+		ingresses := 0
+		for _, object := range objects {
+			if object.Kind() != "Ingress" {
+				continue
+			}
+			ingresses++
+			assert.Empty(t, helpers.ValidateIngress(object, schema, ctx), "%s:%d %s", path, object.Line, object.Name())
+		}
+		assert.NotZero(t, ingresses, "%s deve ter exemplos de Ingress", path)
+	}
+}
+
+// TestIngressValidation valida a detecção de campos fora do schema, anotações de
+// outro controller, ClusterIssuer inexistente e hosts fora do domínio
+// Valida: Requisitos 11.3, 11.6
+func TestIngressValidation(t *testing.T) {
+	t.Parallel()
+
+	schema, err := helpers.LoadSchema(helpers.GetKubernetesSchemaPath("networking.k8s.io/v1", "Ingress"))
+	require.NoError(t, err)
+	ctx := &helpers.IngressContext{
+		Env: "staging", IngressType: "nginx", IngressClass: "nginx",
+		IssuerName: "letsencrypt-staging", DomainName: "staging.example.com",
+	}
+	object := &helpers.KubernetesObject{Object: map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata": map[string]interface{}{
+			"name": "app",
+			"annotations": map[string]interface{}{
+				"kubernetes.io/ingress.class":            "nginx",
+				"alb.ingress.kubernetes.io/scheme":       "internet-facing",
+				"nginx.ingress.kubernetes.io/use-regex":  "true",
+				"cert-manager.io/cluster-issuer":         "letsencrypt-production",
+				"nginx.ingress.kubernetes.io/proxy-body": "1m",
+			},
+		},
+		"spec": map[string]interface{}{
+			"ingressClassName": "nginx",
+			"tls": []interface{}{
+				map[string]interface{}{"hosts": []interface{}{"app.staging.example.com", "app.example.com"}, "secretName": "app-tls"},
+			},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"host": "app.staging.example.com",
+					"http": map[string]interface{}{"paths": []interface{}{
+						map[string]interface{}{
+							"path": "/api(/|$)(.*)", "pathType": "Prefix",
+							"backend": map[string]interface{}{"service": map[string]interface{}{
+								"name": "app", "port": map[string]interface{}{"number": 80},
+							}},
+						},
+						map[string]interface{}{
+							"path": "/", "pathType": "Any",
+							"backend": map[string]interface{}{"serviceName": "app"},
+						},
+					}},
+				},
+			},
+		},
+	}}
+
+	problems := helpers.ValidateIngress(object, schema, ctx)
+	assert.ElementsMatch(t, []string{
+		"spec.rules[0].http.paths[1].backend.serviceName: propriedade não reconhecida pelo schema",
+		"spec.rules[0].http.paths[1].pathType: valor Any não está entre os permitidos [Exact Prefix ImplementationSpecific]",
+		"anotação alb.ingress.kubernetes.io/scheme exige ingressClassName alb",
+		`anotação cert-manager.io/cluster-issuer="letsencrypt-production"; o módulo cria o ClusterIssuer "letsencrypt-staging"`,
+		"anotação kubernetes.io/ingress.class é rejeitada pela API junto com spec.ingressClassName",
+		`spec.rules[0].http.paths[0]: path "/api(/|$)(.*)" é uma expressão regular e exige pathType ImplementationSpecific`,
+		"host app.example.com fora do domínio staging.example.com do staging",
+	}, problems)
+
+	// A mesma IngressClass do contexto é exigida
+	ctx.IngressType, ctx.IngressClass = "alb", "alb"
+	assert.Contains(t, helpers.ValidateIngress(object, schema, ctx),
+		`spec.ingressClassName "nginx"; o controller alb atende "alb"`)
+}
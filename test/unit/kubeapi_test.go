@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Kubernetes API Deprecation Tests
+// ============================================================================
+
+// maxSupportedKubernetesVersion é a maior versão aceita pela validação de cluster_version
+const maxSupportedKubernetesVersion = helpers.MaxSupportedKubernetesVersion
+
+// TestRepositoryManifestsAreCollected valida que os manifestos dos módulos e dos
+// exemplos são encontrados com apiVersion e kind
+// Valida: Requisitos 8.2, 9.2, 9.5, 11.3, 11.6
+func TestRepositoryManifestsAreCollected(t *testing.T) {
+	t.Parallel()
+
+	manifests, err := helpers.RepositoryManifests()
+	require.NoError(t, err)
+
+	kinds := make(map[string]bool)
+	for _, manifest := range manifests {
+		assert.NotEmpty(t, manifest.APIVersion, "%s:%d deve declarar apiVersion literal", manifest.File, manifest.Line)
+		assert.NotEmpty(t, manifest.Kind, "%s:%d deve declarar kind literal", manifest.File, manifest.Line)
+		kinds[manifest.Kind] = true
+	}
+
+	for _, kind := range []string{"ClusterPolicy", "ConstraintTemplate", "ClusterIssuer", "ClusterSecretStore", "ExternalSecret", "Ingress"} {
+		assert.True(t, kinds[kind], "Scanner deve encontrar manifestos do tipo %s", kind)
+	}
+}
+
+// TestManifestsSurviveKubernetesUpgrade valida, para cada ambiente, que nenhum
+// manifesto usa APIs removidas entre a versão atual e a maior versão suportada
+// Valida: Requisitos 5.5
+func TestManifestsSurviveKubernetesUpgrade(t *testing.T) {
+	t.Parallel()
+
+	manifests, err := helpers.RepositoryManifests()
+	require.NoError(t, err)
+
+	for _, env := range []string{"staging", "prod"} {
+		env := env
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			tfvars, err := helpers.ReadTFVars(filepath.Join(helpers.GetEnvironmentPath(env), "terraform.tfvars.example"))
+			require.NoError(t, err)
+			current := tfvars["cluster_version"].AsString()
+
+			findings, err := helpers.FindAPIRemovals(manifests, current, maxSupportedKubernetesVersion)
+			require.NoError(t, err)
+
+			for _, finding := range findings {
+				assert.Fail(t, "manifesto usa API removida",
+					"%s: upgrade de %s para %s exige migração: %s", env, current, maxSupportedKubernetesVersion, finding)
+			}
+
+			// APIs de CRDs devem ser servidas pelas versões instaladas dos charts
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			require.NoError(t, err)
+			instances, err := ev.Expand()
+			require.NoError(t, err)
+			releases, err := helpers.HelmReleases(instances)
+			require.NoError(t, err)
+			for _, release := range releases {
+				for _, finding := range helpers.FindCRDRemovals(manifests, release.Chart, release.Version, release.Version) {
+					assert.Fail(t, "manifesto usa API de CRD removida", "%s: %s: %s", env, release.Address, finding)
+				}
+			}
+		})
+	}
+}
+
+// TestAPIRemovalScannerDetectsRemovedAPIs valida a comparação das APIs dos
+// manifestos com a tabela de remoções por versão
+// Valida: Requisitos 16.5
+func TestAPIRemovalScannerDetectsRemovedAPIs(t *testing.T) {
+	t.Parallel()
+
+	psp := &helpers.KubernetesManifest{APIVersion: "policy/v1beta1", Kind: "PodSecurityPolicy", Name: "restricted"}
+	flowSchema := &helpers.KubernetesManifest{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema", Name: "platform"}
+	ingress := &helpers.KubernetesManifest{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "app"}
+	manifests := []*helpers.KubernetesManifest{psp, flowSchema, ingress}
+
+	findings, err := helpers.FindAPIRemovals(manifests, "1.28", "1.30")
+	require.NoError(t, err)
+	require.Len(t, findings, 2, "PodSecurityPolicy e FlowSchema v1beta2 devem ser reportados")
+
+	assert.Equal(t, psp, findings[0].Manifest)
+	assert.True(t, findings[0].AlreadyRemoved, "PodSecurityPolicy já foi removida antes da 1.28")
+
+	assert.Equal(t, flowSchema, findings[1].Manifest)
+	assert.False(t, findings[1].AlreadyRemoved, "FlowSchema v1beta2 é removido no upgrade")
+	assert.Equal(t, "1.29", findings[1].Removal.RemovedIn)
+	assert.Equal(t, "flowcontrol.apiserver.k8s.io/v1", findings[1].Removal.Replacement)
+
+	findings, err = helpers.FindAPIRemovals(manifests, "1.28", "1.28")
+	require.NoError(t, err)
+	assert.Len(t, findings, 1, "Sem upgrade, apenas APIs já removidas devem ser reportadas")
+
+	_, err = helpers.FindAPIRemovals(manifests, "1.28", "2.0")
+	assert.Error(t, err, "Versões fora do formato 1.x devem ser rejeitadas")
+}
+
+// TestCRDRemovalScannerDetectsRemovedAPIs valida a comparação das APIs de CRDs
+// dos manifestos com a versão instalada e a versão alvo do chart que os serve
+// Valida: Requisitos 5.5
+func TestCRDRemovalScannerDetectsRemovedAPIs(t *testing.T) {
+	t.Parallel()
+
+	legacyIssuer := &helpers.KubernetesManifest{APIVersion: "cert-manager.io/v1alpha2", Kind: "ClusterIssuer", Name: "letsencrypt"}
+	issuer := &helpers.KubernetesManifest{APIVersion: "cert-manager.io/v1", Kind: "ClusterIssuer", Name: "letsencrypt-prod"}
+	legacySecret := &helpers.KubernetesManifest{APIVersion: "external-secrets.io/v1alpha1", Kind: "ExternalSecret", Name: "app"}
+	manifests := []*helpers.KubernetesManifest{legacyIssuer, issuer, legacySecret}
+
+	findings := helpers.FindCRDRemovals(manifests, "cert-manager", "1.6.3", "1.7.0")
+	require.Len(t, findings, 1, "Apenas o ClusterIssuer v1alpha2 deixa de ser servido no cert-manager 1.7")
+	assert.Equal(t, legacyIssuer, findings[0].Manifest)
+	assert.False(t, findings[0].AlreadyRemoved)
+	assert.Equal(t, "cert-manager 1.7", findings[0].Removal.RemovedIn)
+	assert.Equal(t, "cert-manager.io/v1", findings[0].Removal.Replacement)
+
+	findings = helpers.FindCRDRemovals(manifests, "cert-manager", "v1.13.3", "v1.13.3")
+	require.Len(t, findings, 1)
+	assert.True(t, findings[0].AlreadyRemoved, "cert-manager 1.13 já não serve v1alpha2")
+
+	assert.Empty(t, helpers.FindCRDRemovals(manifests, "cert-manager", "1.6.0", "1.6.9"), "Sem cruzar a 1.7, nada é removido")
+	assert.Empty(t, helpers.FindCRDRemovals(manifests, "velero", "5.2.0", "7.0.0"), "Remoções de outro chart não se aplicam")
+
+	findings = helpers.FindCRDRemovals(manifests, "external-secrets", "0.9.11", "0.17.0")
+	require.Len(t, findings, 1)
+	assert.Equal(t, legacySecret, findings[0].Manifest)
+
+	for _, removal := range helpers.CRDRemovalTable() {
+		assert.NotEmpty(t, removal.Kinds, "%s %s deve listar os kinds", removal.Chart, removal.GroupVersion)
+		assert.NotEqual(t, removal.GroupVersion, removal.Replacement)
+	}
+}
@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Repository Layout Tests
+// ============================================================================
+
+// TestProjectRootDiscovery valida que a raiz é encontrada pelo marcador a partir
+// de qualquer subdiretório e que o layout do repositório aponta para os diretórios reais
+// Valida: Requisitos 3.1, 3.2, 3.3
+func TestProjectRootDiscovery(t *testing.T) {
+	t.Parallel()
+
+	root := helpers.GetProjectRoot()
+	assert.FileExists(t, filepath.Join(root, helpers.LayoutFile), "Raiz do repositório deve conter o marcador de layout")
+
+	layout, err := helpers.LoadLayout(root)
+	require.NoError(t, err)
+	assert.Equal(t, helpers.DefaultLayout(), layout, "Layout do repositório deve corresponder ao padrão dos helpers")
+
+	assert.DirExists(t, helpers.GetModulePath("clusters"))
+	assert.DirExists(t, helpers.GetModulePath("platform"))
+	assert.DirExists(t, helpers.GetEnvironmentPath("staging"))
+	assert.Equal(t, helpers.GetEnvironmentPath("staging"), helpers.GetEnvironmentPath("aws/staging"),
+		"Ambiente sem prefixo deve usar a nuvem padrão")
+	assert.FileExists(t, helpers.GetSpecPath("requirements.md"))
+
+	for _, dir := range []string{root, helpers.GetModulePath("platform"), helpers.GetTestPath("unit")} {
+		found, err := helpers.FindProjectRoot(dir)
+		require.NoError(t, err)
+		assert.Equal(t, root, found, "Raiz deve ser encontrada a partir de %s", dir)
+	}
+
+	envs, err := helpers.ListAllEnvironments()
+	require.NoError(t, err)
+	assert.Contains(t, envs, "staging")
+	assert.Contains(t, envs, "prod")
+
+	_, err = helpers.FindProjectRoot(t.TempDir())
+	assert.Error(t, err, "Diretório fora do repositório não deve ser reconhecido como raiz")
+}
+
+// TestCustomLayout valida um repositório consumidor com outra estrutura de
+// diretórios e ambientes em mais de uma nuvem, selecionado por TEMPLATE_ROOT
+// Valida: Requisitos 3.3, 3.4
+func TestCustomLayout(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join("infra", "modules", "clusters"),
+		filepath.Join("envs", "aws", "staging"),
+		filepath.Join("envs", "aws", "prod"),
+		filepath.Join("envs", "gcp", "staging"),
+		filepath.Join("vendor", "eks-template", "test", "unit"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+	}
+	layoutFile := "modules: infra/modules\nenvironments: envs\ntests: vendor/eks-template/test\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, helpers.LayoutFile), []byte(layoutFile), 0o644))
+
+	found, err := helpers.FindProjectRoot(filepath.Join(root, "vendor", "eks-template", "test", "unit"))
+	require.NoError(t, err)
+	assert.Equal(t, root, found, "Marcador deve identificar a raiz do repositório consumidor")
+
+	t.Setenv(helpers.ProjectRootEnv, root)
+	assert.Equal(t, root, helpers.GetProjectRoot())
+
+	layout := helpers.GetLayout()
+	assert.Equal(t, "aws", layout.DefaultCloud, "Campos omitidos devem usar o padrão")
+	assert.Equal(t, helpers.DefaultLayout().Spec, layout.Spec, "Campos omitidos devem usar o padrão")
+
+	assert.Equal(t, filepath.Join(root, "infra", "modules", "clusters"), helpers.GetModulePath("clusters"))
+	assert.Equal(t, filepath.Join(root, "envs", "aws", "staging"), helpers.GetEnvironmentPath("staging"))
+	assert.Equal(t, filepath.Join(root, "envs", "gcp", "staging"), helpers.GetEnvironmentPath("gcp/staging"))
+	assert.Equal(t, filepath.Join(root, "vendor", "eks-template", "test", "testdata"), helpers.GetTestPath("testdata"))
+
+	clouds, err := helpers.ListClouds()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws", "gcp"}, clouds)
+
+	envs, err := helpers.ListAllEnvironments()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "staging", "gcp/staging"}, envs,
+		"Ambientes de outras nuvens devem ser prefixados com a nuvem")
+}
@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ============================================================================
+// Observability Sizing Tests
+// ============================================================================
+
+// TestObservabilitySizingModel valida o modelo de dimensionamento e que os
+// volumes do Prometheus e do Loki de cada ambiente comportam a retenção
+// Valida: Requisitos 10.6
+func TestObservabilitySizingModel(t *testing.T) {
+	t.Parallel()
+
+	week := 7 * 24 * time.Hour
+	assert.InDelta(t, 100000.0/30*1.5*week.Seconds(), helpers.PrometheusDataBytes(100000, 30, 1.5, week), 1,
+		"Amostras por segundo × bytes por amostra × retenção")
+	assert.Zero(t, helpers.PrometheusDataBytes(100000, 0, 1.5, week), "Intervalo de scrape zero não gera dado")
+	assert.InDelta(t, 6e9, helpers.LokiDataBytes(10, 5, 72*time.Hour), 1, "10 GB/dia por 3 dias com compressão 5x")
+
+	config, err := helpers.LoadObservabilityConfig(helpers.GetObservabilityConfigPath())
+	require.NoError(t, err)
+	assert.InDelta(t, 10*config.Overhead/config.MaxUsage, config.RequiredBytes(10), 1e-9)
+
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	results, err := helpers.RunObservabilityChecks(report, helpers.GetObservabilityConfigPath(), "staging", "prod")
+	require.NoError(t, err)
+	for _, finding := range report.Findings {
+		assert.NotEqual(t, helpers.SeverityError, finding.Severity, "%s", finding)
+	}
+	require.Len(t, results, 2)
+	for _, result := range results {
+		for _, sizing := range []*helpers.StorageSizing{result.Prometheus, result.Loki} {
+			assert.True(t, sizing.Fits(), "%s: %s precisa de %.0f bytes em %s", result.Environment, sizing.Component, sizing.RequiredBytes, sizing.Volume)
+			assert.Greater(t, sizing.DataBytes, 0.0)
+		}
+	}
+
+	// Com 2Gi, o Prometheus de staging não comporta 7 dias
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	require.NoError(t, ev.OverrideModuleArgument("observability", "prometheus_storage_size", cty.StringVal("2Gi")))
+	small, err := helpers.EstimateObservability(ev, "staging", config)
+	require.NoError(t, err)
+	assert.Equal(t, "2Gi", small.Prometheus.Volume)
+	assert.False(t, small.Prometheus.Fits(), "Volume menor que o necessário deve ser detectado")
+	assert.Contains(t, small.Markdown(), "2Gi ❌")
+}
+
+// TestObservabilityRetentionRendered valida que a retenção nos values
+// renderizados dos charts é comparada com as variáveis do módulo
+// Valida: Requisitos 10.4, 10.5
+func TestObservabilityRetentionRendered(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadObservabilityConfig(helpers.GetObservabilityConfigPath())
+	require.NoError(t, err)
+	for env, days := range map[string][2]int{"staging": {7, 3}, "prod": {30, 15}} {
+		result, err := helpers.EstimateEnvironmentObservability(env, config)
+		require.NoError(t, err)
+		assert.Equal(t, days[0], result.Prometheus.RetentionDays, "%s: prometheus_retention_days", env)
+		assert.Equal(t, days[1], result.Loki.RetentionDays, "%s: loki_retention_days", env)
+		assert.Empty(t, result.Prometheus.RetentionProblems, "%s: retenção renderizada do Prometheus", env)
+		assert.Empty(t, result.Loki.RetentionProblems, "%s: retenção renderizada do Loki", env)
+	}
+
+	// Cópia do módulo com a retenção fixa no chart e o compactor sem retenção
+	root := t.TempDir()
+	source := helpers.GetModulePath("platform/observability")
+	dir := filepath.Join(root, "observability")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	replacements := map[string]string{
+		`retention = "${var.prometheus_retention_days}d"`: `retention = "15d"`,
+		`retention_enabled = true`:                        `retention_enabled = false`,
+	}
+	for _, name := range []string{"main.tf", "variables.tf", "outputs.tf"} {
+		content, err := os.ReadFile(filepath.Join(source, name))
+		require.NoError(t, err)
+		text := string(content)
+		for old, replacement := range replacements {
+			if strings.Contains(text, old) {
+				text = strings.Replace(text, old, replacement, 1)
+				delete(replacements, old)
+			}
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644))
+	}
+	require.Empty(t, replacements, "Trechos substituídos devem existir no módulo")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.tf"), []byte(`module "observability" {
+  source = "./observability"
+
+  environment               = "staging"
+  prometheus_retention_days = 7
+  loki_retention_days       = 3
+  grafana_admin_password    = "test"
+}
+`), 0o644))
+
+	mod, err := helpers.LoadModule(root)
+	require.NoError(t, err)
+	ev, err := helpers.NewEvaluator(mod, nil)
+	require.NoError(t, err)
+	result, err := helpers.EstimateObservability(ev, "staging", config)
+	require.NoError(t, err)
+	require.Len(t, result.Prometheus.RetentionProblems, 1)
+	assert.Contains(t, result.Prometheus.RetentionProblems[0], `"15d" difere de prometheus_retention_days = 7 dias`)
+	require.Len(t, result.Loki.RetentionProblems, 1)
+	assert.Contains(t, result.Loki.RetentionProblems[0], "retention_enabled")
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Property Linkage Tests
+// ============================================================================
+
+// TestPropertiesLinkedToDesign valida que cada propriedade do design.md possui
+// exatamente um teste de propriedade e reporta as propriedades vazias
+// Valida: Requisitos 16.5
+func TestPropertiesLinkedToDesign(t *testing.T) {
+	t.Parallel()
+
+	properties, err := helpers.ParseDesignProperties(helpers.GetDesignPath())
+	require.NoError(t, err)
+	require.NotEmpty(t, properties, "design.md deve declarar propriedades de corretude")
+
+	tests, err := helpers.PropertyTests(helpers.GetTestPath("property"))
+	require.NoError(t, err)
+
+	link := helpers.LinkProperties(properties, tests)
+	report := helpers.NewReport(helpers.GetProjectRoot())
+	report.AddPropertyLinkage("property-linkage", link)
+
+	for _, finding := range report.Findings {
+		if finding.Severity == helpers.SeverityError {
+			assert.Fail(t, "propriedade sem ligação com o design", finding.String())
+			continue
+		}
+		// Propriedades vazias são reportadas sem falhar até os testes usarem os geradores
+		t.Logf("%s", finding)
+	}
+}
+
+// TestPropertyLinkageDetectsVacuousProperties valida a detecção de argumentos gerados
+// que a função verificada não usa
+// Valida: Requisitos 16.5
+func TestPropertyLinkageDetectsVacuousProperties(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := `package property
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// Feature: spec, Property 1: Usa argumentos
+func TestPropertyUsesArgs(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("usa n", prop.ForAll(func(n int) bool { return n >= 0 }, gen.IntRange(0, 3)))
+	properties.TestingRun(t)
+}
+
+func TestPropertyIgnoresArgs(t *testing.T) {
+	// Feature: spec, Property 2: Ignora argumentos
+	properties := gopter.NewProperties(nil)
+	properties.Property("ignora b", prop.ForAll(func(n int, b bool) bool { return n >= 0 }, gen.IntRange(0, 3), gen.Bool()))
+	properties.Property("sem argumentos", prop.ForAll(func() bool { return true }))
+	properties.TestingRun(t)
+}
+
+// Feature: spec, Property 2: Ignora argumentos
+func TestPropertyDuplicated(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("duplicada", prop.ForAll(func(n int) bool { return n >= 0 }, gen.IntRange(0, 3)))
+	properties.TestingRun(t)
+}
+
+// Feature: spec, Property 9: Inexistente
+func TestPropertyUnknown(t *testing.T) {}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample_test.go"), []byte(source), 0o644))
+
+	tests, err := helpers.PropertyTests(dir)
+	require.NoError(t, err)
+	require.Len(t, tests, 4)
+
+	ignores := tests[1]
+	assert.Equal(t, "TestPropertyIgnoresArgs", ignores.Name)
+	assert.Equal(t, 2, ignores.Property, "Tag no corpo da função deve ser reconhecido")
+	require.Len(t, ignores.Checks, 2)
+	assert.Equal(t, "ignora b", ignores.Checks[0].Description)
+	assert.Equal(t, []string{"b"}, ignores.Checks[0].Unused)
+	assert.Empty(t, ignores.Checks[1].Arguments)
+
+	link := helpers.LinkProperties([]*helpers.SpecProperty{
+		{Number: 1, Title: "Usa argumentos"},
+		{Number: 2, Title: "Ignora argumentos"},
+		{Number: 3, Title: "Sem teste"},
+	}, tests)
+
+	require.Len(t, link.Missing(), 1)
+	assert.Equal(t, 3, link.Missing()[0].Number)
+	require.Len(t, link.Duplicated(), 1)
+	assert.Equal(t, 2, link.Duplicated()[0].Number)
+	require.Len(t, link.Unknown, 1)
+	assert.Equal(t, "TestPropertyUnknown", link.Unknown[0].Name)
+
+	vacuous := link.Vacuous()
+	require.Len(t, vacuous, 1, "Apenas o teste que ignora argumentos deve ser vazio")
+	assert.Equal(t, "TestPropertyIgnoresArgs", vacuous[0].Name)
+}
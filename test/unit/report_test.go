@@ -0,0 +1,194 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Report Output Tests
+// ============================================================================
+
+// sampleReport monta um relatório com uma verificação aprovada e duas com achados
+func sampleReport(t *testing.T) *helpers.Report {
+	t.Helper()
+
+	root := helpers.GetProjectRoot()
+	report := helpers.NewReport(root)
+	report.AddCheck(&helpers.Check{ID: "vpc-flow-logs", Requirement: "4.1", Description: "VPC deve habilitar flow logs"})
+	report.AddCheck(&helpers.Check{ID: "kyverno-policies", Requirement: "8.2", Description: "Políticas Kyverno obrigatórias"})
+	report.AddCheck(&helpers.Check{ID: "k8s-api-removals", Requirement: "5.5", Description: "Manifestos sem APIs removidas"})
+
+	report.Add(&helpers.Finding{
+		CheckID:  "vpc-flow-logs",
+		Severity: helpers.SeverityError,
+		File:     filepath.Join(root, "modules", "clusters", "vpc.tf"),
+		Line:     42,
+		Message:  "aws_flow_log ausente para a VPC",
+	})
+
+	policies := filepath.Join(root, "modules", "platform", "policy-engine", "policies_kyverno.tf")
+	report.AddDiagnostics("kyverno-policies", hcl.Diagnostics{{
+		Severity: hcl.DiagWarning,
+		Summary:  "validationFailureAction em Audit",
+		Subject:  &hcl.Range{Filename: policies, Start: hcl.Pos{Line: 7}},
+	}})
+	return report
+}
+
+// TestReportJSON valida o modelo de resultado gravado em JSON com caminhos relativos
+// Valida: Requisitos 16.4
+func TestReportJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport(t).WriteJSON(&buf))
+
+	var decoded struct {
+		Checks   []helpers.Check   `json:"checks"`
+		Findings []helpers.Finding `json:"findings"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	assert.Len(t, decoded.Checks, 3, "Verificações sem achados também devem constar do relatório")
+	require.Len(t, decoded.Findings, 2)
+
+	assert.Equal(t, helpers.Finding{
+		CheckID:     "kyverno-policies",
+		Requirement: "8.2",
+		Severity:    helpers.SeverityWarning,
+		File:        "modules/platform/policy-engine/policies_kyverno.tf",
+		Line:        7,
+		Message:     "validationFailureAction em Audit",
+	}, decoded.Findings[0], "Achados devem herdar o requisito da verificação e usar caminho relativo")
+	assert.Equal(t, "modules/clusters/vpc.tf", decoded.Findings[1].File)
+}
+
+// TestReportJUnit valida que cada achado vira um caso com falha e cada verificação
+// sem achados um caso aprovado
+// Valida: Requisitos 16.4
+func TestReportJUnit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport(t).WriteJUnit(&buf))
+
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				File    string `xml:"file,attr"`
+				Line    int    `xml:"line,attr"`
+				Failure *struct {
+					Type string `xml:"type,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites), "JUnit XML deve ser válido")
+
+	assert.Equal(t, 3, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	require.Len(t, suites.Suites, 3)
+
+	assert.Equal(t, "k8s-api-removals", suites.Suites[0].Name)
+	assert.Nil(t, suites.Suites[0].Cases[0].Failure, "Verificação sem achados deve aparecer como aprovada")
+
+	vpc := suites.Suites[2]
+	assert.Equal(t, "vpc-flow-logs", vpc.Name)
+	require.NotNil(t, vpc.Cases[0].Failure)
+	assert.Equal(t, "error", vpc.Cases[0].Failure.Type)
+	assert.Equal(t, "modules/clusters/vpc.tf", vpc.Cases[0].File)
+	assert.Equal(t, 42, vpc.Cases[0].Line)
+}
+
+// TestReportSARIF valida a localização dos achados no formato aceito pelo GitHub
+// code scanning para anotar PRs
+// Valida: Requisitos 16.4
+func TestReportSARIF(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport(t).WriteSARIF(&buf, "templatecheck"))
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID         string            `json:"id"`
+						Properties map[string]string `json:"properties"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "templatecheck", run.Tool.Driver.Name)
+	require.Len(t, run.Tool.Driver.Rules, 3)
+	require.Len(t, run.Results, 2)
+
+	for _, result := range run.Results {
+		rule := run.Tool.Driver.Rules[result.RuleIndex]
+		assert.Equal(t, result.RuleID, rule.ID, "ruleIndex deve apontar para a regra do achado")
+		assert.NotEmpty(t, rule.Properties["requirement"], "Regra %s deve citar o requisito", rule.ID)
+		require.Len(t, result.Locations, 1)
+	}
+
+	vpc := run.Results[1]
+	assert.Equal(t, "error", vpc.Level)
+	assert.Equal(t, "modules/clusters/vpc.tf", vpc.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 42, vpc.Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+// TestReportFailsOnSeverity valida o limite de severidade que reprova a execução
+// Valida: Requisitos 16.4
+func TestReportFailsOnSeverity(t *testing.T) {
+	t.Parallel()
+
+	report := helpers.NewReport("")
+	report.Add(&helpers.Finding{CheckID: "sg-open-ingress", Severity: helpers.SeverityWarning, Message: "0.0.0.0/0"})
+
+	assert.True(t, report.Failed(helpers.SeverityNote))
+	assert.True(t, report.Failed(helpers.SeverityWarning))
+	assert.False(t, report.Failed(helpers.SeverityError), "Warning não deve reprovar com limite error")
+
+	severity, err := helpers.ParseSeverity("info")
+	require.NoError(t, err)
+	assert.Equal(t, helpers.SeverityNote, severity)
+
+	_, err = helpers.ParseSeverity("critical")
+	assert.Error(t, err)
+}
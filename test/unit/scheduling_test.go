@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Scheduling Tests
+// ============================================================================
+
+// isSystemNodeGroup indica se o node group é destinado aos add-ons de plataforma
+func isSystemNodeGroup(group *helpers.NodeGroup) bool {
+	return group.Labels["role"] == "system"
+}
+
+// TestPlatformAddonsScheduleOnSystemNodeGroup valida, para cada ambiente, que os
+// workloads de plataforma toleram os taints e selecionam os labels do node group
+// system, sem poder ser agendados no node group apps
+// Valida: Requisitos 6.1, 6.2, 6.5, 7.3
+func TestPlatformAddonsScheduleOnSystemNodeGroup(t *testing.T) {
+	t.Parallel()
+
+	for _, env := range []string{"staging", "prod"} {
+		env := env
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			require.NoError(t, err, "Ambiente %s deve ser carregado", env)
+
+			instances, err := ev.Expand()
+			require.NoError(t, err)
+
+			groups := helpers.NodeGroupsFromInstances(instances)
+			require.NotEmpty(t, groups, "Ambiente %s deve declarar node groups", env)
+
+			hasSystem := false
+			for _, group := range groups {
+				hasSystem = hasSystem || isSystemNodeGroup(group)
+			}
+			require.True(t, hasSystem, "Ambiente %s deve ter um node group com label role=system", env)
+
+			releases, err := helpers.HelmReleases(instances)
+			require.NoError(t, err)
+
+			for _, release := range releases {
+				placements, err := helpers.HelmPlacements(release)
+				require.NoError(t, err)
+
+				for _, placement := range placements {
+					eligible := helpers.EligibleNodeGroups(placement, groups)
+					assert.NotEmpty(t, eligible,
+						"%s não pode ser agendado em nenhum node group (nodeSelector=%v, tolerations=%v)",
+						placement.Name(), placement.NodeSelector, placement.Tolerations)
+
+					// DaemonSets rodam em todos os nodes por definição
+					if placement.DaemonSet {
+						continue
+					}
+					for _, group := range eligible {
+						assert.True(t, isSystemNodeGroup(group),
+							"%s pode ser agendado no node group %s; add-ons devem rodar apenas no node group system",
+							placement.Name(), group.Name)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestSchedulingRequiresTolerationForTaint garante que um workload sem toleration
+// para o taint CriticalAddonsOnly não é considerado agendável no node group system
+// Valida: Requisitos 6.1
+func TestSchedulingRequiresTolerationForTaint(t *testing.T) {
+	t.Parallel()
+
+	system := &helpers.NodeGroup{
+		Name:   "system",
+		Labels: map[string]string{"role": "system"},
+		Taints: []helpers.Taint{{Key: "CriticalAddonsOnly", Value: "true", Effect: "NoSchedule"}},
+	}
+	apps := &helpers.NodeGroup{
+		Name:   "apps",
+		Labels: map[string]string{"role": "apps"},
+	}
+	groups := []*helpers.NodeGroup{system, apps}
+
+	withoutToleration := &helpers.PodPlacement{
+		Release:      "helm_release.test",
+		NodeSelector: map[string]string{"role": "system"},
+	}
+	assert.Empty(t, helpers.EligibleNodeGroups(withoutToleration, groups),
+		"Workload sem toleration não deve ser agendável no node group system")
+
+	withoutSelector := &helpers.PodPlacement{
+		Release:     "helm_release.test",
+		Tolerations: []helpers.Toleration{{Key: "CriticalAddonsOnly", Operator: "Equal", Value: "true", Effect: "NoSchedule"}},
+	}
+	assert.ElementsMatch(t, groups, helpers.EligibleNodeGroups(withoutSelector, groups),
+		"Workload sem nodeSelector pode ser agendado também no node group apps")
+
+	// O EKS usa NO_SCHEDULE na API; a comparação deve aceitar os dois formatos
+	eksTaint := helpers.Taint{Key: "CriticalAddonsOnly", Value: "true", Effect: "NO_SCHEDULE"}
+	assert.True(t, withoutSelector.Tolerations[0].Tolerates(eksTaint),
+		"Toleration NoSchedule deve cobrir taint NO_SCHEDULE")
+}
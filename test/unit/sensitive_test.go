@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Sensitive Value Tests
+// ============================================================================
+
+// TestSensitiveValuesDoNotLeak valida que nenhum valor sensitive dos módulos e
+// ambientes chega a outputs, tags, values ou exemplos de tfvars em texto plano e
+// que a senha do Grafana é passada ao chart por set_sensitive
+// Valida: Requisitos 7.4, 10.1
+func TestSensitiveValuesDoNotLeak(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadSensitiveConfig(helpers.GetSensitiveConfigPath())
+	require.NoError(t, err)
+	assert.True(t, config.ShouldBeSensitive("grafana_admin_password"))
+	assert.False(t, config.ShouldBeSensitive("initial_admin_password_secret"), "Output do ArgoCD contém só o nome do Secret")
+	assert.False(t, config.ShouldBeSensitive("kms_key_arn"), "ARN de chave KMS é identificador")
+	assert.True(t, config.IsPlaceholder("CHANGE_ME_STRONG_PASSWORD"))
+	assert.False(t, config.IsPlaceholder("hunter2"))
+
+	dirs, err := helpers.ModuleDirs()
+	require.NoError(t, err)
+	dirs = append(dirs, helpers.GetEnvironmentPath("staging"), helpers.GetEnvironmentPath("prod"))
+	for _, dir := range dirs {
+		leaks, err := helpers.FindSensitiveLeaks(dir, config)
+		require.NoError(t, err, dir)
+		assert.Empty(t, leaks, dir)
+	}
+
+	ev, err := helpers.NewEnvironmentEvaluator("staging")
+	require.NoError(t, err)
+	child, err := ev.Child("observability")
+	require.NoError(t, err)
+	instances, err := child.Instances("helm_release.kube_prometheus_stack")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	values := instances[0].Values()
+	for _, doc := range values["values"].([]interface{}) {
+		assert.NotContains(t, doc, "adminPassword", "Senha não deve estar nos values em texto plano")
+	}
+	release, err := helpers.RenderHelmRelease(instances[0])
+	require.NoError(t, err)
+	grafana, _ := release.Values["grafana"].(map[string]interface{})
+	assert.NotEmpty(t, grafana["adminPassword"], "Senha deve chegar ao chart por set_sensitive")
+}
+
+// TestSensitiveLeakDetection valida que valores sensíveis são seguidos por
+// locals, argumentos e outputs de módulos e que cada destino proibido é reportado
+// Valida: Requisitos 7.4, 10.1
+func TestSensitiveLeakDetection(t *testing.T) {
+	t.Parallel()
+
+	config, err := helpers.LoadSensitiveConfig(helpers.GetSensitiveConfigPath())
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "app"), 0o755))
+	files := map[string]string{
+		"main.tf": `
+variable "db_password" {
+  description = "Senha do banco"
+  type        = string
+  sensitive   = true
+}
+
+variable "api_token" {
+  description = "Token sem sensitive"
+  type        = string
+}
+
+locals {
+  password = var.db_password
+}
+
+module "app" {
+  source = "./app"
+
+  password = local.password
+  tags     = { Secret = var.db_password }
+}
+
+resource "random_password" "admin" {
+  length = 16
+}
+
+output "app_password" {
+  description = "Output sensitive do módulo repassado sem sensitive"
+  value       = module.app.password
+}
+
+output "admin" {
+  description = "Senha gerada"
+  value       = random_password.admin.result
+  sensitive   = true
+}
+`,
+		"terraform.tfvars.example": `
+db_password = "hunter2"
+api_token   = "<token>"
+`,
+		"app/main.tf": `
+variable "password" {
+  description = "Recebe a senha sem sensitive"
+  type        = string
+}
+
+variable "tags" {
+  description = "Tags"
+  type        = map(string)
+}
+
+resource "helm_release" "app" {
+  name  = "app"
+  chart = "app"
+
+  values = [yamlencode({ password = var.password })]
+
+  set {
+    name  = "auth.password"
+    value = var.password
+  }
+
+  set_sensitive {
+    name  = "auth.other"
+    value = var.password
+  }
+}
+
+resource "aws_s3_bucket" "app" {
+  bucket = "app"
+  tags   = var.tags
+}
+
+output "password" {
+  description = "Senha exposta"
+  value       = var.password
+}
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o644))
+	}
+
+	leaks, err := helpers.FindSensitiveLeaks(root, config)
+	require.NoError(t, err)
+	messages := make([]string, len(leaks))
+	for i, leak := range leaks {
+		messages[i] = leak.Message
+	}
+	assert.ElementsMatch(t, []string{
+		"variável password deve ser sensitive",
+		"variável password recebe var.db_password (module.app) e deve ser sensitive",
+		"variável tags recebe var.db_password (module.app) e deve ser sensitive",
+		"helm_release.app: var.db_password em values fica em texto plano; use set_sensitive",
+		"helm_release.app: var.db_password em set fica em texto plano; use set_sensitive",
+		"aws_s3_bucket.app: var.db_password chega às tags",
+		"output password expõe var.db_password e deve ser sensitive",
+		"variável api_token deve ser sensitive",
+		"module.app: var.db_password chega às tags",
+		"output app_password expõe var.db_password e deve ser sensitive",
+		"db_password é sensitive e deve ter um placeholder no exemplo, não um valor",
+	}, messages)
+}
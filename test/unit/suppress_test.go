@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Suppression and Baseline Tests
+// ============================================================================
+
+// TestSuppressionsAndBaseline valida supressões inline e do baseline: achados
+// aceitos não reprovam a execução, e supressões expiradas, sem motivo ou sem uso
+// reprovam
+// Valida: Requisitos 16.4
+func TestSuppressionsAndBaseline(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	envDir := filepath.Join(root, "live", "aws", "staging")
+	require.NoError(t, os.MkdirAll(envDir, 0o755))
+	tfvars := filepath.Join(envDir, "terraform.tfvars.example")
+	require.NoError(t, os.WriteFile(tfvars, []byte(strings.Join([]string{
+		`cluster_endpoint_public_access = true`,
+		`# templatecheck:ignore eks-public-endpoint acesso sem VPN no staging`,
+		`cluster_endpoint_public_access_cidrs = ["0.0.0.0/0"]`,
+		`cluster_version = "1.29" # templatecheck:ignore upgrade-plan sem achado para suprimir`,
+		`# templatecheck:ignore variable-validation`,
+		`# templatecheck:ignore addon-scheduling fora do escopo: verificação não executada`,
+	}, "\n")), 0o644))
+
+	baselinePath := filepath.Join(root, "baseline.yaml")
+	require.NoError(t, os.WriteFile(baselinePath, []byte(strings.Join([]string{
+		`accepted:`,
+		`  - check: plan-open-ingress`,
+		`    file: "*staging*.json"`,
+		`    match: aws_eks_cluster.main`,
+		`    reason: staging sem VPN`,
+		`    expires: "2027-03-31"`,
+		`  - check: plan-required-tags`,
+		`    reason: migração de tags`,
+		`    expires: "2026-01-31"`,
+		`  - check: plan-open-ingress`,
+		`    file: "*prod*.json"`,
+		`    reason: plano de outro ambiente, fora do escopo`,
+		`    expires: "2027-03-31"`,
+		`  - check: plan-destructive-change`,
+		`    reason: sem data`,
+	}, "\n")), 0o644))
+
+	plan := filepath.Join(root, "plans", "staging.json")
+	report := helpers.NewReport(root)
+	for _, id := range []string{helpers.CheckEKSPublicEndpoint, helpers.CheckUpgradePlan, helpers.CheckVariableValidation,
+		helpers.CheckPlanOpenIngress, helpers.CheckPlanRequiredTags, helpers.CheckPlanDestructiveChange} {
+		report.AddCheck(&helpers.Check{ID: id})
+	}
+	report.AddInputs(envDir, plan)
+
+	endpoint := &helpers.Finding{CheckID: helpers.CheckEKSPublicEndpoint, Severity: helpers.SeverityError,
+		File: tfvars, Line: 3, Message: "staging: module.eks_cluster.aws_eks_cluster.main tem endpoint público aberto para 0.0.0.0/0"}
+	ingress := &helpers.Finding{CheckID: helpers.CheckPlanOpenIngress, Severity: helpers.SeverityWarning,
+		File: plan, Message: "module.eks_cluster.aws_eks_cluster.main permite acesso de 0.0.0.0/0"}
+	tags := &helpers.Finding{CheckID: helpers.CheckPlanRequiredTags, Severity: helpers.SeverityError,
+		File: plan, Message: "module.velero.aws_s3_bucket.velero sem as tags Project"}
+	for _, finding := range []*helpers.Finding{endpoint, ingress, tags} {
+		report.Add(finding)
+	}
+	require.True(t, report.Failed(helpers.SeverityWarning))
+
+	inline, err := helpers.InlineSuppressions(report.Inputs...)
+	require.NoError(t, err)
+	require.Len(t, inline, 4, "Comentários templatecheck:ignore devem ser encontrados nas entradas")
+	baseline, err := helpers.LoadBaseline(baselinePath)
+	require.NoError(t, err)
+	require.Len(t, baseline, 4)
+	assert.Equal(t, 2, baseline[0].Line, "Entrada do baseline deve ser localizada pela linha")
+
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	report.ApplySuppressions(append(inline, baseline...), today)
+
+	require.NotNil(t, endpoint.Suppression, "Comentário na linha anterior deve suprimir o achado")
+	assert.True(t, endpoint.Suppression.Inline)
+	require.NotNil(t, ingress.Suppression, "Entrada do baseline deve suprimir o achado do plano")
+	assert.Equal(t, "staging sem VPN", ingress.Suppression.Reason)
+	assert.Nil(t, tags.Suppression, "Supressão expirada não deve suprimir o achado")
+
+	problems := make(map[string]string)
+	for _, finding := range report.Findings {
+		if finding.CheckID == helpers.CheckSuppression {
+			assert.Equal(t, helpers.SeverityError, finding.Severity)
+			problems[fmt.Sprintf("%s:%d", filepath.Base(finding.File), finding.Line)] = finding.Message
+		}
+	}
+	assert.Contains(t, problems["terraform.tfvars.example:4"], "não corresponde a nenhum achado", "Supressão sem uso deve reprovar")
+	assert.Contains(t, problems["terraform.tfvars.example:5"], "sem motivo", "Supressão sem motivo deve reprovar")
+	assert.Contains(t, problems["baseline.yaml:7"], "expirou em 2026-01-31", "Supressão expirada deve reprovar")
+	assert.Contains(t, problems["baseline.yaml:14"], "sem data de expiração", "Baseline exige data de expiração")
+	assert.Len(t, problems, 4, "Supressões de verificações não executadas ou de outros arquivos não devem ser reportadas: %v", problems)
+
+	assert.True(t, report.Failed(helpers.SeverityError), "Supressões inválidas devem reprovar a execução")
+
+	// Com apenas achados suprimidos, a execução é aprovada
+	accepted := helpers.NewReport(root)
+	accepted.AddCheck(&helpers.Check{ID: helpers.CheckEKSPublicEndpoint})
+	accepted.Add(&helpers.Finding{CheckID: helpers.CheckEKSPublicEndpoint, Severity: helpers.SeverityError, Suppression: endpoint.Suppression})
+	assert.False(t, accepted.Failed(helpers.SeverityNote), "Achados suprimidos não devem reprovar a execução")
+
+	var sarif bytes.Buffer
+	require.NoError(t, report.WriteSARIF(&sarif, "templatecheck"))
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID       string `json:"ruleId"`
+				Suppressions []struct {
+					Kind string `json:"kind"`
+				} `json:"suppressions"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(sarif.Bytes(), &log))
+	kinds := make(map[string]string)
+	for _, result := range log.Runs[0].Results {
+		if len(result.Suppressions) > 0 {
+			kinds[result.RuleID] = result.Suppressions[0].Kind
+		}
+	}
+	assert.Equal(t, map[string]string{
+		helpers.CheckEKSPublicEndpoint: "inSource",
+		helpers.CheckPlanOpenIngress:   "external",
+	}, kinds, "SARIF deve marcar os achados suprimidos para o code scanning")
+
+	var junit bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&junit))
+	assert.Equal(t, 2, strings.Count(junit.String(), "<skipped "), "Achados suprimidos devem ser casos ignorados no JUnit")
+}
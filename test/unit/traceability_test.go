@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+// Requirement Traceability Tests
+// ============================================================================
+
+// TestRequirementTraceability valida que os testes citam apenas critérios existentes
+// no spec e que os critérios obrigatórios (segurança, backup) mantêm cobertura
+// Valida: Requisitos 16.5
+func TestRequirementTraceability(t *testing.T) {
+	t.Parallel()
+
+	criteria, err := helpers.ParseRequirements(helpers.GetRequirementsPath())
+	require.NoError(t, err)
+	require.NotEmpty(t, criteria, "Spec deve declarar critérios de aceitação")
+
+	refs, err := helpers.TestReferences(helpers.GetTestDirs()...)
+	require.NoError(t, err)
+	require.NotEmpty(t, refs, "Testes devem citar requisitos com 'Valida: Requisitos'")
+
+	config, err := helpers.LoadTraceabilityConfig(helpers.GetTraceabilityConfigPath())
+	require.NoError(t, err)
+
+	matrix := helpers.BuildTraceabilityMatrix(criteria, refs, nil)
+
+	for _, unknown := range matrix.Unknown {
+		assert.Fail(t, "teste cita critério inexistente",
+			"%s:%d %s cita %s, que não existe em requirements.md",
+			unknown.Test.File, unknown.Test.Line, unknown.Test.Name, unknown.Criterion)
+	}
+	for _, criterion := range matrix.MissingRequired(config.Required) {
+		assert.Fail(t, "critério obrigatório sem cobertura",
+			"%s (%s) não é citado por nenhum teste: %s", criterion.ID, criterion.Title, criterion.Text)
+	}
+
+	uncovered := make([]string, 0)
+	for _, criterion := range matrix.Uncovered() {
+		uncovered = append(uncovered, criterion.ID)
+	}
+	t.Logf("Critérios sem testes: %s", strings.Join(uncovered, ", "))
+}
+
+// TestTraceabilityMatrixUsesTestResults valida que, com a saída de go test -json,
+// apenas testes aprovados contam como cobertura
+// Valida: Requisitos 16.5
+func TestTraceabilityMatrixUsesTestResults(t *testing.T) {
+	t.Parallel()
+
+	criteria := []*helpers.Criterion{
+		{ID: "12.1", Requirement: 12, Title: "Backup e Disaster Recovery"},
+		{ID: "12.2", Requirement: 12, Title: "Backup e Disaster Recovery"},
+		{ID: "15.1", Requirement: 15, Title: "Documentação e Exemplos"},
+	}
+	refs := []*helpers.TestReference{
+		{Package: "unit", Name: "TestVeleroBucket", Criteria: helpers.ParseCriteriaList("12.1, 15.1")},
+		{Package: "unit", Name: "TestVeleroIRSA", Criteria: helpers.ParseCriteriaList("12.2 e 19.1")},
+	}
+
+	output := strings.Join([]string{
+		`{"Action":"run","Package":"example.com/test/unit","Test":"TestVeleroBucket"}`,
+		`{"Action":"pass","Package":"example.com/test/unit","Test":"TestVeleroBucket/staging"}`,
+		`{"Action":"pass","Package":"example.com/test/unit","Test":"TestVeleroBucket"}`,
+		`{"Action":"fail","Package":"example.com/test/unit","Test":"TestVeleroIRSA"}`,
+		`{"Action":"fail","Package":"example.com/test/unit"}`,
+	}, "\n")
+	results, err := helpers.ParseGoTestJSON(strings.NewReader(output))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]helpers.TestStatus{
+		"unit.TestVeleroBucket": helpers.TestPassed,
+		"unit.TestVeleroIRSA":   helpers.TestFailed,
+	}, results, "Apenas testes de nível superior devem ser considerados")
+
+	matrix := helpers.BuildTraceabilityMatrix(criteria, refs, results)
+
+	require.Len(t, matrix.Unknown, 1)
+	assert.Equal(t, "19.1", matrix.Unknown[0].Criterion)
+
+	missing := matrix.MissingRequired([]string{"12"})
+	require.Len(t, missing, 1, "Critério coberto apenas por teste reprovado deve ser reportado")
+	assert.Equal(t, "12.2", missing[0].ID)
+	assert.Empty(t, matrix.MissingRequired([]string{"15.1"}))
+
+	static := helpers.BuildTraceabilityMatrix(criteria, refs, nil)
+	assert.Empty(t, static.MissingRequired([]string{"12"}), "Sem resultados, basta o critério ser citado")
+
+	assert.Contains(t, matrix.Markdown(), "| 12.2 | Backup e Disaster Recovery | unit.TestVeleroIRSA (fail) | sem testes aprovados |")
+}
+
+// TestCheckCatalogCitesCriteria valida que cada verificação do catálogo cita um
+// critério existente no spec, exibido no SARIF e em "templatecheck checks"
+// Valida: Requisitos 16.5
+func TestCheckCatalogCitesCriteria(t *testing.T) {
+	t.Parallel()
+
+	criteria, err := helpers.ParseRequirements(helpers.GetRequirementsPath())
+	require.NoError(t, err)
+	known := make(map[string]bool, len(criteria))
+	for _, criterion := range criteria {
+		known[criterion.ID] = true
+	}
+
+	for _, check := range helpers.CheckCatalog() {
+		assert.True(t, known[check.Requirement], "Verificação %s cita o critério inexistente %q", check.ID, check.Requirement)
+	}
+}
@@ -0,0 +1,264 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/terraform-eks-aws-template/test/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ============================================================================
+// Kubernetes Upgrade Planning Tests
+// ============================================================================
+
+// TestUpgradeStepsOneMinorAtATime valida que o upgrade é dividido em um minor por passo
+// Valida: Requisitos 5.5
+func TestUpgradeStepsOneMinorAtATime(t *testing.T) {
+	t.Parallel()
+
+	steps, err := helpers.UpgradeSteps("1.28", "1.30")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.29", "1.30"}, steps)
+
+	steps, err = helpers.UpgradeSteps("1.24", "1.30")
+	require.NoError(t, err)
+	assert.Len(t, steps, 6, "Upgrade de 1.24 para 1.30 deve passar por 6 versões")
+
+	steps, err = helpers.UpgradeSteps("1.28", "1.28")
+	require.NoError(t, err)
+	assert.Empty(t, steps, "Sem mudança de versão não há passos")
+
+	_, err = helpers.UpgradeSteps("1.29", "1.28")
+	assert.Error(t, err, "Downgrade deve ser rejeitado")
+}
+
+// TestAddonCompatibilityMatrixCoversInstalledCharts valida que a versão fixada de cada
+// add-on monitorado consta da matriz e suporta a versão atual do cluster
+// Valida: Requisitos 5.5
+func TestAddonCompatibilityMatrixCoversInstalledCharts(t *testing.T) {
+	t.Parallel()
+
+	for _, env := range []string{"staging", "prod"} {
+		env := env
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			require.NoError(t, err)
+
+			// Um plano sem passos só contém itens se algum add-on já for incompatível
+			current := ev.Var("cluster_version").AsString()
+			plan, err := helpers.PlanUpgrade(ev, current, nil)
+			require.NoError(t, err)
+			assert.Empty(t, plan.Items, "Add-ons de %s devem suportar o Kubernetes %s atual", env, current)
+
+			tracked := make(map[string]bool)
+			for _, entry := range helpers.AddonCompatibilityMatrix() {
+				tracked[entry.Chart] = true
+			}
+			for _, chart := range []string{"aws-load-balancer-controller", "kyverno", "gatekeeper", "velero", "cert-manager"} {
+				assert.True(t, tracked[chart], "Matriz de compatibilidade deve incluir %s", chart)
+			}
+		})
+	}
+}
+
+// TestUpgradePlanPerEnvironment monta o checklist de upgrade de cada ambiente até a
+// maior versão suportada e valida a ordem dos passos
+// Valida: Requisitos 5.5
+func TestUpgradePlanPerEnvironment(t *testing.T) {
+	t.Parallel()
+
+	manifests, err := helpers.RepositoryManifests()
+	require.NoError(t, err)
+
+	for _, env := range []string{"staging", "prod"} {
+		env := env
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			ev, err := helpers.NewEnvironmentEvaluator(env)
+			require.NoError(t, err)
+
+			plan, err := helpers.PlanUpgrade(ev, maxSupportedKubernetesVersion, manifests)
+			require.NoError(t, err)
+
+			for _, line := range plan.Checklist() {
+				t.Logf("%s: %s", env, line)
+			}
+
+			assert.True(t, plan.Feasible(), "Upgrade de %s para %s deve ter ação conhecida para todos os bloqueios",
+				plan.Current, plan.Target)
+
+			// Em cada passo: bloqueios antes do control plane, node groups depois
+			for _, step := range plan.Steps {
+				controlPlane := -1
+				for i, item := range plan.Items {
+					if item.Version != step {
+						continue
+					}
+					switch {
+					case item.Target == "aws_eks_cluster":
+						controlPlane = i
+					case item.Blocking:
+						assert.Equal(t, -1, controlPlane,
+							"%s: item bloqueante %s deve vir antes do control plane %s", env, item.Target, step)
+					case strings.Contains(item.Target, "aws_eks_node_group"):
+						assert.NotEqual(t, -1, controlPlane,
+							"%s: node group %s deve ser atualizado depois do control plane %s", env, item.Target, step)
+					}
+				}
+				assert.NotEqual(t, -1, controlPlane, "%s: plano deve atualizar o control plane para %s", env, step)
+			}
+		})
+	}
+}
+
+// TestUpgradePlanTracksKubeletSkew valida que o skew do kubelet considera as
+// atualizações de node groups dos passos anteriores e que um node group fora do
+// skew é atualizado antes do control plane do passo
+// Valida: Requisitos 5.5
+func TestUpgradePlanTracksKubeletSkew(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+variable "cluster_version" {
+  type = string
+}
+
+resource "aws_eks_node_group" "legacy" {
+  cluster_name    = "eks"
+  node_group_name = "legacy"
+  version         = "1.24"
+}
+
+resource "aws_eks_node_group" "current" {
+  cluster_name    = "eks"
+  node_group_name = "current"
+}
+`), 0o644))
+	mod, err := helpers.LoadModule(dir)
+	require.NoError(t, err)
+	ev, err := helpers.NewEvaluator(mod, map[string]cty.Value{"cluster_version": cty.StringVal("1.28")})
+	require.NoError(t, err)
+
+	plan, err := helpers.PlanUpgrade(ev, "1.32", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.29", "1.30", "1.31", "1.32"}, plan.Steps)
+
+	// Só o node group em 1.24 bloqueia, e apenas no primeiro passo
+	blocking := plan.Blocking()
+	require.Len(t, blocking, 1)
+	assert.Equal(t, "1.29", blocking[0].Version)
+	assert.Equal(t, "aws_eks_node_group.legacy", blocking[0].Target)
+	assert.Equal(t, "kubelet 1.24 excede o skew permitido com o control plane 1.29 (5 minors); atualizar o node group para 1.28 antes", blocking[0].Action)
+
+	var order []string
+	for _, item := range plan.Items {
+		order = append(order, item.Version+" "+item.Target)
+	}
+	assert.Equal(t, []string{
+		"1.29 aws_eks_node_group.legacy",
+		"1.29 aws_eks_cluster",
+		"1.29 aws_eks_node_group.legacy",
+		"1.29 aws_eks_node_group.current",
+		"1.30 aws_eks_cluster",
+		"1.30 aws_eks_node_group.legacy",
+		"1.30 aws_eks_node_group.current",
+		"1.31 aws_eks_cluster",
+		"1.31 aws_eks_node_group.legacy",
+		"1.31 aws_eks_node_group.current",
+		"1.32 aws_eks_cluster",
+		"1.32 aws_eks_node_group.legacy",
+		"1.32 aws_eks_node_group.current",
+	}, order)
+	assert.Equal(t, "atualizar version do node group de 1.28 para 1.29 após o control plane", plan.Items[2].Action)
+	assert.Equal(t, "atualizar version do node group de 1.31 para 1.32 após o control plane", plan.Items[11].Action)
+}
+
+// TestUpgradePlanMigratesCRDsBeforeChart valida que manifestos com APIs de CRDs
+// removidas pela versão sugerida do chart são migrados antes do upgrade do chart
+// Valida: Requisitos 5.5
+func TestUpgradePlanMigratesCRDsBeforeChart(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+variable "cluster_version" {
+  type = string
+}
+
+resource "helm_release" "cert_manager" {
+  name    = "cert-manager"
+  chart   = "cert-manager"
+  version = "1.6.3"
+}
+`), 0o644))
+	mod, err := helpers.LoadModule(dir)
+	require.NoError(t, err)
+	ev, err := helpers.NewEvaluator(mod, map[string]cty.Value{"cluster_version": cty.StringVal("1.23")})
+	require.NoError(t, err)
+
+	issuer := &helpers.KubernetesManifest{APIVersion: "cert-manager.io/v1alpha2", Kind: "ClusterIssuer", Name: "letsencrypt", File: "issuer.yaml", Line: 1}
+	plan, err := helpers.PlanUpgrade(ev, "1.24", []*helpers.KubernetesManifest{issuer})
+	require.NoError(t, err)
+
+	blocking := plan.Blocking()
+	require.Len(t, blocking, 2, "Migração do ClusterIssuer e upgrade do chart")
+	assert.Equal(t, "issuer.yaml:1", blocking[0].Target)
+	assert.Equal(t, "migrar ClusterIssuer cert-manager.io/v1alpha2 para cert-manager.io/v1 antes de atualizar o chart cert-manager", blocking[0].Action)
+	assert.Equal(t, "issuer.yaml", blocking[0].File)
+	assert.Equal(t, "helm_release.cert_manager", blocking[1].Target)
+	assert.Contains(t, blocking[1].Action, "atualizar chart cert-manager de 1.6.3 para 1.13.x")
+	assert.True(t, plan.Feasible())
+}
+
+// TestUpgradePlanNeverDowngradesCharts valida que versões de chart acima da matriz
+// ficam com compatibilidade desconhecida e que a sugestão nunca é uma linha
+// anterior à instalada
+// Valida: Requisitos 5.5
+func TestUpgradePlanNeverDowngradesCharts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+variable "cluster_version" {
+  type = string
+}
+
+resource "helm_release" "alb" {
+  name    = "aws-load-balancer-controller"
+  chart   = "aws-load-balancer-controller"
+  version = "1.9.9"
+}
+
+resource "helm_release" "velero" {
+  name    = "velero"
+  chart   = "velero"
+  version = "5.2.1"
+}
+`), 0o644))
+	mod, err := helpers.LoadModule(dir)
+	require.NoError(t, err)
+	ev, err := helpers.NewEvaluator(mod, map[string]cty.Value{"cluster_version": cty.StringVal("1.28")})
+	require.NoError(t, err)
+
+	plan, err := helpers.PlanUpgrade(ev, "1.29", nil)
+	require.NoError(t, err)
+
+	blocking := plan.Blocking()
+	require.Len(t, blocking, 2)
+	assert.Equal(t, "helm_release.alb", blocking[0].Target)
+	assert.Equal(t, "compatibilidade do chart aws-load-balancer-controller 1.9.9 com Kubernetes 1.29 desconhecida", blocking[0].Action)
+	assert.False(t, blocking[0].Resolvable, "Versão acima da matriz não tem downgrade sugerido")
+	assert.Equal(t, "helm_release.velero", blocking[1].Target)
+	assert.Contains(t, blocking[1].Action, "atualizar chart velero de 5.2.1 para 6.0.x")
+	assert.True(t, blocking[1].Resolvable)
+	assert.False(t, plan.Feasible())
+}